@@ -65,6 +65,10 @@ func RegisterHook(moduleName string) bool {
 				JqFilter: JQFilterCustomCertificate,
 			},
 		},
+		// private keys are redacted in debug output, keys set into values are pkg.Sensitive
+		Sensitive: &pkg.SensitiveConfig{
+			Snapshots: map[string][]string{snapshotKey: {"key"}},
+		},
 	}, CopyCustomCertificatesHandler(moduleName))
 }
 
@@ -115,7 +119,7 @@ func CopyCustomCertificatesHandler(moduleName string) func(ctx context.Context,
 
 		input.Values.Set(valuesPath, certValues{
 			CA:      string(cert.CA),
-			TLSKey:  string(cert.Key),
+			TLSKey:  pkg.Sensitive(cert.Key),
 			TLSCert: string(cert.Cert),
 		})
 
@@ -124,7 +128,7 @@ func CopyCustomCertificatesHandler(moduleName string) func(ctx context.Context,
 }
 
 type certValues struct {
-	CA      string `json:"ca.crt,omitempty"`
-	TLSKey  string `json:"tls.key,omitempty"`
-	TLSCert string `json:"tls.crt,omitempty"`
+	CA      string        `json:"ca.crt,omitempty"`
+	TLSKey  pkg.Sensitive `json:"tls.key,omitempty"`
+	TLSCert string        `json:"tls.crt,omitempty"`
 }
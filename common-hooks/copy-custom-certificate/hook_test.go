@@ -209,6 +209,10 @@ type: kubernetes.io/tls
 	assert.Equal(t, "CACACACA", hec.ValuesGet("testmodule.internal.customCertificateData.ca\\.crt").String())
 	assert.Equal(t, "KEYKEYKEY", hec.ValuesGet("testmodule.internal.customCertificateData.tls\\.key").String())
 	assert.Equal(t, "CRTCRTCRT", hec.ValuesGet("testmodule.internal.customCertificateData.tls\\.crt").String())
+
+	// the private key is redacted in dumps
+	assert.Contains(t, hec.Dump(), "CRTCRTCRT")
+	assert.NotContains(t, hec.Dump(), "KEYKEYKEY")
 }
 
 func TestHandler_NonCustomCertificateMode_RemovesValues(t *testing.T) {
@@ -179,6 +179,16 @@ func RegisterInternalTLSHookEM(conf GenSelfSignedTLSHookConf) bool {
 }
 
 func GenSelfSignedTLSConfig(conf GenSelfSignedTLSHookConf) *pkg.HookConfig {
+	// private keys are redacted in debug output
+	sensitive := &pkg.SensitiveConfig{
+		Values:    []string{conf.Path() + ".key"},
+		Snapshots: map[string][]string{InternalTLSSnapshotKey: {"key"}},
+	}
+
+	if conf.CommonCAValuesPath != "" {
+		sensitive.Values = append(sensitive.Values, conf.CommonCAPath()+"."+conf.CAKeyField())
+	}
+
 	return &pkg.HookConfig{
 		OnBeforeHelm: &pkg.OrderedConfig{Order: 5},
 		Kubernetes: []pkg.KubernetesConfig{
@@ -203,6 +213,7 @@ func GenSelfSignedTLSConfig(conf GenSelfSignedTLSHookConf) *pkg.HookConfig {
 				Crontab: "42 4 * * *",
 			},
 		},
+		Sensitive: sensitive,
 	}
 }
 
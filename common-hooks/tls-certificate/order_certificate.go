@@ -118,6 +118,10 @@ func CertificateHandlerConfig(namespaces, secretNames []string) *pkg.HookConfig
 				Crontab: "42 4 * * *",
 			},
 		},
+		// private keys are redacted in debug output, keys set into values are pkg.Sensitive
+		Sensitive: &pkg.SensitiveConfig{
+			Snapshots: map[string][]string{OrderSertificateSnapshotKey: {"key"}},
+		},
 	}
 }
 
@@ -173,7 +177,7 @@ func certificateHandlerWithRequests(ctx context.Context, input *pkg.HookInput, r
 				if !genNew {
 					info := CertificateInfo{Certificate: string(secret.Cert), Key: string(secret.Key)}
 
-					input.Values.Set(valueName, info)
+					input.Values.Set(valueName, info.values())
 
 					continue
 				}
@@ -185,7 +189,7 @@ func certificateHandlerWithRequests(ctx context.Context, input *pkg.HookInput, r
 			return fmt.Errorf("issue certificate: %w", err)
 		}
 
-		input.Values.Set(valueName, info)
+		input.Values.Set(valueName, info.values())
 	}
 	return nil
 }
@@ -242,6 +246,21 @@ type CertificateInfo struct {
 	CertificateUpdated bool   `json:"certificate_updated,omitempty"`
 }
 
+// certificateInfoValues is CertificateInfo as it is set into values, the private key is redacted in debug output.
+type certificateInfoValues struct {
+	Certificate        string        `json:"certificate,omitempty"`
+	Key                pkg.Sensitive `json:"key,omitempty"`
+	CertificateUpdated bool          `json:"certificate_updated,omitempty"`
+}
+
+func (i *CertificateInfo) values() certificateInfoValues {
+	return certificateInfoValues{
+		Certificate:        i.Certificate,
+		Key:                pkg.Sensitive(i.Key),
+		CertificateUpdated: i.CertificateUpdated,
+	}
+}
+
 func IssueCertificate(ctx context.Context, input *pkg.HookInput, request OrderCertificateRequest) (*CertificateInfo, error) {
 	k8, err := input.DC.GetK8sClient()
 	if err != nil {
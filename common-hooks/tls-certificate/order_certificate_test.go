@@ -89,5 +89,9 @@ func TestJQFilterApplyCertificateSecret(t *testing.T) {
 }
 
 func TestCertificateHandlerConfig_IsValid(t *testing.T) {
-	require.NoError(t, tlscertificate.CertificateHandlerConfig([]string{}, []string{}).Validate())
+	cfg := tlscertificate.CertificateHandlerConfig([]string{}, []string{})
+	require.NoError(t, cfg.Validate())
+
+	require.NotNil(t, cfg.Sensitive)
+	assert.Equal(t, map[string][]string{tlscertificate.OrderSertificateSnapshotKey: {"key"}}, cfg.Sensitive.Snapshots)
 }
@@ -0,0 +1,100 @@
+package admission
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	admissionv1 "k8s.io/api/admission/v1"
+
+	"github.com/deckhouse/module-sdk/pkg"
+	"github.com/deckhouse/module-sdk/pkg/utils/patch"
+)
+
+var _ pkg.MutatingReview = (*MutatingReview)(nil)
+var _ pkg.Outputer = (*MutatingReview)(nil)
+
+type MutatingReview struct {
+	request  *admissionv1.AdmissionRequest
+	response pkg.MutatingResponse
+}
+
+// NewMutatingReview creates a review which allows the request without changes until the hook mutates or denies it.
+func NewMutatingReview(request *admissionv1.AdmissionRequest) *MutatingReview {
+	return &MutatingReview{
+		request: request,
+		response: pkg.MutatingResponse{
+			Allowed: true,
+		},
+	}
+}
+
+func (r *MutatingReview) Request() *admissionv1.AdmissionRequest {
+	return r.request
+}
+
+func (r *MutatingReview) Patch(p patch.Patch, warnings ...string) error {
+	response := pkg.MutatingResponse{
+		Allowed:  true,
+		Warnings: warnings,
+	}
+
+	if len(p) > 0 {
+		raw, err := json.Marshal(p)
+		if err != nil {
+			return fmt.Errorf("marshal patch: %w", err)
+		}
+
+		response.Patch = raw
+	}
+
+	r.response = response
+
+	return nil
+}
+
+func (r *MutatingReview) Mutate(obj any, warnings ...string) error {
+	if len(r.request.Object.Raw) == 0 {
+		return fmt.Errorf("%s request: %w", r.request.Operation, pkg.ErrNoAdmissionObject)
+	}
+
+	modified, err := json.Marshal(obj)
+	if err != nil {
+		return fmt.Errorf("marshal mutated object: %w", err)
+	}
+
+	p, err := patch.Diff(r.request.Object.Raw, modified)
+	if err != nil {
+		return fmt.Errorf("diff mutated object: %w", err)
+	}
+
+	return r.Patch(p, warnings...)
+}
+
+func (r *MutatingReview) Allow(warnings ...string) {
+	r.response = pkg.MutatingResponse{
+		Allowed:  true,
+		Warnings: warnings,
+	}
+}
+
+func (r *MutatingReview) Deny(message string, warnings ...string) {
+	r.response = pkg.MutatingResponse{
+		Allowed:  false,
+		Message:  message,
+		Warnings: warnings,
+	}
+}
+
+func (r *MutatingReview) Response() pkg.MutatingResponse {
+	return r.response
+}
+
+func (r *MutatingReview) WriteOutput(w io.Writer) error {
+	err := json.NewEncoder(w).Encode(r.response)
+	if err != nil {
+		return fmt.Errorf("json marshall: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package admission_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/deckhouse/module-sdk/internal/admission"
+	"github.com/deckhouse/module-sdk/pkg"
+)
+
+func TestMutatingReview_Mutate(t *testing.T) {
+	t.Run("create", func(t *testing.T) {
+		review := admission.NewMutatingReview(&admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: []byte(`{"metadata":{"name":"pod-1"}}`)},
+		})
+
+		err := review.Mutate(map[string]any{"metadata": map[string]any{"name": "pod-1", "labels": map[string]any{"app": "web"}}})
+		require.NoError(t, err)
+
+		assert.True(t, review.Response().Allowed)
+		assert.JSONEq(t, `[{"op":"add","path":"/metadata/labels","value":{"app":"web"}}]`, string(review.Response().Patch))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		review := admission.NewMutatingReview(&admissionv1.AdmissionRequest{
+			Operation: admissionv1.Delete,
+			OldObject: runtime.RawExtension{Raw: []byte(`{"metadata":{"name":"pod-1"}}`)},
+		})
+
+		err := review.Mutate(map[string]any{"metadata": map[string]any{"name": "pod-1"}})
+		require.ErrorIs(t, err, pkg.ErrNoAdmissionObject)
+		assert.EqualError(t, err, "DELETE request: admission request has no object to mutate")
+
+		// the request is still allowed without changes
+		assert.True(t, review.Response().Allowed)
+		assert.Empty(t, review.Response().Patch)
+	})
+}
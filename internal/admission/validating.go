@@ -0,0 +1,61 @@
+package admission
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	admissionv1 "k8s.io/api/admission/v1"
+
+	"github.com/deckhouse/module-sdk/pkg"
+)
+
+var _ pkg.ValidatingReview = (*ValidatingReview)(nil)
+var _ pkg.Outputer = (*ValidatingReview)(nil)
+
+type ValidatingReview struct {
+	request  *admissionv1.AdmissionRequest
+	response pkg.ValidatingResponse
+}
+
+// NewValidatingReview creates a review which allows the request until the hook denies it.
+func NewValidatingReview(request *admissionv1.AdmissionRequest) *ValidatingReview {
+	return &ValidatingReview{
+		request: request,
+		response: pkg.ValidatingResponse{
+			Allowed: true,
+		},
+	}
+}
+
+func (r *ValidatingReview) Request() *admissionv1.AdmissionRequest {
+	return r.request
+}
+
+func (r *ValidatingReview) Allow(warnings ...string) {
+	r.response = pkg.ValidatingResponse{
+		Allowed:  true,
+		Warnings: warnings,
+	}
+}
+
+func (r *ValidatingReview) Deny(message string, warnings ...string) {
+	r.response = pkg.ValidatingResponse{
+		Allowed:  false,
+		Message:  message,
+		Warnings: warnings,
+	}
+}
+
+func (r *ValidatingReview) Response() pkg.ValidatingResponse {
+	return r.response
+}
+
+func (r *ValidatingReview) WriteOutput(w io.Writer) error {
+	err := json.NewEncoder(w).Encode(r.response)
+	if err != nil {
+		return fmt.Errorf("json marshall: %w", err)
+	}
+
+	return nil
+}
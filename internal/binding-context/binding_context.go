@@ -30,6 +30,9 @@ const (
 	TypeEvent           KubeEventType = "Event"
 	TypeSchedule        KubeEventType = "Schedule"
 	TypeGroup           KubeEventType = "Group"
+	TypeValidating      KubeEventType = "Validating"
+	TypeMutating        KubeEventType = "Mutating"
+	TypeConversion      KubeEventType = "Conversion"
 )
 
 // from shell operator
@@ -43,12 +46,20 @@ type BindingContext struct {
 	Snapshots map[string]ObjectAndFilterResults `json:"snapshots,omitempty"`
 
 	// For “Event”-type binding context on Kubernetes event
-	// WatchEvent WatchEventType `json:"watchEvent,omitempty"`
-	// Object any `json:"object,omitempty"`
-	// FilterResult any `json:"filterResult,omitempty"`
+	WatchEvent   WatchEventType  `json:"watchEvent,omitempty"`
+	Object       json.RawMessage `json:"object,omitempty"`
+	FilterResult json.RawMessage `json:"filterResult,omitempty"`
 
 	// For “Synchronization”-type binding context
-	// Objects []map[string]any `json:"objects,omitempty"`
+	Objects ObjectAndFilterResults `json:"objects,omitempty"`
+
+	// For “Conversion”-type binding context
+	FromVersion string `json:"fromVersion,omitempty"`
+	ToVersion   string `json:"toVersion,omitempty"`
+
+	// For “Validating”, “Mutating” and “Conversion”-type binding contexts:
+	// AdmissionReview or ConversionReview accordingly
+	Review json.RawMessage `json:"review,omitempty"`
 }
 
 type Metadata struct {
@@ -70,3 +81,43 @@ type ObjectAndFilterResult struct {
 func (bc BindingContext) IsSynchronization() bool {
 	return bc.Binding == "kubernetes" && bc.Type == TypeSynchronization
 }
+
+// DropEmptyObjects replaces empty objects and filter results, which shell-operator
+// can pass for some reason, with nil.
+func DropEmptyObjects(contexts []BindingContext) {
+	for cidx, context := range contexts {
+		for sidx, snap := range context.Snapshots {
+			for ridx, res := range snap {
+				if isEmptyRawObject(res.Object) {
+					contexts[cidx].Snapshots[sidx][ridx].Object = nil
+				}
+
+				if isEmptyRawObject(res.FilterResult) {
+					contexts[cidx].Snapshots[sidx][ridx].FilterResult = nil
+				}
+			}
+		}
+
+		if isEmptyRawObject(context.Object) {
+			contexts[cidx].Object = nil
+		}
+
+		if isEmptyRawObject(context.FilterResult) {
+			contexts[cidx].FilterResult = nil
+		}
+
+		for oidx, res := range context.Objects {
+			if isEmptyRawObject(res.Object) {
+				contexts[cidx].Objects[oidx].Object = nil
+			}
+
+			if isEmptyRawObject(res.FilterResult) {
+				contexts[cidx].Objects[oidx].FilterResult = nil
+			}
+		}
+	}
+}
+
+func isEmptyRawObject(raw json.RawMessage) bool {
+	return string(raw) == `{}` || string(raw) == `"{}"`
+}
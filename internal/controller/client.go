@@ -0,0 +1,175 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/deckhouse/module-sdk/internal/executor"
+	"github.com/deckhouse/module-sdk/internal/transport/file"
+	"github.com/deckhouse/module-sdk/internal/transport/stream"
+	gohook "github.com/deckhouse/module-sdk/pkg/hook"
+)
+
+// runRemote forwards the hook run to the hook server and writes its output with the configured transport.
+// The hook is run locally if the server does not accept connections, e.g. the socket is stale.
+func (c *HookController) runRemote(ctx context.Context, hook executor.Executor) error {
+	hookName := hook.Config().GetMetadata().Name
+
+	var (
+		input         []byte
+		fileTransport *file.Transport
+		err           error
+	)
+
+	if c.transport == TransportStream {
+		input, err = io.ReadAll(c.stdin)
+		if err != nil {
+			return fmt.Errorf("read input: %w", err)
+		}
+
+		// the input is read again if the hook is run locally
+		c.stdin = bytes.NewReader(input)
+	} else {
+		fileTransport = file.NewTransport(c.fConfig, hookName, c.dc, c.logger.Named("file-transport"))
+
+		input, err = encodeStreamInput(fileTransport.NewRequest())
+		if err != nil {
+			return exitWithError(hookName, fmt.Errorf("read input: %w", err))
+		}
+	}
+
+	output, err := c.postRun(ctx, c.serverPath(hook), input)
+	if isDialError(err) {
+		c.logger.Warn("hook server is not available, run hook locally",
+			slog.String("socket", c.serverSocket), slog.String("error", err.Error()))
+
+		return c.runLocal(ctx, hook)
+	}
+
+	if err != nil {
+		output = &gohook.StreamOutput{Error: newOutputError(hookName, fmt.Errorf("hook server: %w", err))}
+	}
+
+	if c.transport == TransportStream {
+		if err := json.NewEncoder(c.stdout).Encode(output); err != nil {
+			return fmt.Errorf("send: %w", err)
+		}
+
+		if output.Error != nil {
+			os.Exit(1)
+		}
+
+		return nil
+	}
+
+	// the result of a failed run contains execution metrics only
+	sendErr := fileTransport.NewResponse().Send(stream.NewResult(output))
+
+	if output.Error != nil {
+		if sendErr != nil {
+			c.logger.Error("send output of failed run", slog.String("error", sendErr.Error()))
+		}
+
+		return exitWithOutputError(output.Error)
+	}
+
+	if sendErr != nil {
+		return fmt.Errorf("send: %w", sendErr)
+	}
+
+	return nil
+}
+
+// serverPath returns the path of the hook server API running the hook.
+func (c *HookController) serverPath(hook executor.Executor) string {
+	if hook == c.registry.Readiness() {
+		return serverReadinessPath
+	}
+
+	return serverRunPath + hook.Config().GetMetadata().Name
+}
+
+// postRun sends the hook input to the hook server and returns the output of the run.
+func (c *HookController) postRun(ctx context.Context, path string, input []byte) (*gohook.StreamOutput, error) {
+	// the host is ignored, requests are sent to the socket
+	endpoint := "http://hook-server" + (&url.URL{Path: path}).EscapedPath()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(input))
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := newServerClient(c.serverSocket).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("unexpected status %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+
+	output := new(gohook.StreamOutput)
+	if err := json.NewDecoder(resp.Body).Decode(output); err != nil {
+		return nil, fmt.Errorf("decode output: %w", err)
+	}
+
+	return output, nil
+}
+
+func newServerClient(socket string) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+}
+
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// encodeStreamInput encodes the input of the request as the stream transport input.
+func encodeStreamInput(req executor.Request) ([]byte, error) {
+	values, err := req.GetValues()
+	if err != nil {
+		return nil, fmt.Errorf("get values: %w", err)
+	}
+
+	configValues, err := req.GetConfigValues()
+	if err != nil {
+		return nil, fmt.Errorf("get config values: %w", err)
+	}
+
+	contexts, err := req.GetBindingContexts()
+	if err != nil {
+		return nil, fmt.Errorf("get binding contexts: %w", err)
+	}
+
+	rawContexts, err := json.Marshal(contexts)
+	if err != nil {
+		return nil, fmt.Errorf("marshal binding contexts: %w", err)
+	}
+
+	return json.Marshal(&gohook.StreamInput{
+		BindingContexts: rawContexts,
+		Values:          values,
+		ConfigValues:    configValues,
+	})
+}
@@ -2,9 +2,11 @@ package controller
 
 import (
 	"context"
+	"time"
 
 	"github.com/deckhouse/deckhouse/pkg/log"
 
+	"github.com/deckhouse/module-sdk/internal/executor"
 	"github.com/deckhouse/module-sdk/internal/transport/file"
 	"github.com/deckhouse/module-sdk/pkg"
 	"github.com/deckhouse/module-sdk/pkg/settingscheck"
@@ -23,6 +25,13 @@ type HookConfig struct {
 	ValuesJSONPath       string
 	ConfigValuesJSONPath string
 
+	ValidatingResponsePath string
+	AdmissionResponsePath  string
+	ConversionResponsePath string
+
+	// OutputManifestPath is the path of the manifest of written outputs, empty disables it
+	OutputManifestPath string
+
 	CreateFilesByYourself bool
 }
 
@@ -33,10 +42,28 @@ type ReadinessConfig struct {
 }
 
 type Config struct {
-	ModuleName      string
+	ModuleName string
+	// ValuesRootKey restricts values patches of module hooks to the module values key, e.g. "myModule",
+	// and pkg.HookConfig.AllowedValuesRoots. Empty disables the check.
+	ValuesRootKey   string
 	HookConfig      *HookConfig
 	ReadinessConfig *ReadinessConfig
 	SettingsCheck   settingscheck.Check
+	// Middlewares wrap every hook, see pkg.Middleware.
+	Middlewares []pkg.Middleware
+	// DisableExecutionMetrics disables metrics emitted by the SDK for every hook run.
+	DisableExecutionMetrics bool
+	// DefaultTimeout is the timeout of hooks without their own timeout, zero disables it.
+	DefaultTimeout time.Duration
+	// Transport is TransportFile (default) or TransportStream.
+	Transport string
+	// ServerSocket is the Unix socket of the hook server, see HookController.Serve.
+	ServerSocket string
+	// RecordDir is the directory hook runs are recorded into, each run into its own tarball,
+	// see record.WriteRun and HookController.Replay.
+	RecordDir string
+	// ValuesValidator validates values patched by module hooks, nil disables validation.
+	ValuesValidator executor.ValuesValidator
 
 	LogLevelRaw string
 	LogLevel    log.Level
@@ -55,6 +82,12 @@ func (cfg *Config) GetFileConfig() *file.Config {
 		ValuesJSONPath:       cfg.HookConfig.ValuesJSONPath,
 		ConfigValuesJSONPath: cfg.HookConfig.ConfigValuesJSONPath,
 
+		ValidatingResponsePath: cfg.HookConfig.ValidatingResponsePath,
+		AdmissionResponsePath:  cfg.HookConfig.AdmissionResponsePath,
+		ConversionResponsePath: cfg.HookConfig.ConversionResponsePath,
+
+		OutputManifestPath: cfg.HookConfig.OutputManifestPath,
+
 		CreateFilesByYourself: cfg.HookConfig.CreateFilesByYourself,
 	}
 }
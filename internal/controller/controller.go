@@ -6,14 +6,18 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 
 	"github.com/deckhouse/deckhouse/pkg/log"
 
 	"github.com/deckhouse/module-sdk/internal/common-hooks/readiness"
+	"github.com/deckhouse/module-sdk/internal/executor"
 	execregistry "github.com/deckhouse/module-sdk/internal/executor/registry"
 	"github.com/deckhouse/module-sdk/internal/transport/file"
+	"github.com/deckhouse/module-sdk/internal/transport/stream"
 	"github.com/deckhouse/module-sdk/pkg"
 	"github.com/deckhouse/module-sdk/pkg/dependency"
 	gohook "github.com/deckhouse/module-sdk/pkg/hook"
@@ -22,10 +26,28 @@ import (
 	"github.com/deckhouse/module-sdk/pkg/utils/ptr"
 )
 
+// Transports pass the hook input and output between the hook binary and its caller.
+const (
+	// TransportFile reads the input from files and writes the output to files, as addon-operator expects.
+	TransportFile = "file"
+	// TransportStream reads the input from stdin and writes the output to stdout, see gohook.StreamInput.
+	TransportStream = "stream"
+)
+
 type HookController struct {
 	registry *execregistry.Registry
 	fConfig  *file.Config
 
+	transport string
+	stdin     io.Reader
+	stdout    io.Writer
+	// serverSocket is the socket of the hook server, hook runs are forwarded to it if it exists
+	serverSocket string
+	// recordPath is the directory or the tarball the run is recorded into, it takes precedence over recordDir
+	recordPath string
+	// recordDir is the directory runs are recorded into, each run into its own tarball
+	recordDir string
+
 	settingsCheck settingscheck.Check
 
 	dc     pkg.DependencyContainer
@@ -40,7 +62,20 @@ type HookSender interface {
 }
 
 func NewHookController(cfg *Config, logger *log.Logger) *HookController {
-	reg := execregistry.NewRegistry(logger)
+	execOpts := []executor.Option{executor.WithDefaultTimeout(cfg.DefaultTimeout)}
+	if cfg.DisableExecutionMetrics {
+		execOpts = append(execOpts, executor.WithoutExecutionMetrics())
+	}
+	if cfg.ValuesRootKey != "" {
+		execOpts = append(execOpts, executor.WithValuesRootKey(cfg.ValuesRootKey))
+	}
+	if cfg.ValuesValidator != nil {
+		execOpts = append(execOpts, executor.WithValuesValidator(cfg.ValuesValidator))
+	}
+
+	reg := execregistry.NewRegistry(logger, cfg.Middlewares...)
+	reg.SetExecutorOptions(execOpts...)
+
 	reg.RegisterModuleHooks(hookregistry.Registry().ModuleHooks()...)
 	reg.RegisterAppHooks(hookregistry.Registry().ApplicationHooks()...)
 
@@ -53,10 +88,36 @@ func NewHookController(cfg *Config, logger *log.Logger) *HookController {
 		settingsCheck: cfg.SettingsCheck,
 		dc:            dependency.NewDependencyContainer(),
 		fConfig:       cfg.GetFileConfig(),
+		transport:     cfg.Transport,
+		serverSocket:  cfg.ServerSocket,
+		recordDir:     cfg.RecordDir,
+		stdin:         os.Stdin,
+		stdout:        os.Stdout,
 		logger:        logger,
 	}
 }
 
+// SetTransport overrides the transport from the config, e.g. by a command line flag.
+func (c *HookController) SetTransport(transport string) error {
+	if err := ValidateTransport(transport); err != nil {
+		return err
+	}
+
+	c.transport = transport
+
+	return nil
+}
+
+// ValidateTransport checks that the transport is known.
+func ValidateTransport(transport string) error {
+	switch transport {
+	case "", TransportFile, TransportStream:
+		return nil
+	}
+
+	return fmt.Errorf("unknown transport %q, expected %q or %q", transport, TransportFile, TransportStream)
+}
+
 func addReadinessHook(reg *execregistry.Registry, cfg *ReadinessConfig) {
 	readinessConfig := &readiness.ReadinessHookConfig{
 		ModuleName:        cfg.ModuleName,
@@ -71,43 +132,61 @@ func addReadinessHook(reg *execregistry.Registry, cfg *ReadinessConfig) {
 	reg.SetReadinessHook(pkg.Hook[pkg.HookConfig, *pkg.HookInput]{Config: *config, HookFunc: f})
 }
 
-func (c *HookController) ListHooksMeta() []pkg.HookMetadata {
+// TODO: fix typo, didn't fix now to not break public API
+var ErrHookIndexIsNotExists = errors.New("hook index does not exist")
+
+func (c *HookController) RunHook(ctx context.Context, idx int) error {
 	hooks := c.registry.Executors()
 
-	hooksmetas := make([]pkg.HookMetadata, 0, len(hooks))
-	for _, hook := range hooks {
-		hooksmetas = append(hooksmetas, hook.Config().GetMetadata())
+	if idx < 0 || len(hooks) <= idx {
+		return ErrHookIndexIsNotExists
 	}
 
-	return hooksmetas
+	c.checkOrderFingerprint(idx)
+
+	return c.run(ctx, hooks[idx])
 }
 
-// TODO: fix typo, didn't fix now to not break public API
-var ErrHookIndexIsNotExists = errors.New("hook index does not exist")
+var ErrReadinessHookDoesNotExists = errors.New("readiness hook does not exists")
 
-func (c *HookController) RunHook(ctx context.Context, idx int) error {
-	hooks := c.registry.Executors()
+func (c *HookController) RunReadiness(ctx context.Context) error {
+	hook := c.registry.Readiness()
 
-	if len(hooks) <= idx {
-		return ErrHookIndexIsNotExists
+	if hook == nil {
+		return ErrReadinessHookDoesNotExists
+	}
+
+	return c.run(ctx, hook)
+}
+
+func (c *HookController) run(ctx context.Context, hook executor.Executor) error {
+	// recorded runs are not forwarded, as interactions of the dependency container are recorded locally
+	if c.serverSocket != "" && !c.recording() {
+		if _, err := os.Stat(c.serverSocket); err == nil {
+			return c.runRemote(ctx, hook)
+		}
 	}
 
-	hook := hooks[idx]
+	return c.runLocal(ctx, hook)
+}
+
+func (c *HookController) runLocal(ctx context.Context, hook executor.Executor) error {
+	if c.transport == TransportStream {
+		return c.runStream(ctx, hook)
+	}
 
 	transport := file.NewTransport(c.fConfig, hook.Config().GetMetadata().Name, c.dc, c.logger.Named("file-transport"))
 
-	hookRes, err := hook.Execute(ctx, transport.NewRequest())
+	hookRes, err := c.execute(ctx, hook, transport.NewRequest())
 	if err != nil {
-		outputError := &gohook.Error{Message: "execute: " + err.Error()}
-
-		buf := bytes.NewBuffer([]byte{})
-		err := json.NewEncoder(buf).Encode(outputError)
-		if err != nil {
-			return fmt.Errorf("encode error: %w", err)
+		// the result of a failed run contains execution metrics only
+		if hookRes != nil {
+			if sendErr := transport.NewResponse().Send(hookRes); sendErr != nil {
+				c.logger.Error("send output of failed run", slog.String("error", sendErr.Error()))
+			}
 		}
 
-		fmt.Fprintln(os.Stderr, buf.String())
-		os.Exit(1)
+		return exitWithError(hook.Config().GetMetadata().Name, err)
 	}
 
 	err = transport.NewResponse().Send(hookRes)
@@ -118,37 +197,50 @@ func (c *HookController) RunHook(ctx context.Context, idx int) error {
 	return nil
 }
 
-var ErrReadinessHookDoesNotExists = errors.New("readiness hook does not exists")
-
-func (c *HookController) RunReadiness(ctx context.Context) error {
-	hook := c.registry.Readiness()
+// runStream runs the hook with the input read from stdin and writes the output envelope,
+// including the error of a failed run, to stdout.
+func (c *HookController) runStream(ctx context.Context, hook executor.Executor) error {
+	failed, err := c.executeStream(ctx, hook, stream.NewTransport(c.stdin, c.stdout, c.dc, c.logger.Named("stream-transport")))
+	if err != nil {
+		return err
+	}
 
-	if hook == nil {
-		return ErrReadinessHookDoesNotExists
+	if failed {
+		os.Exit(1)
 	}
 
-	transport := file.NewTransport(c.fConfig, hook.Config().GetMetadata().Name, c.dc, c.logger.Named("file-transport"))
+	return nil
+}
 
-	hookRes, err := hook.Execute(ctx, transport.NewRequest())
-	if err != nil {
-		outputError := &gohook.Error{Message: "execute: " + err.Error()}
+// executeStream runs the hook with the input read by the stream transport and writes the output envelope,
+// including the error of a failed run. It reports whether the run failed.
+func (c *HookController) executeStream(ctx context.Context, hook executor.Executor, transport *stream.Transport) (bool, error) {
+	hookName := hook.Config().GetMetadata().Name
 
-		buf := bytes.NewBuffer([]byte{})
-		err := json.NewEncoder(buf).Encode(outputError)
-		if err != nil {
-			return fmt.Errorf("encode error: %w", err)
+	var hookRes executor.Result
+
+	req, err := transport.NewRequest()
+	if err == nil {
+		hookRes, err = c.execute(ctx, hook, req)
+	} else {
+		err = pkg.NewPermanentError(fmt.Errorf("read input: %w", err))
+	}
+
+	if err != nil {
+		// the result of a failed run contains execution metrics only
+		if sendErr := transport.NewResponse().Send(hookRes, newOutputError(hookName, err)); sendErr != nil {
+			return true, fmt.Errorf("send: %w", sendErr)
 		}
 
-		fmt.Fprintln(os.Stderr, buf.String())
-		os.Exit(1)
+		return true, nil
 	}
 
-	err = transport.NewResponse().Send(hookRes)
+	err = transport.NewResponse().Send(hookRes, nil)
 	if err != nil {
-		return fmt.Errorf("send: %w", err)
+		return false, fmt.Errorf("send: %w", err)
 	}
 
-	return nil
+	return false, nil
 }
 
 func (c *HookController) CheckSettings(ctx context.Context) error {
@@ -194,6 +286,10 @@ func (c *HookController) PrintHookConfigs() error {
 		cfg.Readiness = readinessConfig
 	}
 
+	if len(configs) > 0 {
+		cfg.OrderFingerprint = c.orderFingerprint()
+	}
+
 	if c.settingsCheck != nil {
 		cfg.HasSettingsCheck = true
 	}
@@ -250,6 +346,10 @@ func (c *HookController) WriteHookConfigsInFile() error {
 		cfg.Readiness = readinessConfig
 	}
 
+	if len(configs) > 0 {
+		cfg.OrderFingerprint = c.orderFingerprint()
+	}
+
 	err = json.NewEncoder(f).Encode(cfg)
 	if err != nil {
 		return fmt.Errorf("json marshall: %w", err)
@@ -274,11 +374,7 @@ func remapHookConfigToGohook(cfg pkg.HookConfigInterface) *gohook.HookConfig {
 
 func remapModuleHookConfig(cfg *pkg.HookConfig, out *gohook.HookConfig) {
 	for _, scfg := range cfg.Schedule {
-		out.Schedule = append(out.Schedule, gohook.ScheduleConfig{
-			Name:    scfg.Name,
-			Crontab: scfg.Crontab,
-			Queue:   cfg.Queue,
-		})
+		out.Schedule = append(out.Schedule, convertScheduleConfig(scfg, cfg.Queue))
 	}
 
 	for i := range cfg.Kubernetes {
@@ -286,6 +382,18 @@ func remapModuleHookConfig(cfg *pkg.HookConfig, out *gohook.HookConfig) {
 		out.Kubernetes = append(out.Kubernetes, convertKubernetesConfig(k, cfg.Queue))
 	}
 
+	for i := range cfg.KubernetesValidating {
+		out.KubernetesValidating = append(out.KubernetesValidating, convertValidatingConfig(&cfg.KubernetesValidating[i]))
+	}
+
+	for i := range cfg.KubernetesMutating {
+		out.KubernetesMutating = append(out.KubernetesMutating, convertMutatingConfig(&cfg.KubernetesMutating[i]))
+	}
+
+	for i := range cfg.KubernetesConversion {
+		out.KubernetesConversion = append(out.KubernetesConversion, convertConversionConfig(&cfg.KubernetesConversion[i]))
+	}
+
 	if cfg.OnStartup != nil {
 		out.OnStartup = ptr.To(cfg.OnStartup.Order)
 	}
@@ -301,15 +409,18 @@ func remapModuleHookConfig(cfg *pkg.HookConfig, out *gohook.HookConfig) {
 	if cfg.OnAfterDeleteHelm != nil {
 		out.OnAfterDeleteHelm = ptr.To(cfg.OnAfterDeleteHelm.Order)
 	}
+
+	if cfg.AllowFailure {
+		out.AllowFailure = ptr.To(true)
+	}
+
+	out.Settings = convertHookConfigSettings(cfg.Settings)
+	out.LogLevelRaw = cfg.LogLevel
 }
 
 func remapApplicationHookConfig(cfg *pkg.ApplicationHookConfig, out *gohook.HookConfig) {
 	for _, scfg := range cfg.Schedule {
-		out.Schedule = append(out.Schedule, gohook.ScheduleConfig{
-			Name:    scfg.Name,
-			Crontab: scfg.Crontab,
-			Queue:   cfg.Queue,
-		})
+		out.Schedule = append(out.Schedule, convertScheduleConfig(scfg, cfg.Queue))
 	}
 
 	for i := range cfg.Kubernetes {
@@ -332,6 +443,13 @@ func remapApplicationHookConfig(cfg *pkg.ApplicationHookConfig, out *gohook.Hook
 	if cfg.OnAfterDeleteHelm != nil {
 		out.OnAfterDeleteHelm = ptr.To(cfg.OnAfterDeleteHelm.Order)
 	}
+
+	if cfg.AllowFailure {
+		out.AllowFailure = ptr.To(true)
+	}
+
+	out.Settings = convertHookConfigSettings(cfg.Settings)
+	out.LogLevelRaw = cfg.LogLevel
 }
 
 func convertKubernetesConfig(k *pkg.KubernetesConfig, queue string) gohook.KubernetesConfig {
@@ -343,11 +461,17 @@ func convertKubernetesConfig(k *pkg.KubernetesConfig, queue string) gohook.Kuber
 		ExecuteHookOnEvents:          k.ExecuteHookOnEvents,
 		ExecuteHookOnSynchronization: k.ExecuteHookOnSynchronization,
 		WaitForSynchronization:       k.WaitForSynchronization,
-		KeepFullObjectsInMemory:      ptr.To(k.JqFilter == ""),
+		KeepFullObjectsInMemory:      keepFullObjectsInMemory(k.KeepFullObjectsInMemory, k.JqFilter),
 		JqFilter:                     k.JqFilter,
 		AllowFailure:                 k.AllowFailure,
 		ResynchronizationPeriod:      k.ResynchronizationPeriod,
-		Queue:                        queue,
+		IncludeSnapshotsFrom:         k.IncludeSnapshotsFrom,
+		Queue:                        bindingQueue(k.Queue, queue),
+		Group:                        k.Group,
+	}
+
+	if len(k.ExecuteHookOnEventTypes) > 0 {
+		cfg.ExecuteHookOnEventTypes = convertWatchEventTypes(k.ExecuteHookOnEventTypes)
 	}
 
 	if k.NameSelector != nil {
@@ -381,11 +505,17 @@ func convertAppKubernetesConfig(k *pkg.ApplicationKubernetesConfig, queue string
 		ExecuteHookOnEvents:          k.ExecuteHookOnEvents,
 		ExecuteHookOnSynchronization: k.ExecuteHookOnSynchronization,
 		WaitForSynchronization:       k.WaitForSynchronization,
-		KeepFullObjectsInMemory:      ptr.To(k.JqFilter == ""),
+		KeepFullObjectsInMemory:      keepFullObjectsInMemory(k.KeepFullObjectsInMemory, k.JqFilter),
 		JqFilter:                     k.JqFilter,
 		AllowFailure:                 k.AllowFailure,
 		ResynchronizationPeriod:      k.ResynchronizationPeriod,
-		Queue:                        queue,
+		IncludeSnapshotsFrom:         k.IncludeSnapshotsFrom,
+		Queue:                        bindingQueue(k.Queue, queue),
+		Group:                        k.Group,
+	}
+
+	if len(k.ExecuteHookOnEventTypes) > 0 {
+		cfg.ExecuteHookOnEventTypes = convertWatchEventTypes(k.ExecuteHookOnEventTypes)
 	}
 
 	if k.NameSelector != nil {
@@ -403,3 +533,137 @@ func convertAppKubernetesConfig(k *pkg.ApplicationKubernetesConfig, queue string
 
 	return cfg
 }
+
+func convertValidatingConfig(v *pkg.ValidatingConfig) gohook.ValidatingConfig {
+	cfg := gohook.ValidatingConfig{
+		Name:                 v.Name,
+		Rules:                v.Rules,
+		FailurePolicy:        v.FailurePolicy,
+		SideEffects:          v.SideEffects,
+		TimeoutSeconds:       v.TimeoutSeconds,
+		MatchConditions:      v.MatchConditions,
+		LabelSelector:        v.LabelSelector,
+		IncludeSnapshotsFrom: v.IncludeSnapshotsFrom,
+		Group:                v.Group,
+	}
+
+	if v.NamespaceSelector != nil && v.NamespaceSelector.LabelSelector != nil {
+		cfg.Namespace = &gohook.AdmissionNamespaceSelector{LabelSelector: v.NamespaceSelector.LabelSelector}
+	}
+
+	return cfg
+}
+
+func convertMutatingConfig(m *pkg.MutatingConfig) gohook.MutatingConfig {
+	// both webhooks have the same config format
+	return gohook.MutatingConfig(convertValidatingConfig((*pkg.ValidatingConfig)(m)))
+}
+
+func convertConversionConfig(c *pkg.ConversionConfig) gohook.ConversionConfig {
+	cfg := gohook.ConversionConfig{
+		Name:                 c.Name,
+		CRDName:              c.CRDName,
+		Conversions:          make([]gohook.ConversionRule, 0, len(c.Conversions)),
+		IncludeSnapshotsFrom: c.IncludeSnapshotsFrom,
+		Group:                c.Group,
+	}
+
+	for _, rule := range c.Conversions {
+		cfg.Conversions = append(cfg.Conversions, gohook.ConversionRule(rule))
+	}
+
+	return cfg
+}
+
+func convertScheduleConfig(s pkg.ScheduleConfig, queue string) gohook.ScheduleConfig {
+	return gohook.ScheduleConfig{
+		Name:    s.Name,
+		Crontab: s.Crontab,
+		Queue:   bindingQueue(s.Queue, queue),
+		Group:   s.Group,
+	}
+}
+
+func convertHookConfigSettings(settings *pkg.HookConfigSettings) *gohook.HookConfigSettings {
+	if settings == nil {
+		return nil
+	}
+
+	return &gohook.HookConfigSettings{
+		ExecutionMinInterval:     settings.ExecutionMinInterval,
+		ExecutionBurst:           settings.ExecutionBurst,
+		EnableSchedulesOnStartup: settings.EnableSchedulesOnStartup,
+	}
+}
+
+// keepFullObjectsInMemory is always true without jq filter, because snapshot contains full objects in this case.
+func keepFullObjectsInMemory(keep *bool, jqFilter string) *bool {
+	if jqFilter == "" {
+		return ptr.To(true)
+	}
+
+	if keep != nil {
+		return keep
+	}
+
+	return ptr.To(false)
+}
+
+// bindingQueue returns the binding queue if it is set, otherwise the hook queue.
+func bindingQueue(bindingQueue, hookQueue string) string {
+	if bindingQueue != "" {
+		return bindingQueue
+	}
+
+	return hookQueue
+}
+
+func convertWatchEventTypes(eventTypes []pkg.WatchEventType) []string {
+	out := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		out = append(out, string(eventType))
+	}
+
+	return out
+}
+
+// exitWithError writes the hook error to stderr and exits, as expected by shell-operator.
+func exitWithError(hookName string, err error) error {
+	return exitWithOutputError(newOutputError(hookName, err))
+}
+
+func exitWithOutputError(outputErr *gohook.Error) error {
+	buf := bytes.NewBuffer([]byte{})
+
+	encodeErr := json.NewEncoder(buf).Encode(outputErr)
+	if encodeErr != nil {
+		return fmt.Errorf("encode error: %w", encodeErr)
+	}
+
+	fmt.Fprintln(os.Stderr, buf.String())
+	os.Exit(1)
+
+	return nil
+}
+
+// newOutputError converts the hook run error into the error reported to addon-operator.
+func newOutputError(hookName string, err error) *gohook.Error {
+	outputError := &gohook.Error{
+		Message:   "execute: " + err.Error(),
+		Code:      pkg.ErrorCode(err),
+		Retryable: pkg.IsRetryable(err),
+		Hook:      hookName,
+	}
+
+	var hookErr *executor.HookError
+	if errors.As(err, &hookErr) {
+		outputError.Bindings = hookErr.Bindings
+	}
+
+	var panicErr *pkg.PanicError
+	if errors.As(err, &panicErr) {
+		outputError.Stacktrace = panicErr.Stack
+	}
+
+	return outputError
+}
@@ -1,13 +1,26 @@
 package controller
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/deckhouse/deckhouse/pkg/log"
+
+	"github.com/deckhouse/module-sdk/internal/executor"
+	"github.com/deckhouse/module-sdk/internal/transport/stream"
 	"github.com/deckhouse/module-sdk/pkg"
+	gohook "github.com/deckhouse/module-sdk/pkg/hook"
+	"github.com/deckhouse/module-sdk/pkg/utils/ptr"
 )
 
 func TestConvertAppKubernetesConfig(t *testing.T) {
@@ -157,3 +170,358 @@ func TestConvertAppKubernetesConfig(t *testing.T) {
 		assert.Nil(t, cfg.NamespaceSelector, "application hook config must never set NamespaceSelector")
 	})
 }
+
+func TestConvertKubernetesConfig(t *testing.T) {
+	t.Run("binding queue overrides hook queue", func(t *testing.T) {
+		k := &pkg.KubernetesConfig{
+			Name:  "pods",
+			Kind:  "Pod",
+			Queue: "pods-queue",
+		}
+		cfg := convertKubernetesConfig(k, "main")
+		assert.Equal(t, "pods-queue", cfg.Queue)
+	})
+
+	t.Run("KeepFullObjectsInMemory is passed through when JqFilter is set", func(t *testing.T) {
+		k := &pkg.KubernetesConfig{
+			Name:                    "pods",
+			Kind:                    "Pod",
+			JqFilter:                ".metadata.name",
+			KeepFullObjectsInMemory: ptr.To(true),
+		}
+		cfg := convertKubernetesConfig(k, "main")
+		require.NotNil(t, cfg.KeepFullObjectsInMemory)
+		assert.True(t, *cfg.KeepFullObjectsInMemory)
+	})
+
+	t.Run("KeepFullObjectsInMemory is always true when JqFilter is empty", func(t *testing.T) {
+		k := &pkg.KubernetesConfig{
+			Name:                    "pods",
+			Kind:                    "Pod",
+			KeepFullObjectsInMemory: ptr.To(false),
+		}
+		cfg := convertKubernetesConfig(k, "main")
+		require.NotNil(t, cfg.KeepFullObjectsInMemory)
+		assert.True(t, *cfg.KeepFullObjectsInMemory)
+	})
+
+	t.Run("IncludeSnapshotsFrom is passed through", func(t *testing.T) {
+		k := &pkg.KubernetesConfig{
+			Name:                 "pods",
+			Kind:                 "Pod",
+			IncludeSnapshotsFrom: []string{"pods", "nodes"},
+		}
+		cfg := convertKubernetesConfig(k, "main")
+		assert.Equal(t, []string{"pods", "nodes"}, cfg.IncludeSnapshotsFrom)
+	})
+}
+
+func TestRemapHookConfigToGohook(t *testing.T) {
+	t.Run("module hook settings, allow failure and log level are mapped", func(t *testing.T) {
+		cfg := &pkg.HookConfig{
+			Metadata: pkg.HookMetadata{Name: "hook", Path: "hooks/"},
+			Schedule: []pkg.ScheduleConfig{
+				{Name: "default", Crontab: "* * * * *"},
+				{Name: "custom", Crontab: "* * * * *", Queue: "custom"},
+			},
+			AllowFailure: true,
+			Queue:        "main",
+			Settings: &pkg.HookConfigSettings{
+				ExecutionMinInterval:     time.Minute,
+				ExecutionBurst:           3,
+				EnableSchedulesOnStartup: ptr.To(true),
+			},
+			LogLevel: "debug",
+		}
+
+		out := remapHookConfigToGohook(cfg)
+		require.Len(t, out.Schedule, 2)
+		assert.Equal(t, "main", out.Schedule[0].Queue)
+		assert.Equal(t, "custom", out.Schedule[1].Queue)
+		require.NotNil(t, out.AllowFailure)
+		assert.True(t, *out.AllowFailure)
+		require.NotNil(t, out.Settings)
+		assert.Equal(t, time.Minute, out.Settings.ExecutionMinInterval)
+		assert.Equal(t, 3, out.Settings.ExecutionBurst)
+		require.NotNil(t, out.Settings.EnableSchedulesOnStartup)
+		assert.True(t, *out.Settings.EnableSchedulesOnStartup)
+		assert.Equal(t, "debug", out.LogLevelRaw)
+	})
+
+	t.Run("application hook settings and allow failure are mapped", func(t *testing.T) {
+		cfg := &pkg.ApplicationHookConfig{
+			Metadata:     pkg.HookMetadata{Name: "hook", Path: "hooks/"},
+			AllowFailure: true,
+			Settings:     &pkg.HookConfigSettings{ExecutionBurst: 1},
+		}
+
+		out := remapHookConfigToGohook(cfg)
+		require.NotNil(t, out.AllowFailure)
+		assert.True(t, *out.AllowFailure)
+		require.NotNil(t, out.Settings)
+		assert.Equal(t, 1, out.Settings.ExecutionBurst)
+	})
+
+	t.Run("empty optional fields are omitted", func(t *testing.T) {
+		out := remapHookConfigToGohook(&pkg.HookConfig{})
+		assert.Nil(t, out.AllowFailure)
+		assert.Nil(t, out.Settings)
+		assert.Empty(t, out.LogLevelRaw)
+	})
+}
+
+func TestConvertExecuteHookOnEventTypes(t *testing.T) {
+	t.Run("event types are converted for module hooks", func(t *testing.T) {
+		k := &pkg.KubernetesConfig{
+			Name:                    "pods",
+			Kind:                    "Pod",
+			ExecuteHookOnEventTypes: []pkg.WatchEventType{pkg.WatchEventAdded, pkg.WatchEventModified},
+		}
+		cfg := convertKubernetesConfig(k, "main")
+		assert.Equal(t, []string{"Added", "Modified"}, cfg.ExecuteHookOnEventTypes)
+	})
+
+	t.Run("event types are converted for application hooks", func(t *testing.T) {
+		k := &pkg.ApplicationKubernetesConfig{
+			Name:                    "pods",
+			Kind:                    "Pod",
+			ExecuteHookOnEventTypes: []pkg.WatchEventType{pkg.WatchEventDeleted},
+		}
+		cfg := convertAppKubernetesConfig(k, "main")
+		assert.Equal(t, []string{"Deleted"}, cfg.ExecuteHookOnEventTypes)
+	})
+
+	t.Run("event types are omitted when not set", func(t *testing.T) {
+		k := &pkg.KubernetesConfig{Name: "pods", Kind: "Pod"}
+		cfg := convertKubernetesConfig(k, "main")
+		assert.Nil(t, cfg.ExecuteHookOnEventTypes)
+	})
+
+	t.Run("event types are marshaled as executeHookOnEvent", func(t *testing.T) {
+		k := &pkg.KubernetesConfig{
+			Name:                    "pods",
+			Kind:                    "Pod",
+			ExecuteHookOnEventTypes: []pkg.WatchEventType{pkg.WatchEventAdded, pkg.WatchEventModified},
+		}
+
+		data, err := json.Marshal(convertKubernetesConfig(k, "main"))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"name":"pods","kind":"Pod","queue":"main","keepFullObjectsInMemory":true,"executeHookOnEvent":["Added","Modified"]}`, string(data))
+
+		var cfg gohook.KubernetesConfig
+		require.NoError(t, json.Unmarshal(data, &cfg))
+		assert.Equal(t, []string{"Added", "Modified"}, cfg.ExecuteHookOnEventTypes)
+		assert.Nil(t, cfg.ExecuteHookOnEvents)
+	})
+
+	t.Run("disabled events are marshaled as before", func(t *testing.T) {
+		k := &pkg.KubernetesConfig{Name: "pods", Kind: "Pod", ExecuteHookOnEvents: ptr.To(false)}
+
+		data, err := json.Marshal(convertKubernetesConfig(k, "main"))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"name":"pods","kind":"Pod","queue":"main","keepFullObjectsInMemory":true,"executeHookOnEvent":false}`, string(data))
+
+		var cfg gohook.KubernetesConfig
+		require.NoError(t, json.Unmarshal(data, &cfg))
+		require.NotNil(t, cfg.ExecuteHookOnEvents)
+		assert.False(t, *cfg.ExecuteHookOnEvents)
+		assert.Nil(t, cfg.ExecuteHookOnEventTypes)
+	})
+}
+
+func TestConvertGroup(t *testing.T) {
+	cfg := &pkg.HookConfig{
+		Schedule: []pkg.ScheduleConfig{
+			{Name: "every-minute", Crontab: "* * * * *", Group: "policies"},
+		},
+		Kubernetes: []pkg.KubernetesConfig{
+			{Name: "pods", Kind: "Pod", Group: "policies"},
+			{Name: "nodes", Kind: "Node"},
+		},
+	}
+
+	out := remapHookConfigToGohook(cfg)
+	require.Len(t, out.Schedule, 1)
+	assert.Equal(t, "policies", out.Schedule[0].Group)
+	require.Len(t, out.Kubernetes, 2)
+	assert.Equal(t, "policies", out.Kubernetes[0].Group)
+	assert.Empty(t, out.Kubernetes[1].Group)
+}
+
+func TestConvertValidatingConfig(t *testing.T) {
+	failurePolicy := admissionregv1.Ignore
+	cfg := &pkg.HookConfig{
+		KubernetesValidating: []pkg.ValidatingConfig{
+			{
+				Name: "policy.example.deckhouse.io",
+				Rules: []admissionregv1.RuleWithOperations{
+					{
+						Operations: []admissionregv1.OperationType{admissionregv1.Create},
+						Rule: admissionregv1.Rule{
+							APIGroups:   []string{""},
+							APIVersions: []string{"v1"},
+							Resources:   []string{"pods"},
+						},
+					},
+				},
+				FailurePolicy:  &failurePolicy,
+				TimeoutSeconds: ptr.To(int32(5)),
+				NamespaceSelector: &pkg.NamespaceSelector{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"heritage": "deckhouse"}},
+				},
+				IncludeSnapshotsFrom: []string{"pods"},
+				Group:                "policies",
+			},
+		},
+	}
+
+	out := remapHookConfigToGohook(cfg)
+	require.Len(t, out.KubernetesValidating, 1)
+
+	raw, err := json.Marshal(out.KubernetesValidating[0])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"name": "policy.example.deckhouse.io",
+		"rules": [{"operations": ["CREATE"], "apiGroups": [""], "apiVersions": ["v1"], "resources": ["pods"]}],
+		"failurePolicy": "Ignore",
+		"timeoutSeconds": 5,
+		"namespace": {"labelSelector": {"matchLabels": {"heritage": "deckhouse"}}},
+		"includeSnapshotsFrom": ["pods"],
+		"group": "policies"
+	}`, string(raw))
+}
+
+func TestConvertMutatingConfig(t *testing.T) {
+	sideEffects := admissionregv1.SideEffectClassNone
+	cfg := &pkg.HookConfig{
+		KubernetesMutating: []pkg.MutatingConfig{
+			{
+				Name: "defaults.example.deckhouse.io",
+				Rules: []admissionregv1.RuleWithOperations{
+					{
+						Operations: []admissionregv1.OperationType{admissionregv1.Create, admissionregv1.Update},
+						Rule: admissionregv1.Rule{
+							APIGroups:   []string{"apps"},
+							APIVersions: []string{"v1"},
+							Resources:   []string{"deployments"},
+						},
+					},
+				},
+				SideEffects:   &sideEffects,
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			},
+		},
+	}
+
+	out := remapHookConfigToGohook(cfg)
+	require.Empty(t, out.KubernetesValidating)
+
+	raw, err := json.Marshal(out.KubernetesMutating)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{
+		"name": "defaults.example.deckhouse.io",
+		"rules": [{"operations": ["CREATE", "UPDATE"], "apiGroups": ["apps"], "apiVersions": ["v1"], "resources": ["deployments"]}],
+		"sideEffects": "None",
+		"labelSelector": {"matchLabels": {"app": "web"}}
+	}]`, string(raw))
+}
+
+func TestConvertConversionConfig(t *testing.T) {
+	cfg := &pkg.HookConfig{
+		KubernetesConversion: []pkg.ConversionConfig{
+			{
+				Name:    "widgets",
+				CRDName: "widgets.example.io",
+				Conversions: []pkg.ConversionRule{
+					{FromVersion: "example.io/v1alpha1", ToVersion: "example.io/v1"},
+				},
+				IncludeSnapshotsFrom: []string{"nodes"},
+			},
+		},
+	}
+
+	out := remapHookConfigToGohook(cfg)
+
+	raw, err := json.Marshal(out)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"configVersion": "v1",
+		"metadata": {"name": "", "path": ""},
+		"kubernetesCustomResourceConversion": [{
+			"name": "widgets",
+			"crdName": "widgets.example.io",
+			"conversions": [{"fromVersion": "example.io/v1alpha1", "toVersion": "example.io/v1"}],
+			"includeSnapshotsFrom": ["nodes"]
+		}]
+	}`, string(raw))
+}
+
+func TestNewOutputError(t *testing.T) {
+	err := &executor.HookError{
+		Hook:     "002-hook/main",
+		Bindings: []string{"pods"},
+		Err:      fmt.Errorf("hook reconcile func: %w", pkg.NewConfigurationError(errors.New("replicas must be positive"))),
+	}
+
+	assert.Equal(t, &gohook.Error{
+		Message:  "execute: hook reconcile func: replicas must be positive (code: 3)",
+		Code:     pkg.ErrorCodeConfiguration,
+		Hook:     "002-hook/main",
+		Bindings: []string{"pods"},
+	}, newOutputError("002-hook/main", err))
+
+	panicErr := fmt.Errorf("hook reconcile func: hook panicked: %w", &pkg.PanicError{Value: "boom", Stack: "goroutine 1 [running]:"})
+
+	assert.Equal(t, &gohook.Error{
+		Message:    "execute: hook reconcile func: hook panicked: boom",
+		Code:       pkg.ErrorCodePanic,
+		Hook:       "readiness",
+		Stacktrace: "goroutine 1 [running]:",
+	}, newOutputError("readiness", panicErr))
+
+	assert.Equal(t, &gohook.Error{
+		Message:   "execute: get values: read error",
+		Retryable: true,
+		Hook:      "readiness",
+	}, newOutputError("readiness", fmt.Errorf("get values: %w", errors.New("read error"))))
+}
+
+func TestNewHookController_ValuesRootKey(t *testing.T) {
+	// the hook patches values of another module
+	hook := pkg.Hook[pkg.HookConfig, *pkg.HookInput]{
+		Config: pkg.HookConfig{Metadata: pkg.HookMetadata{Name: "patch-other-module"}, OnStartup: &pkg.OrderedConfig{Order: 1}},
+		HookFunc: func(_ context.Context, input *pkg.HookInput) error {
+			input.Values.Set("otherModule.replicas", 2)
+			return nil
+		},
+	}
+
+	run := func(t *testing.T, cfg *Config) error {
+		t.Helper()
+
+		c := NewHookController(cfg, log.NewNop())
+		c.registry.RegisterModuleHooks(hook)
+
+		exec, err := c.findHook("patch-other-module")
+		require.NoError(t, err)
+
+		req, err := stream.NewTransport(strings.NewReader(`{}`), nil, c.dc, c.logger).NewRequest()
+		require.NoError(t, err)
+
+		_, err = c.execute(context.Background(), exec, req)
+
+		return err
+	}
+
+	t.Run("module name is not set", func(t *testing.T) {
+		// app.Run sets the default module name, values roots are not checked without MODULE_NAME
+		err := run(t, &Config{ModuleName: "default-module", HookConfig: &HookConfig{}})
+		require.NoError(t, err)
+	})
+
+	t.Run("module name is set", func(t *testing.T) {
+		err := run(t, &Config{ModuleName: "my-module", ValuesRootKey: "myModule", HookConfig: &HookConfig{}})
+		require.ErrorContains(t, err, `hook "patch-other-module" patches values outside of allowed roots ["myModule"]`)
+		assert.Equal(t, pkg.ErrorCodePermanent, pkg.ErrorCode(err))
+	})
+}
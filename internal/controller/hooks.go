@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/deckhouse/module-sdk/internal/executor"
+	gohook "github.com/deckhouse/module-sdk/pkg/hook"
+)
+
+var ErrHookNotFound = errors.New("hook not found")
+
+// ListHooks describes registered hooks in the order of their indexes.
+func (c *HookController) ListHooks() []gohook.HookInfo {
+	hooks := c.registry.Executors()
+
+	infos := make([]gohook.HookInfo, 0, len(hooks))
+	for idx, hook := range hooks {
+		meta := hook.Config().GetMetadata()
+
+		kind := gohook.HookKindApplication
+		if _, ok := hook.Config().AsHookConfig(); ok {
+			kind = gohook.HookKindModule
+		}
+
+		infos = append(infos, gohook.HookInfo{
+			Index:    idx,
+			Name:     meta.Name,
+			Path:     hookPath(hook),
+			Kind:     kind,
+			Bindings: hookBindings(remapHookConfigToGohook(hook.Config())),
+		})
+	}
+
+	return infos
+}
+
+// RunHookByName runs the hook with the name, e.g. "subfolder/my_hook", or the path,
+// e.g. "my-module/hooks/subfolder/my_hook.go". Unlike the index, they do not depend on the registration order.
+func (c *HookController) RunHookByName(ctx context.Context, name string) error {
+	hook, err := c.findHook(name)
+	if err != nil {
+		return err
+	}
+
+	return c.run(ctx, hook)
+}
+
+func (c *HookController) findHook(name string) (executor.Executor, error) {
+	var found []executor.Executor
+	for _, hook := range c.registry.Executors() {
+		hookPath := hookPath(hook)
+		if hook.Config().GetMetadata().Name == name || hookPath == name || hookPath+".go" == name {
+			found = append(found, hook)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("%w: %q", ErrHookNotFound, name)
+	case 1:
+		return found[0], nil
+	}
+
+	paths := make([]string, 0, len(found))
+	for _, hook := range found {
+		paths = append(paths, hookPath(hook))
+	}
+
+	return nil, fmt.Errorf("hook name %q is ambiguous, use the path: %s", name, strings.Join(paths, ", "))
+}
+
+// hookPath returns the path of the hook file without the extension, e.g. "my-module/hooks/subfolder/my_hook".
+func hookPath(hook executor.Executor) string {
+	meta := hook.Config().GetMetadata()
+
+	return meta.Path + path.Base(meta.Name)
+}
+
+func hookBindings(cfg *gohook.HookConfig) []gohook.HookBinding {
+	var bindings []gohook.HookBinding
+
+	ordered := []struct {
+		binding string
+		order   *uint
+	}{
+		{"onStartup", cfg.OnStartup},
+		{"beforeHelm", cfg.OnBeforeHelm},
+		{"afterHelm", cfg.OnAfterHelm},
+		{"beforeDeleteHelm", cfg.OnBeforeDeleteHelm},
+		{"afterDeleteHelm", cfg.OnAfterDeleteHelm},
+	}
+
+	for _, o := range ordered {
+		if o.order != nil {
+			bindings = append(bindings, gohook.HookBinding{Type: o.binding})
+		}
+	}
+
+	for _, s := range cfg.Schedule {
+		bindings = append(bindings, gohook.HookBinding{Type: "schedule", Name: s.Name})
+	}
+
+	for _, k := range cfg.Kubernetes {
+		bindings = append(bindings, gohook.HookBinding{Type: "kubernetes", Name: k.Name})
+	}
+
+	for _, v := range cfg.KubernetesValidating {
+		bindings = append(bindings, gohook.HookBinding{Type: "kubernetesValidating", Name: v.Name})
+	}
+
+	for _, m := range cfg.KubernetesMutating {
+		bindings = append(bindings, gohook.HookBinding{Type: "kubernetesMutating", Name: m.Name})
+	}
+
+	for _, conv := range cfg.KubernetesConversion {
+		bindings = append(bindings, gohook.HookBinding{Type: "kubernetesCustomResourceConversion", Name: conv.Name})
+	}
+
+	return bindings
+}
+
+// orderFingerprint identifies the order of hooks, so a changed order can be detected
+// before a hook is run by index.
+func (c *HookController) orderFingerprint() string {
+	h := sha256.New()
+	for _, hook := range c.registry.Executors() {
+		h.Write([]byte(hookPath(hook)))
+		h.Write([]byte{'\n'})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// checkOrderFingerprint warns if the order of hooks differs from the order in the last dumped config,
+// as the index can point to another hook then.
+func (c *HookController) checkOrderFingerprint(idx int) {
+	data, err := os.ReadFile(c.fConfig.HookConfigPath)
+	if err != nil {
+		// the config is not dumped
+		return
+	}
+
+	dumped := new(gohook.BatchHookConfig)
+	if err := json.Unmarshal(data, dumped); err != nil || dumped.OrderFingerprint == "" {
+		return
+	}
+
+	if current := c.orderFingerprint(); dumped.OrderFingerprint != current {
+		c.logger.Warn("hooks order differs from the dumped config, the index can point to another hook, run hooks by name",
+			slog.Int("index", idx),
+			slog.String("hook", c.registry.Executors()[idx].Config().GetMetadata().Name),
+			slog.String("dumped_fingerprint", dumped.OrderFingerprint),
+			slog.String("fingerprint", current),
+		)
+	}
+}
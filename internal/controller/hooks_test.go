@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/deckhouse/deckhouse/pkg/log"
+
+	execregistry "github.com/deckhouse/module-sdk/internal/executor/registry"
+	"github.com/deckhouse/module-sdk/internal/transport/file"
+	"github.com/deckhouse/module-sdk/pkg"
+	gohook "github.com/deckhouse/module-sdk/pkg/hook"
+)
+
+func newTestModuleHook(name, path string, cfg pkg.HookConfig) pkg.Hook[pkg.HookConfig, *pkg.HookInput] {
+	cfg.Metadata = pkg.HookMetadata{Name: name, Path: path}
+
+	return pkg.Hook[pkg.HookConfig, *pkg.HookInput]{
+		Config:   cfg,
+		HookFunc: func(_ context.Context, _ *pkg.HookInput) error { return nil },
+	}
+}
+
+func newTestHooksController(t *testing.T, logger *log.Logger) *HookController {
+	t.Helper()
+
+	reg := execregistry.NewRegistry(logger)
+	reg.RegisterModuleHooks(
+		newTestModuleHook("subfolder/my_hook", "my-module/hooks/subfolder/", pkg.HookConfig{
+			OnBeforeHelm: &pkg.OrderedConfig{Order: 10},
+			Kubernetes:   []pkg.KubernetesConfig{{Name: "pods", APIVersion: "v1", Kind: "Pod"}},
+		}),
+		newTestModuleHook("my_hook", "other-module/hooks/", pkg.HookConfig{
+			Schedule: []pkg.ScheduleConfig{{Name: "every-minute", Crontab: "* * * * *"}},
+		}),
+		newTestModuleHook("my_hook", "my-module/hooks/", pkg.HookConfig{OnStartup: &pkg.OrderedConfig{Order: 1}}),
+	)
+	reg.RegisterAppHooks(pkg.Hook[pkg.ApplicationHookConfig, *pkg.ApplicationHookInput]{
+		Config: pkg.ApplicationHookConfig{
+			Metadata:    pkg.HookMetadata{Name: "app_hook", Path: "my-app/hooks/"},
+			OnAfterHelm: &pkg.OrderedConfig{Order: 1},
+		},
+		HookFunc: func(_ context.Context, _ *pkg.ApplicationHookInput) error { return nil },
+	})
+
+	return &HookController{
+		registry: reg,
+		fConfig: &file.Config{
+			HookConfigPath:        filepath.Join(t.TempDir(), "hook_config.json"),
+			CreateFilesByYourself: true,
+		},
+		logger: logger,
+	}
+}
+
+func TestListHooks(t *testing.T) {
+	c := newTestHooksController(t, log.NewNop())
+
+	assert.Equal(t, []gohook.HookInfo{
+		{
+			Index: 0, Name: "subfolder/my_hook", Path: "my-module/hooks/subfolder/my_hook", Kind: gohook.HookKindModule,
+			Bindings: []gohook.HookBinding{{Type: "beforeHelm"}, {Type: "kubernetes", Name: "pods"}},
+		},
+		{
+			Index: 1, Name: "my_hook", Path: "other-module/hooks/my_hook", Kind: gohook.HookKindModule,
+			Bindings: []gohook.HookBinding{{Type: "schedule", Name: "every-minute"}},
+		},
+		{
+			Index: 2, Name: "my_hook", Path: "my-module/hooks/my_hook", Kind: gohook.HookKindModule,
+			Bindings: []gohook.HookBinding{{Type: "onStartup"}},
+		},
+		{
+			Index: 3, Name: "app_hook", Path: "my-app/hooks/app_hook", Kind: gohook.HookKindApplication,
+			Bindings: []gohook.HookBinding{{Type: "afterHelm"}},
+		},
+	}, c.ListHooks())
+}
+
+func TestFindHook(t *testing.T) {
+	c := newTestHooksController(t, log.NewNop())
+
+	tests := []struct {
+		name     string
+		expected string
+		err      string
+	}{
+		{name: "subfolder/my_hook", expected: "my-module/hooks/subfolder/my_hook"},
+		{name: "my-module/hooks/subfolder/my_hook", expected: "my-module/hooks/subfolder/my_hook"},
+		{name: "my-module/hooks/my_hook.go", expected: "my-module/hooks/my_hook"},
+		{name: "app_hook", expected: "my-app/hooks/app_hook"},
+		{name: "my_hook", err: `hook name "my_hook" is ambiguous, use the path: other-module/hooks/my_hook, my-module/hooks/my_hook`},
+		{name: "unknown", err: `hook not found: "unknown"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook, err := c.findHook(tt.name)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, hookPath(hook))
+		})
+	}
+}
+
+func TestCheckOrderFingerprint(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	c := newTestHooksController(t, log.NewLogger(log.WithOutput(buf)))
+
+	// no dumped config
+	c.checkOrderFingerprint(0)
+	assert.Empty(t, buf.String())
+
+	require.NoError(t, c.WriteHookConfigsInFile())
+
+	c.checkOrderFingerprint(0)
+	assert.Empty(t, buf.String())
+
+	// a new hook changes the order
+	c.registry.RegisterModuleHooks(newTestModuleHook("new_hook", "my-module/hooks/", pkg.HookConfig{}))
+
+	c.checkOrderFingerprint(1)
+	assert.Contains(t, buf.String(), "hooks order differs from the dumped config")
+	assert.Contains(t, buf.String(), `"hook":"my_hook"`)
+}
@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/deckhouse/module-sdk/internal/executor"
+	"github.com/deckhouse/module-sdk/internal/record"
+	"github.com/deckhouse/module-sdk/internal/transport/stream"
+	gohook "github.com/deckhouse/module-sdk/pkg/hook"
+)
+
+var ErrReplayOutputsDiffer = errors.New("replayed outputs differ from the recording")
+
+// SetRecordPath enables recording of hook runs into the directory or the tarball, see record.Write.
+// The recording is overwritten by the next run, unlike recordings of runs into RecordDir of the config.
+func (c *HookController) SetRecordPath(path string) {
+	c.recordPath = path
+}
+
+// recording reports whether hook runs are recorded.
+func (c *HookController) recording() bool {
+	return c.recordPath != "" || c.recordDir != ""
+}
+
+// execute runs the hook, recording the run if recording is enabled.
+func (c *HookController) execute(ctx context.Context, hook executor.Executor, req executor.Request) (executor.Result, error) {
+	if !c.recording() {
+		return hook.Execute(ctx, req)
+	}
+
+	hookName := hook.Config().GetMetadata().Name
+	meta := record.NewMeta(hookName, hookPath(hook), time.Now())
+
+	// inputs are read before the run, so they are recorded even if the hook does not read them
+	input, err := encodeStreamInput(req)
+	if err != nil {
+		c.logger.Error("hook run is not recorded", slog.String("error", fmt.Sprintf("read input: %s", err)))
+		return hook.Execute(ctx, req)
+	}
+
+	interactions := new(record.Interactions)
+	dc := record.NewRecordingContainer(req.GetDependencyContainer(), interactions)
+
+	recordedReq, err := stream.NewTransport(bytes.NewReader(input), nil, dc, c.logger.Named("stream-transport")).NewRequest()
+	if err != nil {
+		c.logger.Error("hook run is not recorded", slog.String("error", err.Error()))
+		return hook.Execute(ctx, req)
+	}
+
+	res, execErr := hook.Execute(ctx, recordedReq)
+
+	rec := &record.Recording{
+		Meta:         meta,
+		Input:        new(gohook.StreamInput),
+		Interactions: interactions,
+	}
+
+	err = json.Unmarshal(input, rec.Input)
+	if err == nil {
+		rec.Output, err = c.streamOutput(hookName, res, execErr)
+	}
+
+	path := c.recordPath
+	if err == nil {
+		if path != "" {
+			err = record.Write(path, rec)
+		} else {
+			path, err = record.WriteRun(c.recordDir, rec)
+		}
+	}
+
+	if err != nil {
+		c.logger.Error("hook run is not recorded", slog.String("error", err.Error()))
+	} else {
+		c.logger.Info("hook run recorded", slog.String("hook", hookName), slog.String("path", path))
+	}
+
+	return res, execErr
+}
+
+// Replay runs the recorded hook with the recorded input, replaying recorded interactions of the dependency
+// container, and writes the differences between the recorded and the new outputs to w.
+// It returns ErrReplayOutputsDiffer if the outputs differ.
+func (c *HookController) Replay(ctx context.Context, path string, w io.Writer) error {
+	rec, err := record.Read(path)
+	if err != nil {
+		return fmt.Errorf("read recording: %w", err)
+	}
+
+	hook, err := c.findHook(rec.Meta.HookPath)
+	if err != nil {
+		// the path is different if the hook binary is built from another checkout
+		hook, err = c.findHook(rec.Meta.HookName)
+		if err != nil {
+			return err
+		}
+	}
+
+	hookName := hook.Config().GetMetadata().Name
+
+	input, err := json.Marshal(rec.Input)
+	if err != nil {
+		return fmt.Errorf("marshal input: %w", err)
+	}
+
+	dc := record.NewReplayContainer(rec.Interactions, rec.Meta.Time)
+
+	req, err := stream.NewTransport(bytes.NewReader(input), nil, dc, c.logger.Named("stream-transport")).NewRequest()
+	if err != nil {
+		return fmt.Errorf("read input: %w", err)
+	}
+
+	res, execErr := hook.Execute(ctx, req)
+
+	output, err := c.streamOutput(hookName, res, execErr)
+	if err != nil {
+		return err
+	}
+
+	diff, err := record.Diff(rec.Output, output)
+	if err != nil {
+		return fmt.Errorf("diff: %w", err)
+	}
+
+	fmt.Fprintf(w, "hook %q recorded at %s with SDK %s, replayed with SDK %s\n",
+		hookName, rec.Meta.Time.Format(time.RFC3339), rec.Meta.SDKVersion, record.SDKVersion())
+
+	if len(diff) == 0 {
+		fmt.Fprintln(w, "outputs match the recording")
+		return nil
+	}
+
+	for _, d := range diff {
+		fmt.Fprintln(w, d)
+	}
+
+	return ErrReplayOutputsDiffer
+}
+
+// streamOutput returns the output of the run in the stream transport format.
+func (c *HookController) streamOutput(hookName string, res executor.Result, execErr error) (*gohook.StreamOutput, error) {
+	var outputErr *gohook.Error
+	if execErr != nil {
+		outputErr = newOutputError(hookName, execErr)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := stream.NewTransport(nil, buf, nil, c.logger.Named("stream-transport")).NewResponse().Send(res, outputErr); err != nil {
+		return nil, fmt.Errorf("output: %w", err)
+	}
+
+	output := new(gohook.StreamOutput)
+	if err := json.Unmarshal(buf.Bytes(), output); err != nil {
+		return nil, fmt.Errorf("decode output: %w", err)
+	}
+
+	return output, nil
+}
@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/deckhouse/deckhouse/pkg/log"
+
+	execregistry "github.com/deckhouse/module-sdk/internal/executor/registry"
+	"github.com/deckhouse/module-sdk/internal/record"
+	"github.com/deckhouse/module-sdk/internal/transport/stream"
+	"github.com/deckhouse/module-sdk/pkg"
+	"github.com/deckhouse/module-sdk/pkg/dependency"
+)
+
+func TestRecordReplay(t *testing.T) {
+	replicas := "3"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, replicas)
+	}))
+	defer srv.Close()
+
+	// the hook sets replicas fetched over HTTP, the bump is changed between the record and the replay
+	bump := 0
+	reg := execregistry.NewRegistry(log.NewNop())
+	reg.RegisterModuleHooks(pkg.Hook[pkg.HookConfig, *pkg.HookInput]{
+		Config: pkg.HookConfig{Metadata: pkg.HookMetadata{Name: "fetch-replicas", Path: "my-module/hooks/"}, OnStartup: &pkg.OrderedConfig{Order: 1}},
+		HookFunc: func(ctx context.Context, input *pkg.HookInput) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+			if err != nil {
+				return err
+			}
+
+			resp, err := input.DC.GetHTTPClient().Do(req)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return err
+			}
+
+			var n int
+			if _, err := fmt.Sscan(string(body), &n); err != nil {
+				return err
+			}
+
+			input.Values.Set("myModule.replicas", n+bump)
+
+			return nil
+		},
+	})
+
+	path := filepath.Join(t.TempDir(), "run.tar.gz")
+
+	c := &HookController{
+		registry:   reg,
+		dc:         dependency.NewDependencyContainer(),
+		recordPath: path,
+		logger:     log.NewNop(),
+	}
+
+	req, err := stream.NewTransport(strings.NewReader(`{"values": {"myModule": {"replicas": 1}}}`), nil, c.dc, c.logger).NewRequest()
+	require.NoError(t, err)
+
+	hook, err := c.findHook("fetch-replicas")
+	require.NoError(t, err)
+
+	_, err = c.execute(context.Background(), hook, req)
+	require.NoError(t, err)
+
+	rec, err := record.Read(path)
+	require.NoError(t, err)
+	assert.Equal(t, "my-module/hooks/fetch-replicas", rec.Meta.HookPath)
+	assert.JSONEq(t, `[{"op":"add","path":"/myModule/replicas","value":3}]`, string(rec.Output.ValuesPatches))
+	require.Len(t, rec.Interactions.HTTP, 1)
+
+	// the replay does not reach the server
+	replicas = "5"
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, c.Replay(context.Background(), path, buf))
+	assert.Contains(t, buf.String(), "outputs match the recording")
+
+	bump = 1
+
+	buf.Reset()
+	require.ErrorIs(t, c.Replay(context.Background(), path, buf), ErrReplayOutputsDiffer)
+	assert.Contains(t, buf.String(), "valuesPatches:\n"+
+		`  recorded: [{"op":"add","path":"/myModule/replicas","value":3}]`+"\n"+
+		`  replayed: [{"op":"add","path":"/myModule/replicas","value":4}]`)
+
+	// runs recorded into the record dir do not overwrite each other
+	c.recordPath = ""
+	c.recordDir = filepath.Join(t.TempDir(), "recordings")
+
+	for range 2 {
+		req, err := stream.NewTransport(strings.NewReader(`{"values": {"myModule": {"replicas": 1}}}`), nil, c.dc, c.logger).NewRequest()
+		require.NoError(t, err)
+
+		_, err = c.execute(context.Background(), hook, req)
+		require.NoError(t, err)
+	}
+
+	recordings, err := filepath.Glob(filepath.Join(c.recordDir, "*-fetch-replicas.tar.gz"))
+	require.NoError(t, err)
+	require.Len(t, recordings, 2)
+
+	buf.Reset()
+	require.NoError(t, c.Replay(context.Background(), recordings[0], buf))
+	assert.Contains(t, buf.String(), "outputs match the recording")
+}
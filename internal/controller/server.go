@@ -0,0 +1,204 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/deckhouse/module-sdk/internal/executor"
+	"github.com/deckhouse/module-sdk/internal/transport/stream"
+)
+
+// DefaultServerShutdownTimeout is the time given to running hooks to finish when the hook server stops.
+const DefaultServerShutdownTimeout = 30 * time.Second
+
+// Hook server API paths. Run requests and responses are the documents of the stream transport,
+// gohook.StreamInput and gohook.StreamOutput.
+const (
+	serverRunPath       = "/v1/run/"
+	serverReadinessPath = "/v1/readiness"
+	serverHealthPath    = "/healthz"
+	serverReadyPath     = "/readyz"
+)
+
+// ServerSocket returns the socket of the hook server from the config.
+func (c *HookController) ServerSocket() string {
+	return c.serverSocket
+}
+
+// Serve runs registered hooks by name on requests to the Unix socket until ctx is done.
+// Hook runs share the dependency container, so clients are not created on every run.
+// Runs are serialized like addon-operator runs hooks of a module, concurrent requests wait for their turn.
+// On shutdown running hooks are given shutdownTimeout to finish, then they are cancelled.
+func (c *HookController) Serve(ctx context.Context, socket string, shutdownTimeout time.Duration) error {
+	if c.recording() {
+		c.logger.Warn("hook runs are not recorded by the hook server")
+		c.recordPath = ""
+		c.recordDir = ""
+	}
+
+	listener, err := listenUnix(socket)
+	if err != nil {
+		return err
+	}
+
+	// runs are not cancelled with ctx, so they can finish during the shutdown
+	runCtx, cancelRuns := context.WithCancelCause(context.WithoutCancel(ctx))
+	defer cancelRuns(nil)
+
+	var shuttingDown atomic.Bool
+
+	srv := &http.Server{
+		Handler:           c.serverHandler(&shuttingDown),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return runCtx
+		},
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(listener)
+	}()
+
+	c.logger.Info("hook server started", slog.String("socket", socket))
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("serve: %w", err)
+	case <-ctx.Done():
+	}
+
+	shuttingDown.Store(true)
+	c.logger.Info("hook server shutdown", slog.String("cause", context.Cause(ctx).Error()))
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		cancelRuns(errors.New("hook server shutdown timeout exceeded"))
+		_ = srv.Close()
+
+		return fmt.Errorf("shutdown: %w", err)
+	}
+
+	return nil
+}
+
+func (c *HookController) serverHandler(shuttingDown *atomic.Bool) http.Handler {
+	hooks := make(map[string]executor.Executor, len(c.registry.Executors()))
+	for _, hook := range c.registry.Executors() {
+		name := hook.Config().GetMetadata().Name
+		if _, ok := hooks[name]; ok {
+			c.logger.Warn("hook name is not unique, the hook is not served", slog.String("name", name))
+			continue
+		}
+
+		hooks[name] = hook
+	}
+
+	// hooks and middlewares expect runs of the module hooks to be sequential
+	var runmu sync.Mutex
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET "+serverHealthPath, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+
+	mux.HandleFunc("GET "+serverReadyPath, func(w http.ResponseWriter, _ *http.Request) {
+		if shuttingDown.Load() {
+			http.Error(w, "shutting down", http.StatusServiceUnavailable)
+			return
+		}
+
+		_, _ = w.Write([]byte("ok"))
+	})
+
+	mux.HandleFunc("POST "+serverRunPath+"{name...}", func(w http.ResponseWriter, r *http.Request) {
+		hook, ok := hooks[r.PathValue("name")]
+		if !ok {
+			http.Error(w, fmt.Sprintf("hook %q not found", r.PathValue("name")), http.StatusNotFound)
+			return
+		}
+
+		runmu.Lock()
+		defer runmu.Unlock()
+
+		c.serveRun(w, r, hook)
+	})
+
+	mux.HandleFunc("POST "+serverReadinessPath, func(w http.ResponseWriter, r *http.Request) {
+		hook := c.registry.Readiness()
+		if hook == nil {
+			http.Error(w, ErrReadinessHookDoesNotExists.Error(), http.StatusNotFound)
+			return
+		}
+
+		runmu.Lock()
+		defer runmu.Unlock()
+
+		c.serveRun(w, r, hook)
+	})
+
+	return mux
+}
+
+// serveRun runs the hook with the request body as input. The result of the run, including
+// the error of a failed run, is the response body.
+func (c *HookController) serveRun(w http.ResponseWriter, r *http.Request, hook executor.Executor) {
+	hookName := hook.Config().GetMetadata().Name
+
+	w.Header().Set("Content-Type", "application/json")
+
+	transport := stream.NewTransport(r.Body, w, c.dc, c.logger.Named("stream-transport"))
+
+	failed, err := c.executeStream(r.Context(), hook, transport)
+	if err != nil {
+		c.logger.Error("send hook output", slog.String("hook", hookName), slog.String("error", err.Error()))
+		return
+	}
+
+	c.logger.Debug("hook run served", slog.String("hook", hookName), slog.Bool("failed", failed))
+}
+
+// listenUnix listens on the socket. A socket left by a killed server is removed,
+// a socket of a running server is not.
+func listenUnix(socket string) (net.Listener, error) {
+	info, err := os.Stat(socket)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("stat socket: %w", err)
+	case info.Mode()&os.ModeSocket == 0:
+		return nil, fmt.Errorf("%q exists and is not a socket", socket)
+	default:
+		if conn, err := net.Dial("unix", socket); err == nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("socket %q is in use", socket)
+		}
+
+		if err := os.Remove(socket); err != nil {
+			return nil, fmt.Errorf("remove stale socket: %w", err)
+		}
+	}
+
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, fmt.Errorf("listen: %w", err)
+	}
+
+	if err := os.Chmod(socket, 0o600); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("chmod socket: %w", err)
+	}
+
+	return listener, nil
+}
@@ -0,0 +1,170 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/deckhouse/deckhouse/pkg/log"
+
+	execregistry "github.com/deckhouse/module-sdk/internal/executor/registry"
+	"github.com/deckhouse/module-sdk/pkg"
+	"github.com/deckhouse/module-sdk/pkg/dependency"
+)
+
+func TestServe(t *testing.T) {
+	dir, err := os.MkdirTemp("", "hook-server")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	socket := filepath.Join(dir, "hooks.sock")
+
+	// running counts concurrent runs of the slow hook, overlapped is set if they overlap
+	var running atomic.Int32
+	var overlapped atomic.Bool
+
+	reg := execregistry.NewRegistry(log.NewNop())
+	reg.RegisterModuleHooks(
+		pkg.Hook[pkg.HookConfig, *pkg.HookInput]{
+			Config: pkg.HookConfig{Metadata: pkg.HookMetadata{Name: "slow"}, OnStartup: &pkg.OrderedConfig{Order: 1}},
+			HookFunc: func(_ context.Context, _ *pkg.HookInput) error {
+				if running.Add(1) > 1 {
+					overlapped.Store(true)
+				}
+				defer running.Add(-1)
+
+				time.Sleep(50 * time.Millisecond)
+
+				return nil
+			},
+		},
+		pkg.Hook[pkg.HookConfig, *pkg.HookInput]{
+			Config: pkg.HookConfig{Metadata: pkg.HookMetadata{Name: "subfolder/set-replicas"}, OnStartup: &pkg.OrderedConfig{Order: 1}},
+			HookFunc: func(_ context.Context, input *pkg.HookInput) error {
+				input.Values.Set("myModule.replicas", input.Values.Get("myModule.replicas").Int()+1)
+				return nil
+			},
+		},
+		pkg.Hook[pkg.HookConfig, *pkg.HookInput]{
+			Config: pkg.HookConfig{Metadata: pkg.HookMetadata{Name: "fail"}, OnStartup: &pkg.OrderedConfig{Order: 1}},
+			HookFunc: func(_ context.Context, _ *pkg.HookInput) error {
+				return pkg.NewPermanentError(errors.New("boom"))
+			},
+		},
+	)
+
+	c := &HookController{
+		registry:     reg,
+		dc:           dependency.NewDependencyContainer(),
+		serverSocket: socket,
+		logger:       log.NewNop(),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	served := make(chan error, 1)
+	go func() {
+		served <- c.Serve(ctx, socket, time.Second)
+	}()
+
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(socket)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	t.Run("health", func(t *testing.T) {
+		for _, path := range []string{serverHealthPath, serverReadyPath} {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://hook-server"+path, nil)
+			require.NoError(t, err)
+
+			resp, err := newServerClient(socket).Do(req)
+			require.NoError(t, err)
+
+			body, _ := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode, path)
+			assert.Equal(t, "ok", string(body), path)
+		}
+	})
+
+	t.Run("run by name", func(t *testing.T) {
+		output, err := c.postRun(ctx, serverRunPath+"subfolder/set-replicas", []byte(`{"values": {"myModule": {"replicas": 2}}}`))
+		require.NoError(t, err)
+
+		assert.Nil(t, output.Error)
+		assert.JSONEq(t, `[{"op":"add","path":"/myModule/replicas","value":3}]`, string(output.ValuesPatches))
+		assert.NotEmpty(t, output.Metrics)
+	})
+
+	t.Run("runs are sequential", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for range 2 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				output, err := c.postRun(ctx, serverRunPath+"slow", []byte(`{}`))
+				assert.NoError(t, err)
+				assert.Nil(t, output.Error)
+			}()
+		}
+		wg.Wait()
+
+		assert.False(t, overlapped.Load(), "hook runs overlap")
+	})
+
+	t.Run("failed run", func(t *testing.T) {
+		output, err := c.postRun(ctx, serverRunPath+"fail", []byte(`{}`))
+		require.NoError(t, err)
+
+		require.NotNil(t, output.Error)
+		assert.Equal(t, "fail", output.Error.Hook)
+		assert.Equal(t, pkg.ErrorCodePermanent, output.Error.Code)
+		assert.Empty(t, output.ValuesPatches)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		output, err := c.postRun(ctx, serverRunPath+"fail", []byte(`{{{`))
+		require.NoError(t, err)
+
+		require.NotNil(t, output.Error)
+		assert.Contains(t, output.Error.Message, "read input: decode input")
+	})
+
+	t.Run("unknown hook", func(t *testing.T) {
+		_, err := c.postRun(ctx, serverRunPath+"unknown", []byte(`{}`))
+		require.EqualError(t, err, `unexpected status 404 Not Found: hook "unknown" not found`)
+		assert.False(t, isDialError(err))
+	})
+
+	t.Run("no readiness hook", func(t *testing.T) {
+		_, err := c.postRun(ctx, serverReadinessPath, []byte(`{}`))
+		require.EqualError(t, err, "unexpected status 404 Not Found: "+ErrReadinessHookDoesNotExists.Error())
+	})
+
+	t.Run("socket in use", func(t *testing.T) {
+		_, err := listenUnix(socket)
+		require.EqualError(t, err, `socket "`+socket+`" is in use`)
+	})
+
+	cancel()
+	require.NoError(t, <-served)
+
+	_, err = os.Stat(socket)
+	assert.ErrorIs(t, err, os.ErrNotExist, "socket is removed on shutdown")
+
+	_, err = c.postRun(context.Background(), serverRunPath+"fail", []byte(`{}`))
+	assert.True(t, isDialError(err), "runs are run locally without the server: %v", err)
+}
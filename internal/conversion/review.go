@@ -0,0 +1,73 @@
+package conversion
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/deckhouse/module-sdk/pkg"
+)
+
+var _ pkg.ConversionReview = (*Review)(nil)
+var _ pkg.Outputer = (*Review)(nil)
+
+const notConvertedMessage = "objects are not converted by the hook"
+
+type Review struct {
+	fromVersion string
+	toVersion   string
+	objects     []*unstructured.Unstructured
+
+	response pkg.ConversionResponse
+}
+
+// NewReview creates a review which fails until the hook responds with converted objects.
+func NewReview(fromVersion, toVersion string, objects []*unstructured.Unstructured) *Review {
+	return &Review{
+		fromVersion: fromVersion,
+		toVersion:   toVersion,
+		objects:     objects,
+		response: pkg.ConversionResponse{
+			FailedMessage: notConvertedMessage,
+		},
+	}
+}
+
+func (r *Review) FromVersion() string {
+	return r.fromVersion
+}
+
+func (r *Review) ToVersion() string {
+	return r.toVersion
+}
+
+func (r *Review) Objects() []*unstructured.Unstructured {
+	return r.objects
+}
+
+func (r *Review) Respond(objects ...*unstructured.Unstructured) {
+	r.response = pkg.ConversionResponse{
+		ConvertedObjects: objects,
+	}
+}
+
+func (r *Review) Fail(message string) {
+	r.response = pkg.ConversionResponse{
+		FailedMessage: message,
+	}
+}
+
+func (r *Review) Response() pkg.ConversionResponse {
+	return r.response
+}
+
+func (r *Review) WriteOutput(w io.Writer) error {
+	err := json.NewEncoder(w).Encode(r.response)
+	if err != nil {
+		return fmt.Errorf("json marshall: %w", err)
+	}
+
+	return nil
+}
@@ -2,9 +2,11 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"time"
 
 	"github.com/deckhouse/deckhouse/pkg/log"
 
@@ -16,15 +18,17 @@ import (
 )
 
 type applicationExecutor struct {
-	hook   pkg.Hook[pkg.ApplicationHookConfig, *pkg.ApplicationHookInput]
-	logger *log.Logger
+	hook    pkg.Hook[pkg.ApplicationHookConfig, *pkg.ApplicationHookInput]
+	options options
+	logger  *log.Logger
 }
 
 // NewApplicationExecutor creates a new application executor
-func NewApplicationExecutor(h pkg.Hook[pkg.ApplicationHookConfig, *pkg.ApplicationHookInput], logger *log.Logger) Executor {
+func NewApplicationExecutor(h pkg.Hook[pkg.ApplicationHookConfig, *pkg.ApplicationHookInput], logger *log.Logger, opts ...Option) Executor {
 	return &applicationExecutor{
-		hook:   h,
-		logger: logger,
+		hook:    h,
+		options: newOptions(opts),
+		logger:  logger,
 	}
 }
 
@@ -93,8 +97,9 @@ func (e *applicationExecutor) Execute(ctx context.Context, req Request) (Result,
 		return nil, fmt.Errorf("get application dependency container: incompatible dependency container type")
 	}
 
-	err = e.hook.HookFunc(ctx, &pkg.ApplicationHookInput{
+	input := &pkg.ApplicationHookInput{
 		Snapshots:        formattedSnapshots,
+		BindingContexts:  convertBindingContexts(bContext),
 		Instance:         inst,
 		Values:           patchableValues,
 		Settings:         patchableSettings,
@@ -102,9 +107,49 @@ func (e *applicationExecutor) Execute(ctx context.Context, req Request) (Result,
 		MetricsCollector: metricsCollector,
 		DC:               dc,
 		Logger:           e.logger,
+	}
+
+	run := &pkg.HookRun{
+		Metadata:         e.hook.Config.Metadata,
+		BindingContexts:  input.BindingContexts,
+		Values:           input.Values,
+		MetricsCollector: input.MetricsCollector,
+		Logger:           input.Logger,
+		Input:            input,
+	}
+
+	ctx, cancel := e.options.withTimeout(ctx, e.hook.Config.Timeout)
+	defer cancel()
+
+	start := time.Now()
+
+	err = runCancellable(ctx, func(ctx context.Context) error {
+		return runHook(ctx, run, e.hook.Middlewares, func(ctx context.Context) error {
+			return e.hook.HookFunc(ctx, input)
+		})
 	})
+
+	if errors.Is(err, errHookAbandoned) {
+		return e.options.abandonedResult(ctx, e.logger, e.hook.Config.Metadata.Name, bContext, formattedSnapshots, time.Since(start))
+	}
+
+	if !e.options.disableExecutionMetrics {
+		collectExecutionMetrics(metricsCollector, executionStats{
+			hook:          e.hook.Config.Metadata.Name,
+			elapsed:       time.Since(start),
+			err:           err,
+			snapshots:     formattedSnapshots,
+			objectPatches: len(namespacedPatchCollector.Operations()),
+			valuesPatches: map[string]int{
+				valuesPatchTypeValues: emittedPatches(patchableValues),
+			},
+		})
+	}
+
+	err = handleCancellation(ctx, e.logger, e.hook.Config.PartialOutput, err)
 	if err != nil {
-		return nil, fmt.Errorf("hook reconcile func: %w", err)
+		return e.options.failedResult(metricsCollector),
+			newHookError(e.hook.Config.Metadata.Name, bContext, fmt.Errorf("hook reconcile func: %w", err))
 	}
 
 	return &result{
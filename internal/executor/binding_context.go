@@ -0,0 +1,146 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/deckhouse/module-sdk/internal/admission"
+	bctx "github.com/deckhouse/module-sdk/internal/binding-context"
+	"github.com/deckhouse/module-sdk/internal/conversion"
+	"github.com/deckhouse/module-sdk/internal/objectpatch"
+	"github.com/deckhouse/module-sdk/pkg"
+)
+
+// convertBindingContexts remaps shell-operator binding contexts to the typed ones passed to the hook.
+func convertBindingContexts(bcs []bctx.BindingContext) []pkg.BindingContext {
+	if len(bcs) == 0 {
+		return nil
+	}
+
+	out := make([]pkg.BindingContext, 0, len(bcs))
+	for _, bc := range bcs {
+		converted := pkg.BindingContext{
+			Binding:      bc.Binding,
+			BindingType:  pkg.BindingType(bc.Metadata.BindingType),
+			Type:         pkg.KubeEventType(bc.Type),
+			Group:        bc.Metadata.Group,
+			WatchEvent:   pkg.WatchEventType(bc.WatchEvent),
+			Object:       rawToSnapshot(bc.Object),
+			FilterResult: rawToSnapshot(bc.FilterResult),
+		}
+
+		// group binding context has the group name as a binding name
+		if converted.Type == pkg.KubeEventTypeGroup && converted.Group == "" {
+			converted.Group = bc.Binding
+		}
+
+		if len(bc.Objects) > 0 {
+			converted.Objects = make([]pkg.ObjectAndFilterResult, 0, len(bc.Objects))
+			for _, obj := range bc.Objects {
+				converted.Objects = append(converted.Objects, pkg.ObjectAndFilterResult{
+					Object:       rawToSnapshot(obj.Object),
+					FilterResult: rawToSnapshot(obj.FilterResult),
+				})
+			}
+		}
+
+		out = append(out, converted)
+	}
+
+	return out
+}
+
+// newValidatingReview returns a review for the first validating binding context with an admission request.
+// It returns nil if the hook is not triggered by a validating binding.
+func newValidatingReview(bcs []bctx.BindingContext) (*admission.ValidatingReview, error) {
+	request, err := admissionRequest(bcs, bctx.TypeValidating)
+	if err != nil || request == nil {
+		return nil, err
+	}
+
+	return admission.NewValidatingReview(request), nil
+}
+
+// newMutatingReview returns a review for the first mutating binding context with an admission request.
+// It returns nil if the hook is not triggered by a mutating binding.
+func newMutatingReview(bcs []bctx.BindingContext) (*admission.MutatingReview, error) {
+	request, err := admissionRequest(bcs, bctx.TypeMutating)
+	if err != nil || request == nil {
+		return nil, err
+	}
+
+	return admission.NewMutatingReview(request), nil
+}
+
+func admissionRequest(bcs []bctx.BindingContext, contextType bctx.KubeEventType) (*admissionv1.AdmissionRequest, error) {
+	for _, bc := range bcs {
+		if bc.Type != contextType || len(bc.Review) == 0 {
+			continue
+		}
+
+		review := new(admissionv1.AdmissionReview)
+		if err := json.Unmarshal(bc.Review, review); err != nil {
+			return nil, fmt.Errorf("unmarshal admission review: %w", err)
+		}
+
+		if review.Request == nil {
+			return nil, fmt.Errorf("admission review for binding '%s' has no request", bc.Binding)
+		}
+
+		return review.Request, nil
+	}
+
+	return nil, nil
+}
+
+// newConversionReview returns a review for the first conversion binding context with a conversion request.
+// It returns nil if the hook is not triggered by a conversion binding.
+func newConversionReview(bcs []bctx.BindingContext) (*conversion.Review, error) {
+	for _, bc := range bcs {
+		if bc.Type != bctx.TypeConversion || len(bc.Review) == 0 {
+			continue
+		}
+
+		review := new(apiextensionsv1.ConversionReview)
+		if err := json.Unmarshal(bc.Review, review); err != nil {
+			return nil, fmt.Errorf("unmarshal conversion review: %w", err)
+		}
+
+		if review.Request == nil {
+			return nil, fmt.Errorf("conversion review for binding '%s' has no request", bc.Binding)
+		}
+
+		objects := make([]*unstructured.Unstructured, 0, len(review.Request.Objects))
+		for _, raw := range review.Request.Objects {
+			obj := new(unstructured.Unstructured)
+			if err := obj.UnmarshalJSON(raw.Raw); err != nil {
+				return nil, fmt.Errorf("unmarshal object to convert: %w", err)
+			}
+
+			objects = append(objects, obj)
+		}
+
+		toVersion := review.Request.DesiredAPIVersion
+		if toVersion == "" {
+			toVersion = bc.ToVersion
+		}
+
+		return conversion.NewReview(bc.FromVersion, toVersion, objects), nil
+	}
+
+	return nil, nil
+}
+
+// rawToSnapshot returns nil interface for empty message
+// to let hooks check snapshot presence with a simple nil comparison.
+func rawToSnapshot(raw json.RawMessage) pkg.Snapshot {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	return objectpatch.Snapshot(raw)
+}
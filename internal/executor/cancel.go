@@ -0,0 +1,123 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/deckhouse/deckhouse/pkg/log"
+
+	bctx "github.com/deckhouse/module-sdk/internal/binding-context"
+	"github.com/deckhouse/module-sdk/internal/metric"
+	"github.com/deckhouse/module-sdk/internal/objectpatch"
+	"github.com/deckhouse/module-sdk/pkg"
+)
+
+// cancellationGracePeriod is the time given to the hook to return after its context is cancelled.
+const cancellationGracePeriod = 2 * time.Second
+
+// errHookAbandoned is returned by runCancellable if the hook did not return after its context is cancelled.
+var errHookAbandoned = fmt.Errorf("hook did not return in %s after cancellation", cancellationGracePeriod)
+
+// withTimeout returns the context of the hook run, cancelled after the hook timeout or the default one.
+func (o options) withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout == 0 {
+		timeout = o.defaultTimeout
+	}
+
+	if timeout == 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeoutCause(ctx, timeout, fmt.Errorf("hook timeout %s exceeded", timeout))
+}
+
+// runCancellable runs f until it returns or ctx is done. A hook ignoring the context
+// is abandoned after cancellationGracePeriod with errHookAbandoned, so it can not block the module queue.
+// The abandoned hook can not be stopped and keeps running until it returns, so its input
+// (values and collectors) must not be read after that, see abandonedResult.
+func runCancellable(ctx context.Context, f func(ctx context.Context) error) error {
+	done := make(chan error, 1)
+
+	go func() {
+		done <- f(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(cancellationGracePeriod):
+		return errHookAbandoned
+	}
+}
+
+// failedResult returns the result of a failed run, execution metrics of the run are sent as well.
+func (o options) failedResult(metricsCollector *metric.Collector) Result {
+	if o.disableExecutionMetrics {
+		return nil
+	}
+
+	return &result{metricsCollector: metricsCollector}
+}
+
+// abandonedResult returns the result of the run abandoned by runCancellable. The hook still writes
+// into its values and collectors, so the result contains only execution metrics collected into a new collector,
+// and the partial output is never flushed.
+func (o options) abandonedResult(
+	ctx context.Context,
+	logger *log.Logger,
+	hook string,
+	bContext []bctx.BindingContext,
+	snapshots objectpatch.Snapshots,
+	elapsed time.Duration,
+) (Result, error) {
+	err := errHookAbandoned
+	if cause := context.Cause(ctx); cause != nil {
+		err = fmt.Errorf("%w: %w", cause, err)
+	}
+
+	logger.Error("hook abandoned, it keeps running in the background", slog.String("error", err.Error()))
+
+	hookErr := newHookError(hook, bContext, fmt.Errorf("hook reconcile func: %w", pkg.NewRetryableError(err)))
+
+	if o.disableExecutionMetrics {
+		return nil, hookErr
+	}
+
+	metricsCollector := metric.NewCollector()
+	collectExecutionMetrics(metricsCollector, executionStats{
+		hook:      hook,
+		elapsed:   elapsed,
+		err:       hookErr,
+		snapshots: snapshots,
+	})
+
+	return &result{metricsCollector: metricsCollector}, hookErr
+}
+
+// handleCancellation applies the partial output policy to the result of a run failed after its context is cancelled.
+// It returns nil if the output must be flushed as the output of a successful run.
+func handleCancellation(ctx context.Context, logger *log.Logger, policy pkg.PartialOutputPolicy, err error) error {
+	cause := context.Cause(ctx)
+	if err == nil || cause == nil || errors.Is(err, errHookAbandoned) {
+		return err
+	}
+
+	if policy == pkg.PartialOutputFlush {
+		logger.Warn("hook run cancelled, flushing partial output",
+			slog.String("cause", cause.Error()),
+			slog.String("error", err.Error()))
+
+		return nil
+	}
+
+	return pkg.NewRetryableError(fmt.Errorf("%w: %w", cause, err))
+}
@@ -0,0 +1,50 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+
+	"github.com/deckhouse/deckhouse/pkg/log"
+
+	"github.com/deckhouse/module-sdk/internal/objectpatch"
+	"github.com/deckhouse/module-sdk/internal/redact"
+	"github.com/deckhouse/module-sdk/pkg"
+	patchablevalues "github.com/deckhouse/module-sdk/pkg/patchable-values"
+)
+
+// logDebugDump logs snapshots passed to the hook and collected values patches on the debug level.
+// Sensitive values are redacted, see pkg.SensitiveConfig and pkg.Sensitive.
+func logDebugDump(
+	ctx context.Context,
+	logger *log.Logger,
+	sensitive *pkg.SensitiveConfig,
+	snapshots objectpatch.Snapshots,
+	values, configValues *patchablevalues.PatchableValues,
+) {
+	if !logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	if sensitive == nil {
+		sensitive = &pkg.SensitiveConfig{}
+	}
+
+	redactedSnapshots := make(map[string][]json.RawMessage, len(snapshots))
+	for binding, snaps := range snapshots {
+		redactor := redact.New(redact.ParsePaths(sensitive.Snapshots[binding])...)
+
+		for _, snap := range snaps {
+			redactedSnapshots[binding] = append(redactedSnapshots[binding], redactor.Raw([]byte(snap.String())))
+		}
+	}
+
+	valuesPaths := redact.ParsePaths(sensitive.Values)
+
+	logger.Debug("hook run dump",
+		slog.Any("snapshots", redactedSnapshots),
+		slog.Any("values_patches", redact.New(slices.Concat(valuesPaths, values.SensitivePaths())...).Patches(values.GetPatches())),
+		slog.Any("config_values_patches", redact.New(slices.Concat(valuesPaths, configValues.SensitivePaths())...).Patches(configValues.GetPatches())),
+	)
+}
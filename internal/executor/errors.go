@@ -0,0 +1,68 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"slices"
+
+	bctx "github.com/deckhouse/module-sdk/internal/binding-context"
+	"github.com/deckhouse/module-sdk/pkg"
+)
+
+// HookError is returned by Execute if the hook run fails.
+// It describes the run for the error reported to addon-operator.
+type HookError struct {
+	Hook     string
+	Bindings []string
+	Err      error
+}
+
+func newHookError(hook string, bContext []bctx.BindingContext, err error) *HookError {
+	bindings := make([]string, 0, len(bContext))
+	for _, bc := range bContext {
+		if bc.Binding != "" && !slices.Contains(bindings, bc.Binding) {
+			bindings = append(bindings, bc.Binding)
+		}
+	}
+
+	return &HookError{
+		Hook:     hook,
+		Bindings: bindings,
+		Err:      err,
+	}
+}
+
+func (e *HookError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// runHook runs the hook func wrapped by middlewares. Panics in the hook func and in middlewares
+// are recovered outside of the middlewares and converted into errors wrapping *pkg.PanicError.
+// Middlewares see a panic as an error only inside middleware.Recovery.
+func runHook(ctx context.Context, run *pkg.HookRun, middlewares []pkg.Middleware, f func(ctx context.Context) error) error {
+	return recoverPanic(run, func() error {
+		return pkg.RunMiddlewares(ctx, run, middlewares, f)
+	})
+}
+
+func recoverPanic(run *pkg.HookRun, f func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			stack := string(debug.Stack())
+
+			run.Logger.Error("hook panic recovered",
+				slog.Any("panic", r),
+				slog.String("stacktrace", stack))
+
+			err = fmt.Errorf("hook panicked: %w", &pkg.PanicError{Value: r, Stack: stack})
+		}
+	}()
+
+	return f()
+}
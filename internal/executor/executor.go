@@ -39,12 +39,21 @@ type Result interface {
 	ObjectPatchCollector() pkg.Outputer
 	// ValuesPatchCollector returns collected values patches by type.
 	ValuesPatchCollector(key utils.ValuesPatchType) pkg.Outputer
+	// ValidatingResponse returns the validating webhook response, nil if hook is not triggered by a validating binding.
+	ValidatingResponse() pkg.Outputer
+	// MutatingResponse returns the mutating webhook response, nil if hook is not triggered by a mutating binding.
+	MutatingResponse() pkg.Outputer
+	// ConversionResponse returns the conversion webhook response, nil if hook is not triggered by a conversion binding.
+	ConversionResponse() pkg.Outputer
 }
 
 type result struct {
 	objectPatchCollector pkg.Outputer
 	metricsCollector     pkg.Outputer
 	patches              map[utils.ValuesPatchType]pkg.Outputer
+	validatingResponse   pkg.Outputer
+	mutatingResponse     pkg.Outputer
+	conversionResponse   pkg.Outputer
 }
 
 func (r *result) MetricsCollector() pkg.Outputer {
@@ -58,3 +67,15 @@ func (r *result) ObjectPatchCollector() pkg.Outputer {
 func (r *result) ValuesPatchCollector(key utils.ValuesPatchType) pkg.Outputer {
 	return r.patches[key]
 }
+
+func (r *result) ValidatingResponse() pkg.Outputer {
+	return r.validatingResponse
+}
+
+func (r *result) MutatingResponse() pkg.Outputer {
+	return r.mutatingResponse
+}
+
+func (r *result) ConversionResponse() pkg.Outputer {
+	return r.conversionResponse
+}
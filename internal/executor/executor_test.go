@@ -1,17 +1,26 @@
 package executor_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"log/slog"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/deckhouse/deckhouse/pkg/log"
 
 	bindingcontext "github.com/deckhouse/module-sdk/internal/binding-context"
 	"github.com/deckhouse/module-sdk/internal/executor"
 	"github.com/deckhouse/module-sdk/pkg"
+	"github.com/deckhouse/module-sdk/pkg/metric/operation"
+	"github.com/deckhouse/module-sdk/pkg/middleware"
+	"github.com/deckhouse/module-sdk/pkg/utils"
 )
 
 func Test_Go_Hook_Execute(t *testing.T) {
@@ -25,13 +34,17 @@ func Test_Go_Hook_Execute(t *testing.T) {
 	type fields struct {
 		setupHookRequest       func(t *testing.T) executor.Request
 		setupHookReconcileFunc func(t *testing.T) func(ctx context.Context, input *pkg.HookInput) error
+		middlewares            []pkg.Middleware
 	}
 
 	type args struct {
 	}
 
 	type wants struct {
-		err string
+		err                string
+		validatingResponse string
+		mutatingResponse   string
+		conversionResponse string
 	}
 
 	tests := []struct {
@@ -194,6 +207,386 @@ func Test_Go_Hook_Execute(t *testing.T) {
 			args:  args{},
 			wants: wants{},
 		},
+		{
+			meta: meta{
+				name:    "binding contexts are passed to hook input",
+				enabled: true,
+			},
+			fields: fields{
+				setupHookRequest: func(t *testing.T) executor.Request {
+					hr := NewHookRequestMock(t)
+
+					vals := hr.GetValuesMock.Expect()
+					vals.Return(map[string]any{}, nil)
+
+					cvals := hr.GetConfigValuesMock.Expect()
+					cvals.Return(map[string]any{}, nil)
+
+					bcs := []bindingcontext.BindingContext{
+						{
+							Metadata: bindingcontext.Metadata{
+								BindingType: bindingcontext.OnKubernetesEvent,
+								Group:       "main",
+							},
+							Binding: "pods",
+							Type:    bindingcontext.TypeSynchronization,
+							Objects: bindingcontext.ObjectAndFilterResults{
+								{Object: []byte(`{"name":"pod-1"}`)},
+								{Object: []byte(`{"name":"pod-2"}`), FilterResult: []byte(`"pod-2"`)},
+							},
+						},
+						{
+							Metadata: bindingcontext.Metadata{
+								BindingType: bindingcontext.OnKubernetesEvent,
+							},
+							Binding:      "pods",
+							Type:         bindingcontext.TypeEvent,
+							WatchEvent:   bindingcontext.WatchEventDeleted,
+							Object:       []byte(`{"name":"pod-1"}`),
+							FilterResult: []byte(`"pod-1"`),
+						},
+						{
+							Metadata: bindingcontext.Metadata{
+								BindingType: bindingcontext.Schedule,
+							},
+							Binding: "every-minute",
+						},
+					}
+					bctxs := hr.GetBindingContextsMock.Expect()
+					bctxs.Return(bcs, nil)
+
+					dc := hr.GetDependencyContainerMock.Expect()
+					dc.Return(nil)
+
+					return hr
+				},
+				setupHookReconcileFunc: func(t *testing.T) func(ctx context.Context, input *pkg.HookInput) error {
+					return func(_ context.Context, input *pkg.HookInput) error {
+						assert.Len(t, input.BindingContexts, 3)
+
+						sync := input.BindingContexts[0]
+						assert.True(t, sync.IsSynchronization())
+						assert.Equal(t, "pods", sync.Binding)
+						assert.Equal(t, pkg.BindingTypeKubernetes, sync.BindingType)
+						assert.Equal(t, "main", sync.Group)
+						assert.Nil(t, sync.Object)
+						assert.Len(t, sync.Objects, 2)
+						assert.Nil(t, sync.Objects[0].FilterResult)
+						assert.Equal(t, `{"name":"pod-1"}`, sync.Objects[0].Snapshot().String())
+						assert.Equal(t, `"pod-2"`, sync.Objects[1].Snapshot().String())
+
+						event := input.BindingContexts[1]
+						assert.True(t, event.IsEvent())
+						assert.Equal(t, pkg.WatchEventDeleted, event.WatchEvent)
+						assert.Equal(t, `{"name":"pod-1"}`, event.Object.String())
+						assert.Equal(t, `"pod-1"`, event.Snapshot().String())
+
+						schedule := input.BindingContexts[2]
+						assert.True(t, schedule.IsSchedule())
+						assert.Equal(t, "every-minute", schedule.Binding)
+						assert.Nil(t, schedule.Snapshot())
+
+						return nil
+					}
+				},
+			},
+			args:  args{},
+			wants: wants{},
+		},
+		{
+			meta: meta{
+				name:    "group binding context reports triggered group",
+				enabled: true,
+			},
+			fields: fields{
+				setupHookRequest: func(t *testing.T) executor.Request {
+					hr := NewHookRequestMock(t)
+
+					vals := hr.GetValuesMock.Expect()
+					vals.Return(map[string]any{}, nil)
+
+					cvals := hr.GetConfigValuesMock.Expect()
+					cvals.Return(map[string]any{}, nil)
+
+					bcs := []bindingcontext.BindingContext{
+						{
+							Binding: "policies",
+							Type:    bindingcontext.TypeGroup,
+							Snapshots: map[string]bindingcontext.ObjectAndFilterResults{
+								"pods":  {{FilterResult: []byte(`"pod-1"`)}},
+								"nodes": {{FilterResult: []byte(`"node-1"`)}},
+							},
+						},
+					}
+					bctxs := hr.GetBindingContextsMock.Expect()
+					bctxs.Return(bcs, nil)
+
+					dc := hr.GetDependencyContainerMock.Expect()
+					dc.Return(nil)
+
+					return hr
+				},
+				setupHookReconcileFunc: func(t *testing.T) func(ctx context.Context, input *pkg.HookInput) error {
+					return func(_ context.Context, input *pkg.HookInput) error {
+						assert.Equal(t, "policies", input.TriggeredGroup())
+						assert.Len(t, input.BindingContexts, 1)
+						assert.True(t, input.BindingContexts[0].IsGroup())
+						assert.Len(t, input.Snapshots.Get("pods"), 1)
+						assert.Len(t, input.Snapshots.Get("nodes"), 1)
+
+						return nil
+					}
+				},
+			},
+			args:  args{},
+			wants: wants{},
+		},
+		{
+			meta: meta{
+				name:    "middlewares wrap the hook",
+				enabled: true,
+			},
+			fields: fields{
+				setupHookRequest: func(t *testing.T) executor.Request {
+					hr := NewHookRequestMock(t)
+
+					vals := hr.GetValuesMock.Expect()
+					vals.Return(map[string]any{}, nil)
+
+					cvals := hr.GetConfigValuesMock.Expect()
+					cvals.Return(map[string]any{}, nil)
+
+					bctxs := hr.GetBindingContextsMock.Expect()
+					bctxs.Return(nil, nil)
+
+					dc := hr.GetDependencyContainerMock.Expect()
+					dc.Return(nil)
+
+					return hr
+				},
+				setupHookReconcileFunc: func(_ *testing.T) func(ctx context.Context, input *pkg.HookInput) error {
+					return func(_ context.Context, _ *pkg.HookInput) error {
+						return errors.New("stub error")
+					}
+				},
+				middlewares: []pkg.Middleware{
+					func(ctx context.Context, run *pkg.HookRun, next func(ctx context.Context) error) error {
+						if err := next(ctx); err != nil {
+							return fmt.Errorf("%s: %w", run.Metadata.Name, err)
+						}
+
+						return nil
+					},
+				},
+			},
+			args: args{},
+			wants: wants{
+				err: "hook reconcile func: 002-hook/main: stub error",
+			},
+		},
+		{
+			meta: meta{
+				name:    "validating review is passed to hook input",
+				enabled: true,
+			},
+			fields: fields{
+				setupHookRequest: func(t *testing.T) executor.Request {
+					hr := NewHookRequestMock(t)
+
+					vals := hr.GetValuesMock.Expect()
+					vals.Return(map[string]any{}, nil)
+
+					cvals := hr.GetConfigValuesMock.Expect()
+					cvals.Return(map[string]any{}, nil)
+
+					bcs := []bindingcontext.BindingContext{
+						{
+							Binding: "policy.example.deckhouse.io",
+							Type:    bindingcontext.TypeValidating,
+							Review:  []byte(`{"request":{"uid":"1","name":"pod-1","operation":"DELETE"}}`),
+						},
+					}
+					bctxs := hr.GetBindingContextsMock.Expect()
+					bctxs.Return(bcs, nil)
+
+					dc := hr.GetDependencyContainerMock.Expect()
+					dc.Return(nil)
+
+					return hr
+				},
+				setupHookReconcileFunc: func(t *testing.T) func(ctx context.Context, input *pkg.HookInput) error {
+					return func(_ context.Context, input *pkg.HookInput) error {
+						if !assert.NotNil(t, input.Review) {
+							return nil
+						}
+
+						assert.True(t, input.BindingContexts[0].IsValidating())
+						assert.Equal(t, "pod-1", input.Review.Request().Name)
+
+						input.Review.Deny("pod-1 can not be deleted", "pod-1 is protected")
+
+						return nil
+					}
+				},
+			},
+			args: args{},
+			wants: wants{
+				validatingResponse: `{"allowed":false,"message":"pod-1 can not be deleted","warnings":["pod-1 is protected"]}`,
+			},
+		},
+		{
+			meta: meta{
+				name:    "mutating review is passed to hook input",
+				enabled: true,
+			},
+			fields: fields{
+				setupHookRequest: func(t *testing.T) executor.Request {
+					hr := NewHookRequestMock(t)
+
+					vals := hr.GetValuesMock.Expect()
+					vals.Return(map[string]any{}, nil)
+
+					cvals := hr.GetConfigValuesMock.Expect()
+					cvals.Return(map[string]any{}, nil)
+
+					bcs := []bindingcontext.BindingContext{
+						{
+							Binding: "defaults.example.deckhouse.io",
+							Type:    bindingcontext.TypeMutating,
+							Review:  []byte(`{"request":{"uid":"1","name":"pod-1","operation":"CREATE","object":{"metadata":{"name":"pod-1"}}}}`),
+						},
+					}
+					bctxs := hr.GetBindingContextsMock.Expect()
+					bctxs.Return(bcs, nil)
+
+					dc := hr.GetDependencyContainerMock.Expect()
+					dc.Return(nil)
+
+					return hr
+				},
+				setupHookReconcileFunc: func(t *testing.T) func(ctx context.Context, input *pkg.HookInput) error {
+					return func(_ context.Context, input *pkg.HookInput) error {
+						if !assert.NotNil(t, input.Mutation) {
+							return nil
+						}
+
+						assert.Nil(t, input.Review)
+						assert.True(t, input.BindingContexts[0].IsMutating())
+
+						return input.Mutation.Mutate(map[string]any{
+							"metadata": map[string]any{"name": "pod-1", "labels": map[string]any{"app": "web"}},
+						})
+					}
+				},
+			},
+			args: args{},
+			wants: wants{
+				// patch is base64 encoded [{"op":"add","path":"/metadata/labels","value":{"app":"web"}}]
+				mutatingResponse: `{"allowed":true,"patch":"W3sib3AiOiJhZGQiLCJwYXRoIjoiL21ldGFkYXRhL2xhYmVscyIsInZhbHVlIjp7ImFwcCI6IndlYiJ9fV0="}`,
+			},
+		},
+		{
+			meta: meta{
+				name:    "conversion review is passed to hook input",
+				enabled: true,
+			},
+			fields: fields{
+				setupHookRequest: func(t *testing.T) executor.Request {
+					hr := NewHookRequestMock(t)
+
+					vals := hr.GetValuesMock.Expect()
+					vals.Return(map[string]any{}, nil)
+
+					cvals := hr.GetConfigValuesMock.Expect()
+					cvals.Return(map[string]any{}, nil)
+
+					bcs := []bindingcontext.BindingContext{
+						{
+							Binding:     "widgets",
+							Type:        bindingcontext.TypeConversion,
+							FromVersion: "example.io/v1alpha1",
+							ToVersion:   "example.io/v1",
+							Review: []byte(`{"request":{"uid":"1","desiredAPIVersion":"example.io/v1","objects":[
+								{"apiVersion":"example.io/v1alpha1","kind":"Widget","metadata":{"name":"w-1"}}
+							]}}`),
+						},
+					}
+					bctxs := hr.GetBindingContextsMock.Expect()
+					bctxs.Return(bcs, nil)
+
+					dc := hr.GetDependencyContainerMock.Expect()
+					dc.Return(nil)
+
+					return hr
+				},
+				setupHookReconcileFunc: func(t *testing.T) func(ctx context.Context, input *pkg.HookInput) error {
+					return func(_ context.Context, input *pkg.HookInput) error {
+						if !assert.NotNil(t, input.Conversion) {
+							return nil
+						}
+
+						assert.Nil(t, input.Review)
+						assert.Equal(t, "example.io/v1alpha1", input.Conversion.FromVersion())
+						assert.Equal(t, "example.io/v1", input.Conversion.ToVersion())
+
+						objects := input.Conversion.Objects()
+						if !assert.Len(t, objects, 1) {
+							return nil
+						}
+
+						objects[0].SetAPIVersion(input.Conversion.ToVersion())
+						input.Conversion.Respond(objects...)
+
+						return nil
+					}
+				},
+			},
+			args: args{},
+			wants: wants{
+				conversionResponse: `{"convertedObjects":[{"apiVersion":"example.io/v1","kind":"Widget","metadata":{"name":"w-1"}}]}`,
+			},
+		},
+		{
+			meta: meta{
+				name:    "bad conversion review",
+				enabled: true,
+			},
+			fields: fields{
+				setupHookRequest: func(t *testing.T) executor.Request {
+					hr := NewHookRequestMock(t)
+
+					vals := hr.GetValuesMock.Expect()
+					vals.Return(map[string]any{}, nil)
+
+					cvals := hr.GetConfigValuesMock.Expect()
+					cvals.Return(map[string]any{}, nil)
+
+					bcs := []bindingcontext.BindingContext{
+						{
+							Binding: "widgets",
+							Type:    bindingcontext.TypeConversion,
+							Review:  []byte(`{}`),
+						},
+					}
+					bctxs := hr.GetBindingContextsMock.Expect()
+					bctxs.Return(bcs, nil)
+
+					dc := hr.GetDependencyContainerMock.Expect()
+					dc.Return(nil)
+
+					return hr
+				},
+				setupHookReconcileFunc: func(_ *testing.T) func(ctx context.Context, input *pkg.HookInput) error {
+					return func(_ context.Context, _ *pkg.HookInput) error {
+						return nil
+					}
+				},
+			},
+			args: args{},
+			wants: wants{
+				err: "get conversion review: conversion review for binding 'widgets' has no request",
+			},
+		},
 		{
 			meta: meta{
 				name:    "get values error",
@@ -383,18 +776,615 @@ func Test_Go_Hook_Execute(t *testing.T) {
 			t.Parallel()
 
 			h := pkg.Hook[pkg.HookConfig, *pkg.HookInput]{
-				Config:   pkg.HookConfig{},
-				HookFunc: tt.fields.setupHookReconcileFunc(t),
+				Config:      pkg.HookConfig{Metadata: pkg.HookMetadata{Name: "002-hook/main"}},
+				HookFunc:    tt.fields.setupHookReconcileFunc(t),
+				Middlewares: tt.fields.middlewares,
 			}
 
 			exec := executor.NewModuleExecutor(h, log.NewNop())
 
-			_, err := exec.Execute(context.Background(), tt.fields.setupHookRequest(t))
+			res, err := exec.Execute(context.Background(), tt.fields.setupHookRequest(t))
 			if tt.wants.err != "" {
 				assert.Contains(t, err.Error(), tt.wants.err)
-			} else {
-				assert.NoError(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+
+			assertOutput(t, tt.wants.validatingResponse, res.ValidatingResponse())
+			assertOutput(t, tt.wants.mutatingResponse, res.MutatingResponse())
+			assertOutput(t, tt.wants.conversionResponse, res.ConversionResponse())
+		})
+	}
+}
+
+func assertOutput(t *testing.T, expected string, outputer pkg.Outputer) {
+	t.Helper()
+
+	if expected == "" {
+		assert.Nil(t, outputer)
+		return
+	}
+
+	if !assert.NotNil(t, outputer) {
+		return
+	}
+
+	buf := bytes.NewBuffer(nil)
+	assert.NoError(t, outputer.WriteOutput(buf))
+	assert.JSONEq(t, expected, buf.String())
+}
+
+func Test_Go_Hook_Execute_Panic(t *testing.T) {
+	t.Parallel()
+
+	hr := NewHookRequestMock(t)
+	hr.GetValuesMock.Expect().Return(map[string]any{}, nil)
+	hr.GetConfigValuesMock.Expect().Return(map[string]any{}, nil)
+	hr.GetBindingContextsMock.Expect().Return([]bindingcontext.BindingContext{
+		{Binding: "pods"},
+		{Binding: "nodes"},
+		{Binding: "pods"},
+	}, nil)
+	hr.GetDependencyContainerMock.Expect().Return(nil)
+
+	var middlewareErr error
+
+	h := pkg.Hook[pkg.HookConfig, *pkg.HookInput]{
+		Config: pkg.HookConfig{Metadata: pkg.HookMetadata{Name: "002-hook/main"}},
+		HookFunc: func(_ context.Context, _ *pkg.HookInput) error {
+			panic("boom")
+		},
+		Middlewares: []pkg.Middleware{
+			func(ctx context.Context, _ *pkg.HookRun, next func(ctx context.Context) error) error {
+				middlewareErr = next(ctx)
+				return middlewareErr
+			},
+			middleware.Recovery(),
+		},
+	}
+
+	_, err := executor.NewModuleExecutor(h, log.NewNop()).Execute(context.Background(), hr)
+	assert.EqualError(t, err, `hook reconcile func: hook "002-hook/main" panicked: boom`)
+	assert.Error(t, middlewareErr, "middlewares outside of Recovery must see the panic as an error")
+
+	var hookErr *executor.HookError
+	if assert.ErrorAs(t, err, &hookErr) {
+		assert.Equal(t, "002-hook/main", hookErr.Hook)
+		assert.Equal(t, []string{"pods", "nodes"}, hookErr.Bindings)
+	}
+
+	var panicErr *pkg.PanicError
+	if assert.ErrorAs(t, err, &panicErr) {
+		assert.Equal(t, "boom", panicErr.Value)
+		assert.Contains(t, panicErr.Stack, "executor_test.go")
+	}
+}
+
+func Test_Go_Hook_Execute_PanicWithoutRecovery(t *testing.T) {
+	t.Parallel()
+
+	hr := NewHookRequestMock(t)
+	hr.GetValuesMock.Expect().Return(map[string]any{}, nil)
+	hr.GetConfigValuesMock.Expect().Return(map[string]any{}, nil)
+	hr.GetBindingContextsMock.Expect().Return(nil, nil)
+	hr.GetDependencyContainerMock.Expect().Return(nil)
+
+	returned := false
+
+	h := pkg.Hook[pkg.HookConfig, *pkg.HookInput]{
+		Config: pkg.HookConfig{Metadata: pkg.HookMetadata{Name: "002-hook/main"}},
+		HookFunc: func(_ context.Context, _ *pkg.HookInput) error {
+			panic("boom")
+		},
+		Middlewares: []pkg.Middleware{
+			func(ctx context.Context, _ *pkg.HookRun, next func(ctx context.Context) error) error {
+				err := next(ctx)
+				returned = true
+
+				return err
+			},
+		},
+	}
+
+	// the panic is recovered outside of middlewares
+	_, err := executor.NewModuleExecutor(h, log.NewNop()).Execute(context.Background(), hr)
+	assert.EqualError(t, err, "hook reconcile func: hook panicked: boom")
+	assert.False(t, returned, "the panic passes through middlewares without middleware.Recovery")
+	assert.Equal(t, pkg.ErrorCodePanic, pkg.ErrorCode(err))
+}
+
+func Test_Go_Hook_Execute_Metrics(t *testing.T) {
+	t.Parallel()
+
+	newRequest := func(t *testing.T) executor.Request {
+		hr := NewHookRequestMock(t)
+		hr.GetValuesMock.Expect().Return(map[string]any{}, nil)
+		hr.GetConfigValuesMock.Expect().Return(map[string]any{}, nil)
+		hr.GetBindingContextsMock.Expect().Return([]bindingcontext.BindingContext{
+			{
+				Binding: "pods",
+				Snapshots: map[string]bindingcontext.ObjectAndFilterResults{
+					"pods": {{FilterResult: []byte(`"a"`)}, {FilterResult: []byte(`"b"`)}},
+				},
+			},
+		}, nil)
+		hr.GetDependencyContainerMock.Expect().Return(nil)
+
+		return hr
+	}
+
+	readMetrics := func(t *testing.T, outputer pkg.Outputer) []operation.Operation {
+		t.Helper()
+
+		buf := bytes.NewBuffer(nil)
+		require.NoError(t, outputer.WriteOutput(buf))
+
+		ops, err := operation.MetricOperationsFromBytes(buf.Bytes())
+		require.NoError(t, err)
+		require.NoError(t, operation.ValidateOperations(ops))
+
+		return ops
+	}
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		h := pkg.Hook[pkg.HookConfig, *pkg.HookInput]{
+			Config: pkg.HookConfig{Metadata: pkg.HookMetadata{Name: "002-hook/main"}},
+			HookFunc: func(_ context.Context, input *pkg.HookInput) error {
+				input.Values.Set("replicas", 2)
+				input.PatchCollector.Delete("v1", "Pod", "default", "app")
+				return nil
+			},
+		}
+
+		res, err := executor.NewModuleExecutor(h, log.NewNop()).Execute(context.Background(), newRequest(t))
+		require.NoError(t, err)
+
+		ops := readMetrics(t, res.MetricsCollector())
+		require.Len(t, ops, 7)
+
+		assert.Equal(t, executor.ExecutionDurationMetric, ops[0].Name)
+		assert.Equal(t, "observe", ops[0].Action)
+		assert.Equal(t, map[string]string{"hook": "002-hook/main", "status": "success"}, ops[0].Labels)
+
+		assert.Equal(t, executor.ExecutionsMetric, ops[1].Name)
+		assert.Equal(t, map[string]string{"hook": "002-hook/main", "status": "success"}, ops[1].Labels)
+
+		assert.Equal(t, "expire", ops[2].Action)
+		assert.Equal(t, executor.SnapshotObjectsMetric, ops[3].Name)
+		assert.Equal(t, ops[2].Group, ops[3].Group)
+		assert.Equal(t, map[string]string{"hook": "002-hook/main", "binding": "pods"}, ops[3].Labels)
+		assert.Equal(t, 2.0, *ops[3].Value)
+
+		assert.Equal(t, executor.ObjectPatchesMetric, ops[4].Name)
+		assert.Equal(t, 1.0, *ops[4].Value)
+
+		assert.Equal(t, executor.ValuesPatchesMetric, ops[5].Name)
+		assert.Equal(t, map[string]string{"hook": "002-hook/main", "type": "config_values"}, ops[5].Labels)
+		assert.Equal(t, 0.0, *ops[5].Value)
+		assert.Equal(t, map[string]string{"hook": "002-hook/main", "type": "values"}, ops[6].Labels)
+		assert.Equal(t, 1.0, *ops[6].Value)
+	})
+
+	t.Run("compacted values patches", func(t *testing.T) {
+		t.Parallel()
+
+		h := pkg.Hook[pkg.HookConfig, *pkg.HookInput]{
+			Config: pkg.HookConfig{Metadata: pkg.HookMetadata{Name: "002-hook/main"}},
+			HookFunc: func(_ context.Context, input *pkg.HookInput) error {
+				for i := range 3 {
+					input.Values.Set("replicas", i)
+				}
+				input.Values.Remove("replicas")
+				return nil
+			},
+		}
+
+		res, err := executor.NewModuleExecutor(h, log.NewNop()).Execute(context.Background(), newRequest(t))
+		require.NoError(t, err)
+
+		// no values patches are emitted
+		buf := bytes.NewBuffer(nil)
+		require.NoError(t, res.ValuesPatchCollector(utils.MemoryValuesPatch).WriteOutput(buf))
+		assert.Empty(t, buf.String())
+
+		ops := readMetrics(t, res.MetricsCollector())
+		require.Len(t, ops, 7)
+		assert.Equal(t, map[string]string{"hook": "002-hook/main", "type": "values"}, ops[6].Labels)
+		assert.Equal(t, 0.0, *ops[6].Value)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		t.Parallel()
+
+		h := pkg.Hook[pkg.HookConfig, *pkg.HookInput]{
+			Config: pkg.HookConfig{Metadata: pkg.HookMetadata{Name: "002-hook/main"}},
+			HookFunc: func(_ context.Context, input *pkg.HookInput) error {
+				input.Values.Set("replicas", 2)
+				return errors.New("error")
+			},
+		}
+
+		res, err := executor.NewModuleExecutor(h, log.NewNop()).Execute(context.Background(), newRequest(t))
+		require.Error(t, err)
+		require.NotNil(t, res)
+		assert.Nil(t, res.ValuesPatchCollector(utils.MemoryValuesPatch))
+		assert.Nil(t, res.ObjectPatchCollector())
+
+		ops := readMetrics(t, res.MetricsCollector())
+		require.Len(t, ops, 4)
+		assert.Equal(t, map[string]string{"hook": "002-hook/main", "status": "error"}, ops[1].Labels)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+
+		h := pkg.Hook[pkg.HookConfig, *pkg.HookInput]{
+			Config: pkg.HookConfig{Metadata: pkg.HookMetadata{Name: "002-hook/main"}},
+			HookFunc: func(_ context.Context, _ *pkg.HookInput) error {
+				return nil
+			},
+		}
+
+		res, err := executor.NewModuleExecutor(h, log.NewNop(), executor.WithoutExecutionMetrics()).Execute(context.Background(), newRequest(t))
+		require.NoError(t, err)
+		assert.Empty(t, readMetrics(t, res.MetricsCollector()))
+	})
+}
+
+func Test_Go_Hook_Execute_Cancellation(t *testing.T) {
+	t.Parallel()
+
+	newRequest := func(t *testing.T) executor.Request {
+		hr := NewHookRequestMock(t)
+		hr.GetValuesMock.Expect().Return(map[string]any{}, nil)
+		hr.GetConfigValuesMock.Expect().Return(map[string]any{}, nil)
+		hr.GetBindingContextsMock.Expect().Return(nil, nil)
+		hr.GetDependencyContainerMock.Expect().Return(nil)
+
+		return hr
+	}
+
+	// the hook sets values and waits for the cancellation, like a hook waiting for a certificate
+	blockingHook := func(ctx context.Context, input *pkg.HookInput) error {
+		input.Values.Set("replicas", 2)
+
+		<-ctx.Done()
+
+		return ctx.Err()
+	}
+
+	t.Run("timeout discards output", func(t *testing.T) {
+		t.Parallel()
+
+		h := pkg.Hook[pkg.HookConfig, *pkg.HookInput]{
+			Config:   pkg.HookConfig{Metadata: pkg.HookMetadata{Name: "002-hook/main"}, Timeout: 10 * time.Millisecond},
+			HookFunc: blockingHook,
+		}
+
+		res, err := executor.NewModuleExecutor(h, log.NewNop()).Execute(context.Background(), newRequest(t))
+		require.EqualError(t, err, "hook reconcile func: hook timeout 10ms exceeded: context deadline exceeded (code: 1)")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.True(t, pkg.IsRetryable(err))
+		require.NotNil(t, res)
+		assert.Nil(t, res.ValuesPatchCollector(utils.MemoryValuesPatch))
+	})
+
+	t.Run("default timeout", func(t *testing.T) {
+		t.Parallel()
+
+		h := pkg.Hook[pkg.HookConfig, *pkg.HookInput]{
+			Config:   pkg.HookConfig{Metadata: pkg.HookMetadata{Name: "002-hook/main"}},
+			HookFunc: blockingHook,
+		}
+
+		_, err := executor.NewModuleExecutor(h, log.NewNop(), executor.WithDefaultTimeout(10*time.Millisecond)).Execute(context.Background(), newRequest(t))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("signal flushes output", func(t *testing.T) {
+		t.Parallel()
+
+		h := pkg.Hook[pkg.HookConfig, *pkg.HookInput]{
+			Config: pkg.HookConfig{
+				Metadata:      pkg.HookMetadata{Name: "002-hook/main"},
+				PartialOutput: pkg.PartialOutputFlush,
+			},
+			HookFunc: blockingHook,
+		}
+
+		ctx, cancel := context.WithCancelCause(context.Background())
+		time.AfterFunc(10*time.Millisecond, func() {
+			cancel(errors.New("received signal terminated"))
+		})
+
+		res, err := executor.NewModuleExecutor(h, log.NewNop()).Execute(ctx, newRequest(t))
+		require.NoError(t, err)
+
+		buf := bytes.NewBuffer(nil)
+		require.NoError(t, res.ValuesPatchCollector(utils.MemoryValuesPatch).WriteOutput(buf))
+		assert.JSONEq(t, `[{"op":"add","path":"/replicas","value":2}]`, buf.String())
+	})
+
+	t.Run("abandoned hook is not flushed", func(t *testing.T) {
+		t.Parallel()
+
+		stop := make(chan struct{})
+		defer close(stop)
+
+		h := pkg.Hook[pkg.HookConfig, *pkg.HookInput]{
+			Config: pkg.HookConfig{
+				Metadata:      pkg.HookMetadata{Name: "002-hook/main"},
+				Timeout:       10 * time.Millisecond,
+				PartialOutput: pkg.PartialOutputFlush,
+			},
+			// the hook ignores the context and keeps writing into its input
+			HookFunc: func(_ context.Context, input *pkg.HookInput) error {
+				for i := 0; ; i++ {
+					select {
+					case <-stop:
+						return nil
+					default:
+					}
+
+					input.Values.Set("replicas", i)
+					input.MetricsCollector.Inc("my_metric", nil)
+					input.PatchCollector.Delete("v1", "Pod", "default", "pod")
+				}
+			},
+		}
+
+		res, err := executor.NewModuleExecutor(h, log.NewNop()).Execute(context.Background(), newRequest(t))
+		require.EqualError(t, err, "hook reconcile func: hook timeout 10ms exceeded: hook did not return in 2s after cancellation (code: 1)")
+		assert.True(t, pkg.IsRetryable(err))
+		require.NotNil(t, res)
+		assert.Nil(t, res.ValuesPatchCollector(utils.MemoryValuesPatch))
+		assert.Nil(t, res.ObjectPatchCollector())
+
+		buf := bytes.NewBuffer(nil)
+		require.NoError(t, res.MetricsCollector().WriteOutput(buf))
+		assert.Contains(t, buf.String(), executor.ExecutionDurationMetric)
+		assert.NotContains(t, buf.String(), "my_metric")
+	})
+}
+
+type valuesValidatorFunc func(values map[string]any) error
+
+func (f valuesValidatorFunc) ValidateValues(values map[string]any) error {
+	return f(values)
+}
+
+func (f valuesValidatorFunc) ValidateConfigValues(_ map[string]any) error {
+	return nil
+}
+
+func Test_Go_Hook_Execute_ValuesValidation(t *testing.T) {
+	t.Parallel()
+
+	newRequest := func(t *testing.T) executor.Request {
+		hr := NewHookRequestMock(t)
+		hr.GetValuesMock.Expect().Return(map[string]any{"myModule": map[string]any{"replicas": 1}}, nil)
+		hr.GetConfigValuesMock.Expect().Return(map[string]any{}, nil)
+		hr.GetBindingContextsMock.Expect().Return([]bindingcontext.BindingContext{{Binding: "pods"}}, nil)
+		hr.GetDependencyContainerMock.Expect().Return(nil)
+
+		return hr
+	}
+
+	var validated map[string]any
+	validator := valuesValidatorFunc(func(values map[string]any) error {
+		validated = values
+
+		if _, ok := values["myModule"].(map[string]any)["replicas"].(string); ok {
+			return errors.New("myModule.replicas in body must be of type integer: \"string\"")
+		}
+
+		return nil
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		h := pkg.Hook[pkg.HookConfig, *pkg.HookInput]{
+			Config: pkg.HookConfig{Metadata: pkg.HookMetadata{Name: "002-hook/main"}},
+			HookFunc: func(_ context.Context, input *pkg.HookInput) error {
+				input.Values.Set("myModule.replicas", "two")
+				return nil
+			},
+		}
+
+		res, err := executor.NewModuleExecutor(h, log.NewNop(), executor.WithValuesValidator(validator)).Execute(context.Background(), newRequest(t))
+		require.EqualError(t, err, `hook "002-hook/main" set invalid values: myModule.replicas in body must be of type integer: "string" (code: 3)`)
+		assert.Equal(t, pkg.ErrorCodeConfiguration, pkg.ErrorCode(err))
+
+		var hookErr *executor.HookError
+		require.ErrorAs(t, err, &hookErr)
+		assert.Equal(t, []string{"pods"}, hookErr.Bindings)
+
+		// only execution metrics of the failed run are sent
+		require.NotNil(t, res)
+		assert.Nil(t, res.ValuesPatchCollector(utils.MemoryValuesPatch))
+		assertFailedRunMetrics(t, res)
+	})
+
+	t.Run("valid values", func(t *testing.T) {
+		h := pkg.Hook[pkg.HookConfig, *pkg.HookInput]{
+			Config: pkg.HookConfig{Metadata: pkg.HookMetadata{Name: "002-hook/main"}},
+			HookFunc: func(_ context.Context, input *pkg.HookInput) error {
+				input.Values.Set("myModule.replicas", 2)
+				return nil
+			},
+		}
+
+		_, err := executor.NewModuleExecutor(h, log.NewNop(), executor.WithValuesValidator(validator)).Execute(context.Background(), newRequest(t))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"myModule": map[string]any{"replicas": json.Number("2")}}, validated)
+	})
+
+	t.Run("values are not patched", func(t *testing.T) {
+		validated = nil
+
+		h := pkg.Hook[pkg.HookConfig, *pkg.HookInput]{
+			Config: pkg.HookConfig{Metadata: pkg.HookMetadata{Name: "002-hook/main"}},
+			HookFunc: func(_ context.Context, _ *pkg.HookInput) error {
+				return nil
+			},
+		}
+
+		_, err := executor.NewModuleExecutor(h, log.NewNop(), executor.WithValuesValidator(validator)).Execute(context.Background(), newRequest(t))
+		require.NoError(t, err)
+		assert.Nil(t, validated)
+	})
+}
+
+func Test_Go_Hook_Execute_ValuesRoots(t *testing.T) {
+	t.Parallel()
+
+	newRequest := func(t *testing.T) executor.Request {
+		hr := NewHookRequestMock(t)
+		hr.GetValuesMock.Expect().Return(map[string]any{}, nil)
+		hr.GetConfigValuesMock.Expect().Return(map[string]any{}, nil)
+		hr.GetBindingContextsMock.Expect().Return(nil, nil)
+		hr.GetDependencyContainerMock.Expect().Return(nil)
+
+		return hr
+	}
+
+	tests := []struct {
+		name    string
+		allowed []string
+		patch   func(input *pkg.HookInput)
+		err     string
+	}{
+		{
+			name: "module values",
+			patch: func(input *pkg.HookInput) {
+				input.Values.Set("myModule.internal.replicas", 2)
+				input.ConfigValues.Remove("myModule.replicas")
+			},
+		},
+		{
+			name: "global values",
+			patch: func(input *pkg.HookInput) {
+				input.Values.Set("myModule.internal.replicas", 2)
+				input.Values.Set("global.discovery.replicas", 2)
+			},
+			err: `hook "002-hook/main" patches values outside of allowed roots ["myModule"]: add /global/discovery/replicas (code: 2)`,
+		},
+		{
+			name: "other module config values",
+			patch: func(input *pkg.HookInput) {
+				input.ConfigValues.Set("otherModule.replicas", 2)
+			},
+			err: `hook "002-hook/main" patches config values outside of allowed roots ["myModule"]: add /otherModule/replicas (code: 2)`,
+		},
+		{
+			name:    "allowed global values",
+			allowed: []string{"global"},
+			patch: func(input *pkg.HookInput) {
+				input.Values.Set("global.discovery.replicas", 2)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := pkg.Hook[pkg.HookConfig, *pkg.HookInput]{
+				Config: pkg.HookConfig{
+					Metadata:           pkg.HookMetadata{Name: "002-hook/main"},
+					AllowedValuesRoots: tt.allowed,
+				},
+				HookFunc: func(_ context.Context, input *pkg.HookInput) error {
+					tt.patch(input)
+					return nil
+				},
+			}
+
+			res, err := executor.NewModuleExecutor(h, log.NewNop(), executor.WithValuesRootKey("myModule")).Execute(context.Background(), newRequest(t))
+			if tt.err == "" {
+				require.NoError(t, err)
+				return
 			}
+
+			require.EqualError(t, err, tt.err)
+			assert.Equal(t, pkg.ErrorCodePermanent, pkg.ErrorCode(err))
+			assertFailedRunMetrics(t, res)
 		})
 	}
 }
+
+// assertFailedRunMetrics asserts that the run is counted as failed and its patches are not counted.
+func assertFailedRunMetrics(t *testing.T, res executor.Result) {
+	t.Helper()
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, res.MetricsCollector().WriteOutput(buf))
+
+	ops, err := operation.MetricOperationsFromBytes(buf.Bytes())
+	require.NoError(t, err)
+
+	var runs int
+	for _, op := range ops {
+		assert.NotEqual(t, executor.ValuesPatchesMetric, op.Name)
+
+		if op.Name == executor.ExecutionsMetric {
+			runs++
+			assert.Equal(t, map[string]string{"hook": "002-hook/main", "status": "error"}, op.Labels)
+		}
+	}
+
+	assert.Equal(t, 1, runs)
+}
+
+func Test_Go_Hook_Execute_DebugDumpRedaction(t *testing.T) {
+	t.Parallel()
+
+	hr := NewHookRequestMock(t)
+	hr.GetValuesMock.Expect().Return(map[string]any{}, nil)
+	hr.GetConfigValuesMock.Expect().Return(map[string]any{}, nil)
+	hr.GetBindingContextsMock.Expect().Return([]bindingcontext.BindingContext{
+		{
+			Binding: "secrets",
+			Snapshots: map[string]bindingcontext.ObjectAndFilterResults{
+				"secrets": {{FilterResult: []byte(`{"name":"tls","key":"snapshot-key"}`)}},
+			},
+		},
+	}, nil)
+	hr.GetDependencyContainerMock.Expect().Return(nil)
+
+	h := pkg.Hook[pkg.HookConfig, *pkg.HookInput]{
+		Config: pkg.HookConfig{
+			Metadata: pkg.HookMetadata{Name: "002-hook/main"},
+			Sensitive: &pkg.SensitiveConfig{
+				Values:    []string{"myModule.internal.tls.key"},
+				Snapshots: map[string][]string{"secrets": {"key"}},
+			},
+		},
+		HookFunc: func(_ context.Context, input *pkg.HookInput) error {
+			input.Values.Set("myModule.internal.tls", map[string]string{"crt": "tls-crt", "key": "tls-key"})
+			input.Values.Set("myModule.internal.password", pkg.Sensitive("s3cr3t"))
+			input.Values.Set("myModule.internal.registry", struct {
+				Username string        `json:"username"`
+				Password pkg.Sensitive `json:"password"`
+			}{Username: "admin", Password: "registry-password"})
+			return nil
+		},
+	}
+
+	buf := bytes.NewBuffer(nil)
+	logger := log.NewLogger(log.WithOutput(buf), log.WithLevel(slog.LevelDebug))
+
+	_, err := executor.NewModuleExecutor(h, logger).Execute(context.Background(), hr)
+	require.NoError(t, err)
+
+	assert.Contains(t, buf.String(), "hook run dump")
+	assert.Contains(t, buf.String(), "tls-crt")
+	assert.Contains(t, buf.String(), `"name":"tls"`)
+	assert.NotContains(t, buf.String(), "tls-key")
+	assert.NotContains(t, buf.String(), "s3cr3t")
+	assert.NotContains(t, buf.String(), "snapshot-key")
+	assert.Contains(t, buf.String(), "admin")
+	assert.NotContains(t, buf.String(), "registry-password")
+}
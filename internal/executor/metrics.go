@@ -0,0 +1,100 @@
+package executor
+
+import (
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/deckhouse/module-sdk/internal/metric"
+	"github.com/deckhouse/module-sdk/internal/objectpatch"
+	metrics "github.com/deckhouse/module-sdk/pkg/metric/operation"
+	patchablevalues "github.com/deckhouse/module-sdk/pkg/patchable-values"
+)
+
+// Execution metrics are emitted for every hook run unless disabled with WithoutExecutionMetrics.
+// Names and labels are a part of the public API, do not change them.
+const (
+	// ExecutionDurationMetric is a histogram of hook run durations in seconds, labeled by hook and status.
+	ExecutionDurationMetric = "d8_module_sdk_hook_execution_seconds"
+	// ExecutionsMetric is a counter of hook runs, labeled by hook and status.
+	ExecutionsMetric = "d8_module_sdk_hook_executions_total"
+	// ObjectPatchesMetric is a counter of Kubernetes object patches emitted by the hook, labeled by hook.
+	ObjectPatchesMetric = "d8_module_sdk_hook_object_patches_total"
+	// ValuesPatchesMetric is a counter of values patch operations emitted by the hook after compaction,
+	// labeled by hook and type, see patchablevalues.PatchableValues.CompactedPatches.
+	ValuesPatchesMetric = "d8_module_sdk_hook_values_patches_total"
+	// SnapshotObjectsMetric is a gauge with the number of snapshot objects of the last run, labeled by hook and binding.
+	SnapshotObjectsMetric = "d8_module_sdk_hook_snapshot_objects"
+
+	// executionMetricsGroup prefixes the per-hook group of SnapshotObjectsMetric,
+	// so the gauges of bindings missing in the next run are expired.
+	executionMetricsGroup = "module-sdk-hook-execution:"
+
+	statusSuccess = "success"
+	statusError   = "error"
+
+	valuesPatchTypeValues       = "values"
+	valuesPatchTypeConfigValues = "config_values"
+)
+
+var executionDurationBuckets = []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120}
+
+// executionStats describes the hook run for execution metrics.
+type executionStats struct {
+	hook      string
+	elapsed   time.Duration
+	err       error
+	snapshots objectpatch.Snapshots
+
+	// patches are counted for successful runs only
+	objectPatches int
+	valuesPatches map[string]int
+}
+
+func collectExecutionMetrics(mc *metric.Collector, stats executionStats) {
+	status := statusSuccess
+	if stats.err != nil {
+		status = statusError
+	}
+
+	runLabels := map[string]string{
+		"hook":   stats.hook,
+		"status": status,
+	}
+
+	mc.Observe(ExecutionDurationMetric, stats.elapsed.Seconds(), executionDurationBuckets, runLabels)
+	mc.Inc(ExecutionsMetric, runLabels)
+
+	group := executionMetricsGroup + stats.hook
+	mc.Expire(group)
+	for _, binding := range slices.Sorted(maps.Keys(stats.snapshots)) {
+		mc.Set(SnapshotObjectsMetric, float64(len(stats.snapshots[binding])), map[string]string{
+			"hook":    stats.hook,
+			"binding": binding,
+		}, metrics.WithGroup(group))
+	}
+
+	if stats.err != nil {
+		return
+	}
+
+	mc.Add(ObjectPatchesMetric, float64(stats.objectPatches), map[string]string{"hook": stats.hook})
+
+	for _, patchType := range slices.Sorted(maps.Keys(stats.valuesPatches)) {
+		mc.Add(ValuesPatchesMetric, float64(stats.valuesPatches[patchType]), map[string]string{
+			"hook": stats.hook,
+			"type": patchType,
+		})
+	}
+}
+
+// emittedPatches returns the number of values patch operations written to the output.
+// Collected operations are counted if they can not be compacted, the output fails then anyway.
+func emittedPatches(values *patchablevalues.PatchableValues) int {
+	ops, err := values.CompactedPatches()
+	if err != nil {
+		return len(values.GetPatches())
+	}
+
+	return len(ops)
+}
@@ -2,8 +2,12 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/deckhouse/deckhouse/pkg/log"
 
@@ -15,15 +19,17 @@ import (
 )
 
 type moduleExecutor struct {
-	hook   pkg.Hook[pkg.HookConfig, *pkg.HookInput]
-	logger *log.Logger
+	hook    pkg.Hook[pkg.HookConfig, *pkg.HookInput]
+	options options
+	logger  *log.Logger
 }
 
 // NewModuleExecutor creates a new module hook executor
-func NewModuleExecutor(h pkg.Hook[pkg.HookConfig, *pkg.HookInput], logger *log.Logger) Executor {
+func NewModuleExecutor(h pkg.Hook[pkg.HookConfig, *pkg.HookInput], logger *log.Logger, opts ...Option) Executor {
 	return &moduleExecutor{
-		hook:   h,
-		logger: logger,
+		hook:    h,
+		options: newOptions(opts),
+		logger:  logger,
 	}
 }
 
@@ -84,25 +90,199 @@ func (e *moduleExecutor) Execute(ctx context.Context, req Request) (Result, erro
 	metricsCollector := metric.NewCollector()
 	objectPatchCollector := objectpatch.NewCollector(e.logger.Named("object-patch-collector"))
 
-	err = e.hook.HookFunc(ctx, &pkg.HookInput{
+	input := &pkg.HookInput{
 		Snapshots:        formattedSnapshots,
+		BindingContexts:  convertBindingContexts(bContext),
 		Values:           patchableValues,
 		ConfigValues:     patchableConfigValues,
 		PatchCollector:   objectPatchCollector,
 		MetricsCollector: metricsCollector,
 		DC:               req.GetDependencyContainer(),
 		Logger:           e.logger,
-	})
+	}
+
+	// nil interfaces are passed to the hook if there is no webhook request
+	validatingReview, err := newValidatingReview(bContext)
 	if err != nil {
-		return nil, fmt.Errorf("hook reconcile func: %w", err)
+		e.logger.Error("get validating review", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("get validating review: %w", err)
+	}
+	if validatingReview != nil {
+		input.Review = validatingReview
 	}
 
-	return &result{
+	mutatingReview, err := newMutatingReview(bContext)
+	if err != nil {
+		e.logger.Error("get mutating review", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("get mutating review: %w", err)
+	}
+	if mutatingReview != nil {
+		input.Mutation = mutatingReview
+	}
+
+	conversionReview, err := newConversionReview(bContext)
+	if err != nil {
+		e.logger.Error("get conversion review", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("get conversion review: %w", err)
+	}
+	if conversionReview != nil {
+		input.Conversion = conversionReview
+	}
+
+	run := &pkg.HookRun{
+		Metadata:         e.hook.Config.Metadata,
+		BindingContexts:  input.BindingContexts,
+		Values:           input.Values,
+		MetricsCollector: input.MetricsCollector,
+		Logger:           input.Logger,
+		Input:            input,
+	}
+
+	ctx, cancel := e.options.withTimeout(ctx, e.hook.Config.Timeout)
+	defer cancel()
+
+	start := time.Now()
+
+	err = runCancellable(ctx, func(ctx context.Context) error {
+		return runHook(ctx, run, e.hook.Middlewares, func(ctx context.Context) error {
+			return e.hook.HookFunc(ctx, input)
+		})
+	})
+
+	if errors.Is(err, errHookAbandoned) {
+		return e.options.abandonedResult(ctx, e.logger, e.hook.Config.Metadata.Name, bContext, formattedSnapshots, time.Since(start))
+	}
+
+	elapsed := time.Since(start)
+
+	logDebugDump(ctx, e.logger, e.hook.Config.Sensitive, formattedSnapshots, patchableValues, patchableConfigValues)
+
+	outputErr := handleCancellation(ctx, e.logger, e.hook.Config.PartialOutput, err)
+
+	// values are checked only if the output is sent, a failed check fails the run
+	var checkErr error
+	if outputErr == nil {
+		checkErr = e.checkValues(patchableValues, patchableConfigValues)
+	}
+
+	if !e.options.disableExecutionMetrics {
+		collectExecutionMetrics(metricsCollector, executionStats{
+			hook:          e.hook.Config.Metadata.Name,
+			elapsed:       elapsed,
+			err:           errors.Join(err, checkErr),
+			snapshots:     formattedSnapshots,
+			objectPatches: len(objectPatchCollector.Operations()),
+			valuesPatches: map[string]int{
+				valuesPatchTypeValues:       emittedPatches(patchableValues),
+				valuesPatchTypeConfigValues: emittedPatches(patchableConfigValues),
+			},
+		})
+	}
+
+	if outputErr != nil {
+		return e.options.failedResult(metricsCollector),
+			newHookError(e.hook.Config.Metadata.Name, bContext, fmt.Errorf("hook reconcile func: %w", outputErr))
+	}
+
+	if checkErr != nil {
+		return e.options.failedResult(metricsCollector), newHookError(e.hook.Config.Metadata.Name, bContext, checkErr)
+	}
+
+	res := &result{
 		patches: map[utils.ValuesPatchType]pkg.Outputer{
 			utils.MemoryValuesPatch: patchableValues,
 			utils.ConfigMapPatch:    patchableConfigValues,
 		},
 		objectPatchCollector: objectPatchCollector,
 		metricsCollector:     metricsCollector,
-	}, nil
+	}
+
+	if validatingReview != nil {
+		res.validatingResponse = validatingReview
+	}
+
+	if mutatingReview != nil {
+		res.mutatingResponse = mutatingReview
+	}
+
+	if conversionReview != nil {
+		res.conversionResponse = conversionReview
+	}
+
+	return res, nil
+}
+
+// checkValues checks roots of values patched by the hook and validates them.
+func (e *moduleExecutor) checkValues(values, configValues *patchablevalues.PatchableValues) error {
+	if err := e.checkValuesRoots(values, configValues); err != nil {
+		e.logger.Error("check values roots", slog.String("error", err.Error()))
+		return err
+	}
+
+	if err := e.validateValues(values, configValues); err != nil {
+		e.logger.Error("validate values", slog.String("error", err.Error()))
+		return err
+	}
+
+	return nil
+}
+
+// validateValues validates values with collected patches applied, if the hook patched them.
+func (e *moduleExecutor) validateValues(values, configValues *patchablevalues.PatchableValues) error {
+	if e.options.valuesValidator == nil {
+		return nil
+	}
+
+	for _, v := range []struct {
+		name     string
+		values   *patchablevalues.PatchableValues
+		validate func(map[string]any) error
+	}{
+		{name: "values", values: values, validate: e.options.valuesValidator.ValidateValues},
+		{name: "config values", values: configValues, validate: e.options.valuesValidator.ValidateConfigValues},
+	} {
+		if len(v.values.GetPatches()) == 0 {
+			continue
+		}
+
+		patched, err := v.values.Values()
+		if err != nil {
+			return fmt.Errorf("get patched %s: %w", v.name, err)
+		}
+
+		if err := v.validate(patched); err != nil {
+			return pkg.NewConfigurationError(fmt.Errorf("hook %q set invalid %s: %w", e.hook.Config.Metadata.Name, v.name, err))
+		}
+	}
+
+	return nil
+}
+
+// checkValuesRoots checks that values patches stay under the module values key
+// or roots explicitly allowed for the hook.
+func (e *moduleExecutor) checkValuesRoots(values, configValues *patchablevalues.PatchableValues) error {
+	if e.options.valuesRootKey == "" {
+		return nil
+	}
+
+	allowed := append([]string{e.options.valuesRootKey}, e.hook.Config.AllowedValuesRoots...)
+
+	for _, v := range []struct {
+		name   string
+		values *patchablevalues.PatchableValues
+	}{
+		{name: "values", values: values},
+		{name: "config values", values: configValues},
+	} {
+		for _, op := range v.values.GetPatches() {
+			// the path is a JSON pointer, e.g. /myModule/internal
+			root, _, _ := strings.Cut(strings.TrimPrefix(op.Path, "/"), "/")
+			if !slices.Contains(allowed, root) {
+				return pkg.NewPermanentError(fmt.Errorf("hook %q patches %s outside of allowed roots %q: %s %s",
+					e.hook.Config.Metadata.Name, v.name, allowed, op.Op, op.Path))
+			}
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,60 @@
+package executor
+
+import (
+	"time"
+)
+
+// Option configures an executor.
+type Option func(o *options)
+
+type options struct {
+	disableExecutionMetrics bool
+	defaultTimeout          time.Duration
+	valuesValidator         ValuesValidator
+	valuesRootKey           string
+}
+
+// ValuesValidator validates module values produced by hooks.
+type ValuesValidator interface {
+	ValidateValues(values map[string]any) error
+	ValidateConfigValues(values map[string]any) error
+}
+
+func newOptions(opts []Option) options {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// WithoutExecutionMetrics disables execution metrics of hook runs.
+func WithoutExecutionMetrics() Option {
+	return func(o *options) {
+		o.disableExecutionMetrics = true
+	}
+}
+
+// WithDefaultTimeout sets the timeout of hooks without their own timeout. Zero disables the timeout.
+func WithDefaultTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.defaultTimeout = timeout
+	}
+}
+
+// WithValuesRootKey allows module hooks to patch only values under the module values key,
+// and roots listed in pkg.HookConfig.AllowedValuesRoots. Empty key disables the check.
+func WithValuesRootKey(key string) Option {
+	return func(o *options) {
+		o.valuesRootKey = key
+	}
+}
+
+// WithValuesValidator validates values and config values patched by module hooks.
+// Invalid values fail the hook run, so they are not passed to Helm.
+func WithValuesValidator(v ValuesValidator) Option {
+	return func(o *options) {
+		o.valuesValidator = v
+	}
+}
@@ -1,6 +1,8 @@
 package registry
 
 import (
+	"slices"
+
 	"github.com/deckhouse/deckhouse/pkg/log"
 
 	"github.com/deckhouse/module-sdk/internal/executor"
@@ -11,16 +13,27 @@ type Registry struct {
 	executors         []executor.Executor
 	readinessExecutor executor.Executor
 
+	// middlewares wrap every registered hook outside of its own middlewares
+	middlewares []pkg.Middleware
+	// executorOptions are applied to executors of hooks registered after SetExecutorOptions
+	executorOptions []executor.Option
+
 	logger *log.Logger
 }
 
-func NewRegistry(logger *log.Logger) *Registry {
+func NewRegistry(logger *log.Logger, middlewares ...pkg.Middleware) *Registry {
 	return &Registry{
-		executors: make([]executor.Executor, 0, 1),
-		logger:    logger,
+		executors:   make([]executor.Executor, 0, 1),
+		middlewares: middlewares,
+		logger:      logger,
 	}
 }
 
+// SetExecutorOptions sets options of executors, call it before registering hooks
+func (r *Registry) SetExecutorOptions(opts ...executor.Option) {
+	r.executorOptions = opts
+}
+
 // Executors returns all executors
 func (r *Registry) Executors() []executor.Executor {
 	return r.executors
@@ -36,18 +49,29 @@ func (r *Registry) Readiness() executor.Executor {
 
 func (r *Registry) RegisterModuleHooks(hooks ...pkg.Hook[pkg.HookConfig, *pkg.HookInput]) {
 	for _, h := range hooks {
-		exec := executor.NewModuleExecutor(h, r.logger.Named(h.Config.Metadata.Name))
+		h.Middlewares = r.withGlobalMiddlewares(h.Middlewares)
+		exec := executor.NewModuleExecutor(h, r.logger.Named(h.Config.Metadata.Name), r.executorOptions...)
 		r.executors = append(r.executors, exec)
 	}
 }
 
 func (r *Registry) RegisterAppHooks(hooks ...pkg.Hook[pkg.ApplicationHookConfig, *pkg.ApplicationHookInput]) {
 	for _, h := range hooks {
-		exec := executor.NewApplicationExecutor(h, r.logger.Named(h.Config.Metadata.Name))
+		h.Middlewares = r.withGlobalMiddlewares(h.Middlewares)
+		exec := executor.NewApplicationExecutor(h, r.logger.Named(h.Config.Metadata.Name), r.executorOptions...)
 		r.executors = append(r.executors, exec)
 	}
 }
 
 func (r *Registry) SetReadinessHook(h pkg.Hook[pkg.HookConfig, *pkg.HookInput]) {
-	r.readinessExecutor = executor.NewModuleExecutor(h, r.logger.Named(h.Config.Metadata.Name))
+	h.Middlewares = r.withGlobalMiddlewares(h.Middlewares)
+	r.readinessExecutor = executor.NewModuleExecutor(h, r.logger.Named(h.Config.Metadata.Name), r.executorOptions...)
+}
+
+func (r *Registry) withGlobalMiddlewares(hookMiddlewares []pkg.Middleware) []pkg.Middleware {
+	if len(r.middlewares) == 0 {
+		return hookMiddlewares
+	}
+
+	return append(slices.Clip(r.middlewares), hookMiddlewares...)
 }
@@ -0,0 +1,41 @@
+package registry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deckhouse/deckhouse/pkg/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/deckhouse/module-sdk/pkg"
+)
+
+func TestGlobalMiddlewaresWrapHookMiddlewares(t *testing.T) {
+	var calls []string
+	trace := func(name string) pkg.Middleware {
+		return func(ctx context.Context, _ *pkg.HookRun, next func(ctx context.Context) error) error {
+			calls = append(calls, name)
+			return next(ctx)
+		}
+	}
+
+	r := NewRegistry(log.NewNop(), trace("global-1"), trace("global-2"))
+
+	first := r.withGlobalMiddlewares([]pkg.Middleware{trace("hook-a")})
+	second := r.withGlobalMiddlewares([]pkg.Middleware{trace("hook-b")})
+
+	require.NoError(t, pkg.RunMiddlewares(context.Background(), &pkg.HookRun{}, first, func(_ context.Context) error {
+		calls = append(calls, "hook")
+		return nil
+	}))
+	assert.Equal(t, []string{"global-1", "global-2", "hook-a", "hook"}, calls)
+
+	// chains of different hooks do not share the backing array
+	calls = nil
+	require.NoError(t, pkg.RunMiddlewares(context.Background(), &pkg.HookRun{}, second, func(_ context.Context) error {
+		return nil
+	}))
+	assert.Equal(t, []string{"global-1", "global-2", "hook-b"}, calls)
+	assert.Len(t, r.withGlobalMiddlewares(nil), 2)
+}
@@ -73,6 +73,24 @@ func (mc *Collector) Set(name string, value float64, labels map[string]string, o
 	mc.metrics = append(mc.metrics, m)
 }
 
+// Observe adds the value to the Histogram metric with the buckets
+func (mc *Collector) Observe(name string, value float64, buckets []float64, labels map[string]string, options ...pkg.MetricCollectorOption) {
+	m := metric.Operation{
+		Name:    name,
+		Group:   mc.defaultGroup,
+		Action:  "observe",
+		Value:   pointer.To(value),
+		Buckets: buckets,
+		Labels:  labels,
+	}
+
+	for _, opt := range options {
+		opt.Apply(&m)
+	}
+
+	mc.metrics = append(mc.metrics, m)
+}
+
 // Expire marks metric's group as expired
 func (mc *Collector) Expire(group string) {
 	if group == "" {
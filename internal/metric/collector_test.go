@@ -43,6 +43,19 @@ func Test_Collector_Inc_WithGroup(t *testing.T) {
 	assert.Equal(t, "example_group", metrics[0].Group)
 }
 
+func Test_Collector_Observe(t *testing.T) {
+	mc := NewCollector()
+
+	mc.Observe("d8_example_seconds", 0.3, []float64{0.1, 1}, map[string]string{"hook": "main"})
+
+	metrics := mc.CollectedMetrics()
+	require.Len(t, metrics, 1)
+	assert.Equal(t, "observe", metrics[0].Action)
+	assert.Equal(t, []float64{0.1, 1}, metrics[0].Buckets)
+	assert.Equal(t, 0.3, *metrics[0].Value)
+	require.NoError(t, metrics[0].Validate())
+}
+
 // WithGroup must override the collector's default group.
 func Test_Collector_WithGroup_OverridesDefaultGroup(t *testing.T) {
 	mc := NewCollector(WithDefaultGroup("default_group"))
@@ -0,0 +1,172 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+)
+
+const (
+	// ConfigValuesSchemaFile is the schema of the user-controllable module config.
+	ConfigValuesSchemaFile = "config-values.yaml"
+	// ValuesSchemaFile is the schema of the module values, usually extending ConfigValuesSchemaFile.
+	ValuesSchemaFile = "values.yaml"
+)
+
+// Validator validates module values against the module OpenAPI schemas.
+// The schemas describe the values under the module values key.
+type Validator struct {
+	valuesKey string
+
+	// schemas are nil if the module has no corresponding schema file
+	values       *spec.Schema
+	configValues *spec.Schema
+}
+
+// NewValidator loads the module schemas from the openapi dir.
+// Absent schema files are skipped, so the corresponding values are not validated.
+func NewValidator(dir, valuesKey string) (*Validator, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("openapi: %w", err)
+	}
+
+	if !info.IsDir() {
+		return nil, fmt.Errorf("openapi: %q is not a directory", dir)
+	}
+
+	v := &Validator{valuesKey: valuesKey}
+
+	v.values, err = loadValidationSchema(filepath.Join(dir, ValuesSchemaFile))
+	if err != nil {
+		return nil, err
+	}
+
+	v.configValues, err = loadValidationSchema(filepath.Join(dir, ConfigValuesSchemaFile))
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// ValidateValues validates the module values, e.g. {"moduleName": {...}, "global": {...}}.
+func (v *Validator) ValidateValues(values map[string]any) error {
+	return v.validate(v.values, values)
+}
+
+// ValidateConfigValues validates the module config values, e.g. {"moduleName": {...}}.
+func (v *Validator) ValidateConfigValues(values map[string]any) error {
+	return v.validate(v.configValues, values)
+}
+
+func (v *Validator) validate(schema *spec.Schema, values map[string]any) error {
+	if schema == nil {
+		return nil
+	}
+
+	// error messages start with the values key, e.g. "myModule.internal.replicas in body ..."
+	res := validate.NewSchemaValidator(schema, nil, v.valuesKey, strfmt.Default).Validate(convertNumbers(values[v.valuesKey]))
+	if res.IsValid() {
+		return nil
+	}
+
+	return errors.Join(res.Errors...)
+}
+
+// convertNumbers returns a copy of the document with json.Number values converted into int64,
+// or float64 if they are not integers, like Kubernetes decodes custom resources for validation.
+func convertNumbers(doc any) any {
+	switch d := doc.(type) {
+	case map[string]any:
+		res := make(map[string]any, len(d))
+		for key, value := range d {
+			res[key] = convertNumbers(value)
+		}
+
+		return res
+	case []any:
+		res := make([]any, 0, len(d))
+		for _, value := range d {
+			res = append(res, convertNumbers(value))
+		}
+
+		return res
+	case json.Number:
+		if i, err := d.Int64(); err == nil {
+			return i
+		}
+
+		if f, err := d.Float64(); err == nil {
+			return f
+		}
+	}
+
+	return doc
+}
+
+func loadValidationSchema(path string) (*spec.Schema, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	doc, err := LoadSchema(path)
+	if err != nil {
+		return nil, err
+	}
+
+	denyAdditionalProperties(doc)
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("openapi: marshal %q: %w", path, err)
+	}
+
+	schema := new(spec.Schema)
+	if err := json.Unmarshal(data, schema); err != nil {
+		return nil, fmt.Errorf("openapi: parse %q: %w", path, err)
+	}
+
+	return schema, nil
+}
+
+// denyAdditionalProperties sets `additionalProperties: false` on schemas with
+// properties that do not declare it, mirroring addon-operator's AdditionalPropertiesTransformer.
+func denyAdditionalProperties(schema map[string]any) {
+	if schema == nil {
+		return
+	}
+
+	if _, ok := schema["properties"]; ok {
+		if _, ok := schema["additionalProperties"]; !ok {
+			schema["additionalProperties"] = false
+		}
+	}
+
+	for _, key := range []string{"properties", "patternProperties", "definitions"} {
+		props, _ := schema[key].(map[string]any)
+		for _, prop := range props {
+			sub, _ := prop.(map[string]any)
+			denyAdditionalProperties(sub)
+		}
+	}
+
+	for _, key := range []string{"items", "additionalProperties", "not"} {
+		sub, _ := schema[key].(map[string]any)
+		denyAdditionalProperties(sub)
+	}
+
+	for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+		subs, _ := schema[key].([]any)
+		for _, s := range subs {
+			sub, _ := s.(map[string]any)
+			denyAdditionalProperties(sub)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/deckhouse/module-sdk/internal/openapi"
+)
+
+func TestValidator(t *testing.T) {
+	v, err := openapi.NewValidator("testdata/openapi", "myModule")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		values map[string]any
+		err    string
+	}{
+		{
+			name: "valid",
+			values: map[string]any{
+				"global": map[string]any{"clusterIsBootstrapped": true},
+				"myModule": map[string]any{
+					"replicas": 2,
+					"internal": map[string]any{"certificate": map[string]any{"crt": "a", "key": "b"}},
+				},
+			},
+		},
+		{
+			name:   "extended property",
+			values: map[string]any{"myModule": map[string]any{"https": map[string]any{"mode": "Enabled"}}},
+			err:    `myModule.https.mode in body should be one of [Disabled CertManager]`,
+		},
+		{
+			name:   "required property",
+			values: map[string]any{"myModule": map[string]any{"internal": map[string]any{"certificate": map[string]any{"crt": "a"}}}},
+			err:    `myModule.internal.certificate.key in body is required`,
+		},
+		{
+			name:   "unknown property",
+			values: map[string]any{"myModule": map[string]any{"internal": map[string]any{"unknown": 1}}},
+			err:    `myModule.internal.unknown in body is a forbidden property`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.ValidateValues(tt.values)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+
+	t.Run("config values", func(t *testing.T) {
+		assert.NoError(t, v.ValidateConfigValues(map[string]any{"myModule": map[string]any{"replicas": 1}}))
+
+		err := v.ValidateConfigValues(map[string]any{"myModule": map[string]any{"replicas": 0}})
+		assert.ErrorContains(t, err, "myModule.replicas in body should be greater than or equal to 1")
+
+		// patched values are decoded with json.Number numbers, see patchablevalues.PatchableValues.Values
+		assert.NoError(t, v.ValidateConfigValues(map[string]any{"myModule": map[string]any{"replicas": json.Number("2")}}))
+
+		err = v.ValidateConfigValues(map[string]any{"myModule": map[string]any{"replicas": json.Number("0")}})
+		assert.ErrorContains(t, err, "myModule.replicas in body should be greater than or equal to 1")
+
+		err = v.ValidateConfigValues(map[string]any{"myModule": map[string]any{"replicas": json.Number("1.5")}})
+		assert.ErrorContains(t, err, "myModule.replicas in body must be of type integer")
+
+		// internal is not a part of the config values schema
+		err = v.ValidateConfigValues(map[string]any{"myModule": map[string]any{"internal": map[string]any{}}})
+		assert.ErrorContains(t, err, "myModule.internal in body is a forbidden property")
+	})
+}
+
+func TestNewValidatorMissingDir(t *testing.T) {
+	_, err := openapi.NewValidator("testdata/absent", "myModule")
+	require.Error(t, err)
+}
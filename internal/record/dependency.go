@@ -0,0 +1,350 @@
+package record
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/jonboulle/clockwork"
+	"k8s.io/client-go/rest"
+
+	"github.com/deckhouse/module-sdk/pkg"
+)
+
+// Registry client methods in recorded interactions.
+const (
+	RegistryDigest   = "Digest"
+	RegistryListTags = "ListTags"
+	RegistryImage    = "Image"
+)
+
+// ErrNotRecorded is returned on replay by clients whose interactions are not recorded.
+var ErrNotRecorded = errors.New("not recorded")
+
+// Interactions are calls of the dependency container clients made by the hook.
+// Kubernetes client calls and registry images are not recorded.
+type Interactions struct {
+	HTTP     []HTTPInteraction     `json:"http,omitempty"`
+	Registry []RegistryInteraction `json:"registry,omitempty"`
+
+	mu sync.Mutex
+	// used marks interactions returned on replay, so repeated requests get responses in the recorded order
+	usedHTTP     []bool
+	usedRegistry []bool
+}
+
+// HTTPInteraction is a request of the HTTP client and its response. Request headers are not recorded,
+// as they can contain credentials.
+type HTTPInteraction struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	RequestBody []byte      `json:"requestBody,omitempty"`
+	Status      int         `json:"status,omitempty"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+	Error       string      `json:"error,omitempty"`
+}
+
+// RegistryInteraction is a call of the registry client.
+type RegistryInteraction struct {
+	Repo   string   `json:"repo"`
+	Method string   `json:"method"`
+	Tag    string   `json:"tag,omitempty"`
+	Digest string   `json:"digest,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+func (i *Interactions) addHTTP(interaction HTTPInteraction) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.HTTP = append(i.HTTP, interaction)
+}
+
+func (i *Interactions) addRegistry(interaction RegistryInteraction) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.Registry = append(i.Registry, interaction)
+}
+
+// takeHTTP returns the first not replayed interaction with the request.
+func (i *Interactions) takeHTTP(method, url string) (*HTTPInteraction, bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.usedHTTP == nil {
+		i.usedHTTP = make([]bool, len(i.HTTP))
+	}
+
+	for idx := range i.HTTP {
+		if !i.usedHTTP[idx] && i.HTTP[idx].Method == method && i.HTTP[idx].URL == url {
+			i.usedHTTP[idx] = true
+			return &i.HTTP[idx], true
+		}
+	}
+
+	return nil, false
+}
+
+// takeRegistry returns the first not replayed interaction with the call.
+func (i *Interactions) takeRegistry(repo, method, tag string) (*RegistryInteraction, bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.usedRegistry == nil {
+		i.usedRegistry = make([]bool, len(i.Registry))
+	}
+
+	for idx, r := range i.Registry {
+		if !i.usedRegistry[idx] && r.Repo == repo && r.Method == method && r.Tag == tag {
+			i.usedRegistry[idx] = true
+			return &i.Registry[idx], true
+		}
+	}
+
+	return nil, false
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
+
+func stringError(msg string) error {
+	if msg == "" {
+		return nil
+	}
+
+	return errors.New(msg)
+}
+
+var _ pkg.DependencyContainer = (*recordingContainer)(nil)
+
+// recordingContainer records interactions of the HTTP and registry clients of the wrapped container.
+type recordingContainer struct {
+	pkg.DependencyContainer
+
+	interactions *Interactions
+}
+
+// NewRecordingContainer wraps the container, so calls of its HTTP and registry clients are added to interactions.
+func NewRecordingContainer(dc pkg.DependencyContainer, interactions *Interactions) pkg.DependencyContainer {
+	return &recordingContainer{DependencyContainer: dc, interactions: interactions}
+}
+
+func (dc *recordingContainer) GetHTTPClient(options ...pkg.HTTPOption) pkg.HTTPClient {
+	return &recordingHTTPClient{client: dc.DependencyContainer.GetHTTPClient(options...), interactions: dc.interactions}
+}
+
+func (dc *recordingContainer) GetRegistryClient(repo string, options ...pkg.RegistryOption) (pkg.RegistryClient, error) {
+	client, err := dc.DependencyContainer.GetRegistryClient(repo, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &recordingRegistryClient{repo: repo, client: client, interactions: dc.interactions}, nil
+}
+
+func (dc *recordingContainer) MustGetRegistryClient(repo string, options ...pkg.RegistryOption) pkg.RegistryClient {
+	client, err := dc.GetRegistryClient(repo, options...)
+	if err != nil {
+		panic(err)
+	}
+
+	return client
+}
+
+type recordingHTTPClient struct {
+	client       pkg.HTTPClient
+	interactions *Interactions
+}
+
+func (c *recordingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	interaction := HTTPInteraction{Method: req.Method, URL: req.URL.String()}
+
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		interaction.RequestBody = body
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		interaction.Error = err.Error()
+		c.interactions.addHTTP(interaction)
+
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	interaction.Status = resp.StatusCode
+	interaction.Header = resp.Header.Clone()
+	interaction.Body = body
+	c.interactions.addHTTP(interaction)
+
+	return resp, nil
+}
+
+type recordingRegistryClient struct {
+	repo         string
+	client       pkg.RegistryClient
+	interactions *Interactions
+}
+
+func (c *recordingRegistryClient) Image(ctx context.Context, tag string) (v1.Image, error) {
+	image, err := c.client.Image(ctx, tag)
+	c.interactions.addRegistry(RegistryInteraction{Repo: c.repo, Method: RegistryImage, Tag: tag, Error: errorString(err)})
+
+	return image, err
+}
+
+func (c *recordingRegistryClient) Digest(ctx context.Context, tag string) (string, error) {
+	digest, err := c.client.Digest(ctx, tag)
+	c.interactions.addRegistry(RegistryInteraction{Repo: c.repo, Method: RegistryDigest, Tag: tag, Digest: digest, Error: errorString(err)})
+
+	return digest, err
+}
+
+func (c *recordingRegistryClient) ListTags(ctx context.Context) ([]string, error) {
+	tags, err := c.client.ListTags(ctx)
+	c.interactions.addRegistry(RegistryInteraction{Repo: c.repo, Method: RegistryListTags, Tags: tags, Error: errorString(err)})
+
+	return tags, err
+}
+
+var _ pkg.DependencyContainer = (*replayContainer)(nil)
+
+// replayContainer returns recorded responses of the HTTP and registry clients. The clock is fake,
+// set to the time of the recorded run. The Kubernetes client is not available.
+type replayContainer struct {
+	interactions *Interactions
+	clock        clockwork.Clock
+}
+
+// NewReplayContainer creates the container replaying the interactions of the run started at now.
+func NewReplayContainer(interactions *Interactions, now time.Time) pkg.DependencyContainer {
+	return &replayContainer{interactions: interactions, clock: clockwork.NewFakeClockAt(now)}
+}
+
+func (dc *replayContainer) GetHTTPClient(_ ...pkg.HTTPOption) pkg.HTTPClient {
+	return &replayHTTPClient{interactions: dc.interactions}
+}
+
+func (dc *replayContainer) GetK8sClient(_ ...pkg.KubernetesOption) (pkg.KubernetesClient, error) {
+	return nil, fmt.Errorf("kubernetes client: %w", ErrNotRecorded)
+}
+
+func (dc *replayContainer) MustGetK8sClient(options ...pkg.KubernetesOption) pkg.KubernetesClient {
+	client, err := dc.GetK8sClient(options...)
+	if err != nil {
+		panic(err)
+	}
+
+	return client
+}
+
+func (dc *replayContainer) GetClientConfig() (*rest.Config, error) {
+	return nil, fmt.Errorf("kubernetes client config: %w", ErrNotRecorded)
+}
+
+func (dc *replayContainer) GetRegistryClient(repo string, _ ...pkg.RegistryOption) (pkg.RegistryClient, error) {
+	return &replayRegistryClient{repo: repo, interactions: dc.interactions}, nil
+}
+
+func (dc *replayContainer) MustGetRegistryClient(repo string, options ...pkg.RegistryOption) pkg.RegistryClient {
+	client, err := dc.GetRegistryClient(repo, options...)
+	if err != nil {
+		panic(err)
+	}
+
+	return client
+}
+
+func (dc *replayContainer) GetClock() clockwork.Clock {
+	return dc.clock
+}
+
+type replayHTTPClient struct {
+	interactions *Interactions
+}
+
+func (c *replayHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	interaction, ok := c.interactions.takeHTTP(req.Method, req.URL.String())
+	if !ok {
+		return nil, fmt.Errorf("http %s %s: %w", req.Method, req.URL, ErrNotRecorded)
+	}
+
+	if err := stringError(interaction.Error); err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Status, http.StatusText(interaction.Status)),
+		StatusCode:    interaction.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        interaction.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(interaction.Body)),
+		ContentLength: int64(len(interaction.Body)),
+		Request:       req,
+	}, nil
+}
+
+type replayRegistryClient struct {
+	repo         string
+	interactions *Interactions
+}
+
+func (c *replayRegistryClient) Image(_ context.Context, tag string) (v1.Image, error) {
+	interaction, ok := c.interactions.takeRegistry(c.repo, RegistryImage, tag)
+	if ok {
+		if err := stringError(interaction.Error); err != nil {
+			return nil, err
+		}
+	}
+
+	// images are not recorded, only errors of their requests
+	return nil, fmt.Errorf("registry image %s:%s: %w", c.repo, tag, ErrNotRecorded)
+}
+
+func (c *replayRegistryClient) Digest(_ context.Context, tag string) (string, error) {
+	interaction, ok := c.interactions.takeRegistry(c.repo, RegistryDigest, tag)
+	if !ok {
+		return "", fmt.Errorf("registry digest %s:%s: %w", c.repo, tag, ErrNotRecorded)
+	}
+
+	return interaction.Digest, stringError(interaction.Error)
+}
+
+func (c *replayRegistryClient) ListTags(_ context.Context) ([]string, error) {
+	interaction, ok := c.interactions.takeRegistry(c.repo, RegistryListTags, "")
+	if !ok {
+		return nil, fmt.Errorf("registry tags %s: %w", c.repo, ErrNotRecorded)
+	}
+
+	return interaction.Tags, stringError(interaction.Error)
+}
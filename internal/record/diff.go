@@ -0,0 +1,102 @@
+package record
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/deckhouse/module-sdk/internal/executor"
+	gohook "github.com/deckhouse/module-sdk/pkg/hook"
+)
+
+// Diff describes the differences between the outputs of the recorded and the replayed runs,
+// it is empty if the outputs match. Durations of the runs and stack traces of panics are ignored.
+func Diff(recorded, replayed *gohook.StreamOutput) ([]string, error) {
+	fields := []struct {
+		name               string
+		recorded, replayed any
+	}{
+		{"valuesPatches", recorded.ValuesPatches, replayed.ValuesPatches},
+		{"configValuesPatches", recorded.ConfigValuesPatches, replayed.ConfigValuesPatches},
+		{"kubernetesOperations", recorded.KubernetesOperations, replayed.KubernetesOperations},
+		{"metrics", withoutDurations(recorded.Metrics), withoutDurations(replayed.Metrics)},
+		{"validatingResponse", recorded.ValidatingResponse, replayed.ValidatingResponse},
+		{"admissionResponse", recorded.AdmissionResponse, replayed.AdmissionResponse},
+		{"conversionResponse", recorded.ConversionResponse, replayed.ConversionResponse},
+		{"error", withoutStacktrace(recorded.Error), withoutStacktrace(replayed.Error)},
+	}
+
+	var diff []string
+	for _, f := range fields {
+		a, err := canonical(f.recorded)
+		if err != nil {
+			return nil, fmt.Errorf("recorded %s: %w", f.name, err)
+		}
+
+		b, err := canonical(f.replayed)
+		if err != nil {
+			return nil, fmt.Errorf("replayed %s: %w", f.name, err)
+		}
+
+		if a != b {
+			diff = append(diff, fmt.Sprintf("%s:\n  recorded: %s\n  replayed: %s", f.name, a, b))
+		}
+	}
+
+	return diff, nil
+}
+
+// canonical returns the JSON of the document with sorted keys, "null" for empty documents.
+func canonical(doc any) (string, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+
+	var v any
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &v); err != nil {
+			return "", err
+		}
+	}
+
+	if s, ok := v.([]any); ok && len(s) == 0 {
+		v = nil
+	}
+
+	data, err = json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+func withoutDurations(metrics []json.RawMessage) []any {
+	res := make([]any, 0, len(metrics))
+	for _, raw := range metrics {
+		var m map[string]any
+		if err := json.Unmarshal(raw, &m); err != nil {
+			res = append(res, raw)
+			continue
+		}
+
+		if m["name"] == executor.ExecutionDurationMetric {
+			delete(m, "value")
+		}
+
+		res = append(res, m)
+	}
+
+	return res
+}
+
+func withoutStacktrace(err *gohook.Error) *gohook.Error {
+	if err == nil {
+		return nil
+	}
+
+	res := *err
+	res.Stacktrace = ""
+
+	return &res
+}
@@ -0,0 +1,337 @@
+package record
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/deckhouse/module-sdk/pkg"
+	gohook "github.com/deckhouse/module-sdk/pkg/hook"
+)
+
+// FormatV1 is the version of the recording layout.
+const FormatV1 = "v1"
+
+// Files of a recording.
+const (
+	RecordingFile    = "recording.json"
+	InputFile        = "input.json"
+	OutputFile       = "output.json"
+	InteractionsFile = "interactions.json"
+)
+
+const sdkModulePath = "github.com/deckhouse/module-sdk"
+
+// runTimeLayout is RFC 3339 with fixed nanoseconds, so names of recordings of runs sort by time.
+const runTimeLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+// Recordings contain values, snapshots and HTTP bodies as is, including secrets,
+// so they are accessible by the owner only. Modes of existing directories are not changed.
+const (
+	dirPerm  = 0700
+	filePerm = 0600
+)
+
+// sensitiveEnvRe matches names of environment variables whose values are redacted in recordings.
+var sensitiveEnvRe = regexp.MustCompile(`(?i)(token|password|passwd|secret|auth|credential|key)`)
+
+// Recording is a recorded hook run. Input and Output are in the stream transport format,
+// so the run can be replayed without the files of addon-operator.
+type Recording struct {
+	Meta         *Meta
+	Input        *gohook.StreamInput
+	Output       *gohook.StreamOutput
+	Interactions *Interactions
+}
+
+// Meta describes the recorded run.
+type Meta struct {
+	Format     string `json:"format"`
+	SDKVersion string `json:"sdkVersion"`
+	HookName   string `json:"hookName"`
+	HookPath   string `json:"hookPath"`
+	// Time is the start of the run, replayed by the clock of the dependency container.
+	Time time.Time `json:"time"`
+	// Env is the environment of the run, values of sensitive variables are redacted.
+	Env map[string]string `json:"env"`
+}
+
+// NewMeta describes the run of the hook started now.
+func NewMeta(hookName, hookPath string, now time.Time) *Meta {
+	return &Meta{
+		Format:     FormatV1,
+		SDKVersion: SDKVersion(),
+		HookName:   hookName,
+		HookPath:   hookPath,
+		Time:       now,
+		Env:        environ(),
+	}
+}
+
+// IsArchive reports whether the recording at path is a gzipped tarball rather than a directory.
+func IsArchive(path string) bool {
+	return strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
+
+// Write writes the recording into the directory, or into the tarball if path is an archive, see IsArchive.
+// Files of the recording are readable by the owner only.
+func Write(path string, rec *Recording) error {
+	files := []struct {
+		name string
+		doc  any
+	}{
+		{RecordingFile, rec.Meta},
+		{InputFile, rec.Input},
+		{OutputFile, rec.Output},
+		{InteractionsFile, rec.Interactions},
+	}
+
+	if IsArchive(path) {
+		return writeArchive(path, func(add func(name string, data []byte) error) error {
+			for _, f := range files {
+				data, err := json.MarshalIndent(f.doc, "", "  ")
+				if err != nil {
+					return fmt.Errorf("marshal %s: %w", f.name, err)
+				}
+
+				if err := add(f.name, data); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	}
+
+	if err := os.MkdirAll(path, dirPerm); err != nil {
+		return fmt.Errorf("mkdir all: %w", err)
+	}
+
+	for _, f := range files {
+		data, err := json.MarshalIndent(f.doc, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal %s: %w", f.name, err)
+		}
+
+		if err := writeFile(filepath.Join(path, f.name), data); err != nil {
+			return fmt.Errorf("write %s: %w", f.name, err)
+		}
+	}
+
+	return nil
+}
+
+// WriteRun writes the recording into a new tarball in dir named by the time of the run and the hook name,
+// e.g. "2025-01-02T03:04:05.000000000Z-my-hook.tar.gz", so recordings of other runs are not overwritten.
+// The tarball is written into a temporary file and renamed when it is complete. It returns the path of the tarball.
+func WriteRun(dir string, rec *Recording) (string, error) {
+	if err := os.MkdirAll(dir, dirPerm); err != nil {
+		return "", fmt.Errorf("mkdir all: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".recording-*.tar.gz")
+	if err != nil {
+		return "", fmt.Errorf("create temp file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("close temp file: %w", err)
+	}
+
+	if err := Write(tmp.Name(), rec); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", err
+	}
+
+	// hook names can contain slashes, e.g. "002-hook/main"
+	name := rec.Meta.Time.UTC().Format(runTimeLayout) + "-" + strings.ReplaceAll(rec.Meta.HookName, "/", "_") + ".tar.gz"
+	path := filepath.Join(dir, name)
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("rename: %w", err)
+	}
+
+	return path, nil
+}
+
+// Read reads the recording from the directory or the tarball.
+func Read(path string) (*Recording, error) {
+	files := make(map[string][]byte)
+
+	if IsArchive(path) {
+		if err := readArchive(path, files); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, name := range []string{RecordingFile, InputFile, OutputFile, InteractionsFile} {
+			data, err := os.ReadFile(filepath.Join(path, name))
+			if err != nil {
+				return nil, fmt.Errorf("read %s: %w", name, err)
+			}
+
+			files[name] = data
+		}
+	}
+
+	rec := &Recording{
+		Meta:         new(Meta),
+		Input:        new(gohook.StreamInput),
+		Output:       new(gohook.StreamOutput),
+		Interactions: new(Interactions),
+	}
+
+	docs := []struct {
+		name string
+		doc  any
+	}{
+		{RecordingFile, rec.Meta},
+		{InputFile, rec.Input},
+		{OutputFile, rec.Output},
+		{InteractionsFile, rec.Interactions},
+	}
+
+	for _, d := range docs {
+		data, ok := files[d.name]
+		if !ok {
+			return nil, fmt.Errorf("read %s: file is not in the recording", d.name)
+		}
+
+		if err := json.Unmarshal(data, d.doc); err != nil {
+			return nil, fmt.Errorf("unmarshal %s: %w", d.name, err)
+		}
+	}
+
+	if rec.Meta.Format != FormatV1 {
+		return nil, fmt.Errorf("unsupported recording format %q", rec.Meta.Format)
+	}
+
+	return rec, nil
+}
+
+// writeFile writes the file readable by the owner only, including an overwritten one.
+func writeFile(path string, data []byte) error {
+	if err := os.WriteFile(path, data, filePerm); err != nil {
+		return err
+	}
+
+	return os.Chmod(path, filePerm)
+}
+
+func writeArchive(path string, write func(add func(name string, data []byte) error) error) error {
+	if err := os.MkdirAll(filepath.Dir(path), dirPerm); err != nil {
+		return fmt.Errorf("mkdir all: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, filePerm)
+	if err != nil {
+		return fmt.Errorf("open file: %w", err)
+	}
+
+	// the mode of an overwritten file is not changed by open
+	if err := f.Chmod(filePerm); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("chmod file: %w", err)
+	}
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	err = write(func(name string, data []byte) error {
+		hdr := &tar.Header{Name: name, Mode: int64(filePerm), Size: int64(len(data)), ModTime: time.Now()}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("write %s header: %w", name, err)
+		}
+
+		if _, err := tw.Write(data); err != nil {
+			return fmt.Errorf("write %s: %w", name, err)
+		}
+
+		return nil
+	})
+
+	return errors.Join(err, tw.Close(), gz.Close(), f.Close())
+}
+
+func readArchive(path string, files map[string][]byte) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open file: %w", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("gzip: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("tar: %w", err)
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", hdr.Name, err)
+		}
+
+		files[filepath.Base(hdr.Name)] = data
+	}
+}
+
+// SDKVersion returns the version of the SDK the hook binary is built with.
+func SDKVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+
+	if info.Main.Path == sdkModulePath {
+		return info.Main.Version
+	}
+
+	for _, dep := range info.Deps {
+		if dep.Path != sdkModulePath {
+			continue
+		}
+
+		if dep.Replace != nil {
+			return dep.Version + " => " + dep.Replace.Path + " " + dep.Replace.Version
+		}
+
+		return dep.Version
+	}
+
+	return "unknown"
+}
+
+func environ() map[string]string {
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		if sensitiveEnvRe.MatchString(name) {
+			value = pkg.RedactedValue
+		}
+
+		env[name] = value
+	}
+
+	return env
+}
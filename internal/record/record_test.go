@@ -0,0 +1,267 @@
+package record_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/deckhouse/module-sdk/internal/record"
+	"github.com/deckhouse/module-sdk/pkg"
+	gohook "github.com/deckhouse/module-sdk/pkg/hook"
+)
+
+func TestWriteRead(t *testing.T) {
+	t.Setenv("MODULE_NAME", "my-module")
+	t.Setenv("REGISTRY_AUTH", "secret")
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	rec := &record.Recording{
+		Meta: record.NewMeta("my_hook", "my-module/hooks/my_hook", now),
+		Input: &gohook.StreamInput{
+			BindingContexts: json.RawMessage(`[{"binding":"pods"}]`),
+			Values:          map[string]any{"myModule": map[string]any{"replicas": float64(2)}},
+		},
+		Output: &gohook.StreamOutput{
+			ValuesPatches: json.RawMessage(`[{"op":"remove","path":"/myModule/replicas"}]`),
+			Error:         &gohook.Error{Message: "boom", Code: pkg.ErrorCodePermanent},
+		},
+		Interactions: &record.Interactions{
+			Registry: []record.RegistryInteraction{{Repo: "registry.example.com/app", Method: record.RegistryListTags, Tags: []string{"v1"}}},
+		},
+	}
+
+	assert.Equal(t, "my-module", rec.Meta.Env["MODULE_NAME"])
+	assert.Equal(t, pkg.RedactedValue, rec.Meta.Env["REGISTRY_AUTH"])
+
+	for _, path := range []string{filepath.Join(t.TempDir(), "run"), filepath.Join(t.TempDir(), "run.tar.gz")} {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			require.NoError(t, record.Write(path, rec))
+			assertPrivate(t, path)
+
+			// an overwritten recording is private as well
+			overwritten := path
+			if !record.IsArchive(path) {
+				overwritten = filepath.Join(path, record.InputFile)
+			}
+			require.NoError(t, os.Chmod(overwritten, 0644))
+
+			require.NoError(t, record.Write(path, rec))
+			assertPrivate(t, path)
+
+			read, err := record.Read(path)
+			require.NoError(t, err)
+
+			assert.Equal(t, rec.Meta.HookPath, read.Meta.HookPath)
+			assert.True(t, now.Equal(read.Meta.Time))
+			assert.JSONEq(t, string(rec.Input.BindingContexts), string(read.Input.BindingContexts))
+			assert.Equal(t, rec.Input.Values, read.Input.Values)
+			assert.JSONEq(t, string(rec.Output.ValuesPatches), string(read.Output.ValuesPatches))
+			assert.Equal(t, rec.Output.Error, read.Output.Error)
+			assert.Equal(t, rec.Interactions.Registry, read.Interactions.Registry)
+		})
+	}
+
+	_, err := record.Read(filepath.Join(t.TempDir(), "missing"))
+	assert.ErrorContains(t, err, "read recording.json")
+}
+
+func TestWriteRun(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "recordings")
+
+	first := &record.Recording{Meta: record.NewMeta("002-hook/main", "", time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))}
+	second := &record.Recording{Meta: record.NewMeta("002-hook/main", "", time.Date(2026, 1, 2, 3, 4, 6, 0, time.UTC))}
+
+	firstPath, err := record.WriteRun(dir, first)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "2026-01-02T03:04:05.000000000Z-002-hook_main.tar.gz"), firstPath)
+	assertPrivate(t, firstPath)
+
+	secondPath, err := record.WriteRun(dir, second)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "2026-01-02T03:04:06.000000000Z-002-hook_main.tar.gz"), secondPath)
+
+	// the first recording is kept and no temporary files are left
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	read, err := record.Read(firstPath)
+	require.NoError(t, err)
+	assert.True(t, first.Meta.Time.Equal(read.Meta.Time))
+}
+
+func TestRecordReplay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Echo", r.Method)
+		_, _ = w.Write([]byte("echo " + string(body)))
+	}))
+	defer srv.Close()
+
+	interactions := new(record.Interactions)
+	dc := record.NewRecordingContainer(&fakeContainer{}, interactions)
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/echo", strings.NewReader("hello"))
+	require.NoError(t, err)
+
+	resp, err := dc.GetHTTPClient().Do(req)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "echo hello", string(body), "the response body is readable after recording")
+
+	registry := dc.MustGetRegistryClient("registry.example.com/app")
+
+	digest, err := registry.Digest(context.Background(), "v1")
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:v1", digest)
+
+	_, err = registry.ListTags(context.Background())
+	require.EqualError(t, err, "unauthorized")
+
+	require.Len(t, interactions.HTTP, 1)
+	assert.Equal(t, []byte("hello"), interactions.HTTP[0].RequestBody)
+	assert.Equal(t, http.StatusOK, interactions.HTTP[0].Status)
+	require.Len(t, interactions.Registry, 2)
+
+	// interactions are replayed after a round trip through JSON
+	data, err := json.Marshal(interactions)
+	require.NoError(t, err)
+
+	replayed := new(record.Interactions)
+	require.NoError(t, json.Unmarshal(data, replayed))
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	replay := record.NewReplayContainer(replayed, now)
+
+	assert.True(t, now.Equal(replay.GetClock().Now()))
+
+	req, err = http.NewRequest(http.MethodPost, srv.URL+"/echo", nil)
+	require.NoError(t, err)
+
+	resp, err = replay.GetHTTPClient().Do(req)
+	require.NoError(t, err)
+	body, _ = io.ReadAll(resp.Body)
+	assert.Equal(t, "echo hello", string(body))
+	assert.Equal(t, "POST", resp.Header.Get("X-Echo"))
+
+	// every interaction is replayed once
+	_, err = replay.GetHTTPClient().Do(req)
+	require.ErrorIs(t, err, record.ErrNotRecorded)
+
+	digest, err = replay.MustGetRegistryClient("registry.example.com/app").Digest(context.Background(), "v1")
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:v1", digest)
+
+	_, err = replay.MustGetRegistryClient("registry.example.com/app").ListTags(context.Background())
+	require.EqualError(t, err, "unauthorized")
+
+	_, err = replay.MustGetRegistryClient("registry.example.com/other").ListTags(context.Background())
+	require.ErrorIs(t, err, record.ErrNotRecorded)
+
+	_, err = replay.GetK8sClient()
+	require.ErrorIs(t, err, record.ErrNotRecorded)
+}
+
+func TestDiff(t *testing.T) {
+	recorded := &gohook.StreamOutput{
+		ValuesPatches: json.RawMessage(`[{"op":"add","path":"/a","value":{"x":1,"y":2}}]`),
+		Metrics: []json.RawMessage{
+			json.RawMessage(`{"name":"d8_module_sdk_hook_execution_seconds","action":"observe","value":0.1}`),
+			json.RawMessage(`{"name":"my_metric","action":"set","value":1}`),
+		},
+		Error: &gohook.Error{Message: "boom", Stacktrace: "goroutine 1"},
+	}
+
+	replayed := &gohook.StreamOutput{
+		ValuesPatches: json.RawMessage(`[{"op":"add","path":"/a","value":{"y":2,"x":1}}]`),
+		Metrics: []json.RawMessage{
+			json.RawMessage(`{"name":"d8_module_sdk_hook_execution_seconds","action":"observe","value":0.2}`),
+			json.RawMessage(`{"name":"my_metric","action":"set","value":1}`),
+		},
+		KubernetesOperations: []json.RawMessage{},
+		Error:                &gohook.Error{Message: "boom", Stacktrace: "goroutine 2"},
+	}
+
+	diff, err := record.Diff(recorded, replayed)
+	require.NoError(t, err)
+	assert.Empty(t, diff)
+
+	replayed.Metrics[1] = json.RawMessage(`{"name":"my_metric","action":"set","value":2}`)
+	replayed.Error = nil
+
+	diff, err = record.Diff(recorded, replayed)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"metrics:\n" +
+			`  recorded: [{"action":"observe","name":"d8_module_sdk_hook_execution_seconds"},{"action":"set","name":"my_metric","value":1}]` + "\n" +
+			`  replayed: [{"action":"observe","name":"d8_module_sdk_hook_execution_seconds"},{"action":"set","name":"my_metric","value":2}]`,
+		"error:\n" +
+			`  recorded: {"message":"boom","retryable":false}` + "\n" +
+			`  replayed: null`,
+	}, diff)
+}
+
+// assertPrivate checks that the recording is accessible by the owner only, as it contains secrets.
+func assertPrivate(t *testing.T, path string) {
+	t.Helper()
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+
+	if !info.IsDir() {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+		return
+	}
+
+	assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+
+	for _, name := range []string{record.RecordingFile, record.InputFile, record.OutputFile, record.InteractionsFile} {
+		info, err := os.Stat(filepath.Join(path, name))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), name)
+	}
+}
+
+type fakeContainer struct {
+	pkg.DependencyContainer
+}
+
+func (dc *fakeContainer) GetHTTPClient(_ ...pkg.HTTPOption) pkg.HTTPClient {
+	return http.DefaultClient
+}
+
+func (dc *fakeContainer) GetRegistryClient(_ string, _ ...pkg.RegistryOption) (pkg.RegistryClient, error) {
+	return &fakeRegistry{}, nil
+}
+
+type fakeRegistry struct{}
+
+func (r *fakeRegistry) Image(_ context.Context, _ string) (v1.Image, error) {
+	return nil, nil
+}
+
+func (r *fakeRegistry) Digest(_ context.Context, tag string) (string, error) {
+	return "sha256:" + tag, nil
+}
+
+func (r *fakeRegistry) ListTags(_ context.Context) ([]string, error) {
+	return nil, errUnauthorized
+}
+
+var errUnauthorized = &registryError{"unauthorized"}
+
+type registryError struct{ msg string }
+
+func (e *registryError) Error() string { return e.msg }
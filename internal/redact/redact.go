@@ -0,0 +1,202 @@
+package redact
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/deckhouse/module-sdk/pkg"
+	patchablevalues "github.com/deckhouse/module-sdk/pkg/patchable-values"
+	"github.com/deckhouse/module-sdk/pkg/utils"
+)
+
+// Wildcard matches any key or array element in a sensitive path.
+const Wildcard = "*"
+
+// Redactor replaces values at sensitive paths with pkg.RedactedValue.
+// A path is a list of keys, see patchablevalues.SplitPath.
+type Redactor struct {
+	paths [][]string
+}
+
+// New creates a redactor of the paths.
+func New(paths ...[]string) *Redactor {
+	return &Redactor{paths: paths}
+}
+
+// ParsePaths splits gjson-style dotted paths, e.g. of pkg.SensitiveConfig, into keys.
+func ParsePaths(paths []string) [][]string {
+	res := make([][]string, 0, len(paths))
+	for _, path := range paths {
+		res = append(res, patchablevalues.SplitPath(path))
+	}
+
+	return res
+}
+
+// Empty reports whether the redactor has no paths.
+func (r *Redactor) Empty() bool {
+	return r == nil || len(r.paths) == 0
+}
+
+// Value returns a copy of the JSON document with sensitive paths redacted.
+// The document is not modified.
+func (r *Redactor) Value(doc any) any {
+	if r.Empty() {
+		return doc
+	}
+
+	for _, path := range r.paths {
+		doc = redactAt(doc, path)
+	}
+
+	return doc
+}
+
+// Raw redacts the raw JSON document. Invalid JSON is redacted as a whole,
+// as its sensitive parts can not be found.
+func (r *Redactor) Raw(raw []byte) json.RawMessage {
+	if r.Empty() {
+		return raw
+	}
+
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return json.RawMessage(strconv.Quote(pkg.RedactedValue))
+	}
+
+	data, err := json.Marshal(r.Value(doc))
+	if err != nil {
+		return json.RawMessage(strconv.Quote(pkg.RedactedValue))
+	}
+
+	return data
+}
+
+// Patches returns copies of the values patches with values at sensitive paths redacted:
+// values of patches under a sensitive path as a whole, and sensitive parts of objects set by patches.
+func (r *Redactor) Patches(ops []*utils.ValuesPatchOperation) []*utils.ValuesPatchOperation {
+	if r.Empty() {
+		return ops
+	}
+
+	res := make([]*utils.ValuesPatchOperation, 0, len(ops))
+	for _, op := range ops {
+		if len(op.Value) == 0 {
+			res = append(res, op)
+			continue
+		}
+
+		keys := splitPointer(op.Path)
+
+		whole := false
+		var nested [][]string
+		for _, path := range r.paths {
+			if matchPrefix(path, keys) {
+				// the patch sets a value under the sensitive path
+				whole = true
+				break
+			}
+
+			if matchPrefix(keys, path) {
+				nested = append(nested, path[len(keys):])
+			}
+		}
+
+		redacted := *op
+		switch {
+		case whole:
+			redacted.Value = json.RawMessage(strconv.Quote(pkg.RedactedValue))
+		case len(nested) > 0:
+			redacted.Value = New(nested...).Raw(op.Value)
+		default:
+			res = append(res, op)
+			continue
+		}
+
+		res = append(res, &redacted)
+	}
+
+	return res
+}
+
+// matchPrefix reports whether the prefix matches the beginning of keys, both can contain wildcards.
+func matchPrefix(prefix, keys []string) bool {
+	if len(prefix) > len(keys) {
+		return false
+	}
+
+	for i, key := range prefix {
+		if key != Wildcard && keys[i] != Wildcard && key != keys[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// redactAt returns a copy of doc with the path redacted, only changed branches are copied.
+func redactAt(doc any, path []string) any {
+	if len(path) == 0 {
+		return pkg.RedactedValue
+	}
+
+	switch d := doc.(type) {
+	case map[string]any:
+		var res map[string]any
+		for key, value := range d {
+			if path[0] != Wildcard && path[0] != key {
+				continue
+			}
+
+			if res == nil {
+				res = maps.Clone(d)
+			}
+
+			res[key] = redactAt(value, path[1:])
+		}
+
+		if res == nil {
+			return doc
+		}
+
+		return res
+	case []any:
+		var res []any
+		for i, value := range d {
+			if path[0] != Wildcard && path[0] != strconv.Itoa(i) {
+				continue
+			}
+
+			if res == nil {
+				res = slices.Clone(d)
+			}
+
+			res[i] = redactAt(value, path[1:])
+		}
+
+		if res == nil {
+			return doc
+		}
+
+		return res
+	}
+
+	return doc
+}
+
+// splitPointer splits the JSON pointer into unescaped keys.
+func splitPointer(pointer string) []string {
+	if pointer == "" || pointer == "/" {
+		return nil
+	}
+
+	keys := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, key := range keys {
+		keys[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(key)
+	}
+
+	return keys
+}
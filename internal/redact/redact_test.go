@@ -0,0 +1,84 @@
+package redact_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/deckhouse/module-sdk/internal/redact"
+	"github.com/deckhouse/module-sdk/pkg/utils"
+)
+
+func TestRedactorValue(t *testing.T) {
+	doc := map[string]any{
+		"myModule": map[string]any{
+			"internal": map[string]any{
+				"certificates": []any{
+					map[string]any{"crt": "crt-a", "key": "key-a"},
+					map[string]any{"crt": "crt-b", "key": "key-b"},
+				},
+				"auth": map[string]any{"password": "s3cr3t", "user": "admin"},
+			},
+		},
+	}
+
+	r := redact.New(
+		[]string{"myModule", "internal", "certificates", "*", "key"},
+		[]string{"myModule", "internal", "auth", "password"},
+		[]string{"myModule", "absent"},
+	)
+
+	assert.Equal(t, map[string]any{
+		"myModule": map[string]any{
+			"internal": map[string]any{
+				"certificates": []any{
+					map[string]any{"crt": "crt-a", "key": "[REDACTED]"},
+					map[string]any{"crt": "crt-b", "key": "[REDACTED]"},
+				},
+				"auth": map[string]any{"password": "[REDACTED]", "user": "admin"},
+			},
+		},
+	}, r.Value(doc))
+
+	// the document is not modified
+	assert.Equal(t, "s3cr3t", doc["myModule"].(map[string]any)["internal"].(map[string]any)["auth"].(map[string]any)["password"])
+
+	assert.JSONEq(t, `"[REDACTED]"`, string(r.Raw([]byte(`{"invalid`))))
+	assert.JSONEq(t, `{"a":1}`, string(redact.New().Raw([]byte(`{"a":1}`))))
+}
+
+func TestRedactorPatches(t *testing.T) {
+	r := redact.New(
+		[]string{"myModule", "internal", "auth", "password"},
+		[]string{"myModule", "internal", "certificates", "*", "key"},
+	)
+
+	ops := []*utils.ValuesPatchOperation{
+		{Op: "add", Path: "/myModule/internal/auth/password", Value: json.RawMessage(`"s3cr3t"`)},
+		{Op: "add", Path: "/myModule/internal/auth", Value: json.RawMessage(`{"password":"s3cr3t","user":"admin"}`)},
+		{Op: "add", Path: "/myModule/internal/certificates/0", Value: json.RawMessage(`{"crt":"crt-a","key":"key-a"}`)},
+		{Op: "add", Path: "/myModule/internal/replicas", Value: json.RawMessage(`2`)},
+		{Op: "remove", Path: "/myModule/internal/auth/password"},
+	}
+
+	redacted := r.Patches(ops)
+	require.Len(t, redacted, 5)
+
+	assert.JSONEq(t, `"[REDACTED]"`, string(redacted[0].Value))
+	assert.JSONEq(t, `{"password":"[REDACTED]","user":"admin"}`, string(redacted[1].Value))
+	assert.JSONEq(t, `{"crt":"crt-a","key":"[REDACTED]"}`, string(redacted[2].Value))
+	assert.Same(t, ops[3], redacted[3])
+	assert.Same(t, ops[4], redacted[4])
+
+	// patches are not modified
+	assert.JSONEq(t, `"s3cr3t"`, string(ops[0].Value))
+}
+
+func TestParsePaths(t *testing.T) {
+	assert.Equal(t, [][]string{
+		{"myModule", "internal", "certificates", "*", "key"},
+		{"myModule", "auth.example.com"},
+	}, redact.ParsePaths([]string{"myModule.internal.certificates.*.key", `myModule.auth\.example\.com`}))
+}
@@ -0,0 +1,136 @@
+package file_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/deckhouse/deckhouse/pkg/log"
+
+	fileTransport "github.com/deckhouse/module-sdk/internal/transport/file"
+	"github.com/deckhouse/module-sdk/pkg"
+	gohook "github.com/deckhouse/module-sdk/pkg/hook"
+	"github.com/deckhouse/module-sdk/pkg/utils"
+)
+
+func Test_ResponseSend(t *testing.T) {
+	t.Parallel()
+
+	const (
+		metrics = "{\"name\":\"a\",\"action\":\"set\",\"value\":1}\n"
+		values  = `[{"op":"add","path":"/myModule/replicas","value":2}]`
+	)
+
+	t.Run("outputs and manifest", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+
+		// the previous content is replaced
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "values.json"), []byte("previous content which is longer"), 0644))
+
+		tr := fileTransport.NewTransport(&fileTransport.Config{
+			MetricsPath:          filepath.Join(dir, "metrics.json"),
+			ValuesJSONPath:       filepath.Join(dir, "values.json"),
+			ConfigValuesJSONPath: filepath.Join(dir, "config_values.json"),
+			OutputManifestPath:   filepath.Join(dir, "out", "manifest.json"),
+			// creates the manifest dir
+			CreateFilesByYourself: true,
+		}, "hook-name", nil, log.NewNop())
+
+		err := tr.NewResponse().Send(&result{metrics: metrics, values: values})
+		require.NoError(t, err)
+
+		assertFile(t, filepath.Join(dir, "metrics.json"), metrics)
+		assertFile(t, filepath.Join(dir, "values.json"), values)
+		assertFile(t, filepath.Join(dir, "config_values.json"), "")
+
+		info, err := os.Stat(filepath.Join(dir, "values.json"))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+
+		data, err := os.ReadFile(filepath.Join(dir, "out", "manifest.json"))
+		require.NoError(t, err)
+
+		manifest := new(gohook.OutputManifest)
+		require.NoError(t, json.Unmarshal(data, manifest))
+		assert.Equal(t, &gohook.OutputManifest{
+			Hook: "hook-name",
+			Outputs: []gohook.OutputManifestEntry{
+				{Name: "metrics", Path: filepath.Join(dir, "metrics.json"), Size: len(metrics), SHA256: checksum(metrics)},
+				{Name: "valuesPatches", Path: filepath.Join(dir, "values.json"), Size: len(values), SHA256: checksum(values)},
+				{Name: "configValuesPatches", Path: filepath.Join(dir, "config_values.json"), Size: 0, SHA256: checksum("")},
+			},
+		}, manifest)
+
+		// no temporary files are left
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 4)
+	})
+
+	t.Run("errors are aggregated", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+
+		tr := fileTransport.NewTransport(&fileTransport.Config{
+			MetricsPath:          filepath.Join(dir, "missing", "metrics.json"),
+			ValuesJSONPath:       filepath.Join(dir, "values.json"),
+			ConfigValuesJSONPath: filepath.Join(dir, "missing", "config_values.json"),
+		}, "hook-name", nil, log.NewNop())
+
+		err := tr.NewResponse().Send(&result{metrics: metrics, values: values})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `send metrics to "`+filepath.Join(dir, "missing", "metrics.json")+`": create temp file`)
+		assert.Contains(t, err.Error(), `send configValuesPatches to "`+filepath.Join(dir, "missing", "config_values.json")+`": create temp file`)
+
+		// other outputs are written
+		assertFile(t, filepath.Join(dir, "values.json"), values)
+	})
+}
+
+func assertFile(t *testing.T, path, expected string) {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(data))
+}
+
+func checksum(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+type outputer string
+
+func (o outputer) WriteOutput(w io.Writer) error {
+	_, err := io.WriteString(w, string(o))
+	return err
+}
+
+type result struct {
+	metrics outputer
+	values  outputer
+}
+
+func (r *result) MetricsCollector() pkg.Outputer     { return r.metrics }
+func (r *result) ObjectPatchCollector() pkg.Outputer { return nil }
+func (r *result) ValuesPatchCollector(key utils.ValuesPatchType) pkg.Outputer {
+	if key == utils.ConfigMapPatch {
+		return outputer("")
+	}
+
+	return r.values
+}
+func (r *result) ValidatingResponse() pkg.Outputer { return nil }
+func (r *result) MutatingResponse() pkg.Outputer   { return nil }
+func (r *result) ConversionResponse() pkg.Outputer { return nil }
@@ -1,18 +1,23 @@
 package file
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"github.com/deckhouse/deckhouse/pkg/log"
 
 	bindingcontext "github.com/deckhouse/module-sdk/internal/binding-context"
 	"github.com/deckhouse/module-sdk/internal/executor"
 	"github.com/deckhouse/module-sdk/pkg"
+	gohook "github.com/deckhouse/module-sdk/pkg/hook"
 	"github.com/deckhouse/module-sdk/pkg/utils"
 )
 
@@ -23,10 +28,16 @@ type Config struct {
 	ConfigValuesPath   string
 
 	// output
-	MetricsPath          string
-	KubernetesPath       string
-	ValuesJSONPath       string
-	ConfigValuesJSONPath string
+	MetricsPath            string
+	KubernetesPath         string
+	ValuesJSONPath         string
+	ConfigValuesJSONPath   string
+	ValidatingResponsePath string
+	AdmissionResponsePath  string
+	ConversionResponsePath string
+
+	// OutputManifestPath is the path of the manifest of written outputs, empty disables it
+	OutputManifestPath string
 
 	HookConfigPath string
 
@@ -42,10 +53,14 @@ type Transport struct {
 	ConfigValuesPath   string
 
 	// output
-	MetricsPath          string
-	KubernetesPath       string
-	ValuesJSONPath       string
-	ConfigValuesJSONPath string
+	MetricsPath            string
+	KubernetesPath         string
+	ValuesJSONPath         string
+	ConfigValuesJSONPath   string
+	ValidatingResponsePath string
+	AdmissionResponsePath  string
+	ConversionResponsePath string
+	OutputManifestPath     string
 
 	dc pkg.DependencyContainer
 
@@ -66,10 +81,14 @@ func NewTransport(cfg *Config, hookName string, dc pkg.DependencyContainer, logg
 		ValuesPath:         cfg.ValuesPath,
 		ConfigValuesPath:   cfg.ConfigValuesPath,
 
-		MetricsPath:          cfg.MetricsPath,
-		KubernetesPath:       cfg.KubernetesPath,
-		ValuesJSONPath:       cfg.ValuesJSONPath,
-		ConfigValuesJSONPath: cfg.ConfigValuesJSONPath,
+		MetricsPath:            cfg.MetricsPath,
+		KubernetesPath:         cfg.KubernetesPath,
+		ValuesJSONPath:         cfg.ValuesJSONPath,
+		ConfigValuesJSONPath:   cfg.ConfigValuesJSONPath,
+		ValidatingResponsePath: cfg.ValidatingResponsePath,
+		AdmissionResponsePath:  cfg.AdmissionResponsePath,
+		ConversionResponsePath: cfg.ConversionResponsePath,
+		OutputManifestPath:     cfg.OutputManifestPath,
 
 		dc: dc,
 
@@ -142,20 +161,7 @@ func (r *Request) GetBindingContexts() ([]bindingcontext.BindingContext, error)
 		return nil, fmt.Errorf("decode binding context: %w", err)
 	}
 
-	for cidx, context := range contexts {
-		for sidx, snap := range context.Snapshots {
-			for ridx, res := range snap {
-				// if we have empty object or filter result for some reason
-				if string(res.Object) == `{}` || string(res.Object) == `"{}"` {
-					contexts[cidx].Snapshots[sidx][ridx].Object = nil
-				}
-
-				if string(res.FilterResult) == `{}` || string(res.FilterResult) == `"{}"` {
-					contexts[cidx].Snapshots[sidx][ridx].FilterResult = nil
-				}
-			}
-		}
-	}
+	bindingcontext.DropEmptyObjects(contexts)
 
 	return contexts, nil
 }
@@ -186,10 +192,14 @@ func (t *Transport) NewResponse() *Response {
 	return &Response{
 		hookName: t.hookName,
 
-		MetricsPath:          t.MetricsPath,
-		KubernetesPath:       t.KubernetesPath,
-		ValuesJSONPath:       t.ValuesJSONPath,
-		ConfigValuesJSONPath: t.ConfigValuesJSONPath,
+		MetricsPath:            t.MetricsPath,
+		KubernetesPath:         t.KubernetesPath,
+		ValuesJSONPath:         t.ValuesJSONPath,
+		ConfigValuesJSONPath:   t.ConfigValuesJSONPath,
+		ValidatingResponsePath: t.ValidatingResponsePath,
+		AdmissionResponsePath:  t.AdmissionResponsePath,
+		ConversionResponsePath: t.ConversionResponsePath,
+		OutputManifestPath:     t.OutputManifestPath,
 
 		CreateFilesByYourself: t.CreateFilesByYourself,
 
@@ -200,38 +210,101 @@ func (t *Transport) NewResponse() *Response {
 type Response struct {
 	hookName string
 
-	MetricsPath          string
-	KubernetesPath       string
-	ValuesJSONPath       string
-	ConfigValuesJSONPath string
+	MetricsPath            string
+	KubernetesPath         string
+	ValuesJSONPath         string
+	ConfigValuesJSONPath   string
+	ValidatingResponsePath string
+	AdmissionResponsePath  string
+	ConversionResponsePath string
+	OutputManifestPath     string
 
 	CreateFilesByYourself bool
 
 	logger *log.Logger
 }
 
+// Send writes outputs of the result. Outputs are written atomically, see writeFileAtomic,
+// so a crash does not leave a partially written output. Errors of all outputs are returned.
 func (r *Response) Send(res executor.Result) error {
-	collectors := map[string]pkg.Outputer{
-		r.MetricsPath:          res.MetricsCollector(),
-		r.KubernetesPath:       res.ObjectPatchCollector(),
-		r.ValuesJSONPath:       res.ValuesPatchCollector(utils.MemoryValuesPatch),
-		r.ConfigValuesJSONPath: res.ValuesPatchCollector(utils.ConfigMapPatch),
+	// slice is used instead of map, because shell-operator can pass the same path
+	// for validating and mutating responses
+	collectors := []struct {
+		name      string
+		path      string
+		collector pkg.Outputer
+	}{
+		{"metrics", r.MetricsPath, res.MetricsCollector()},
+		{"kubernetesOperations", r.KubernetesPath, res.ObjectPatchCollector()},
+		{"valuesPatches", r.ValuesJSONPath, res.ValuesPatchCollector(utils.MemoryValuesPatch)},
+		{"configValuesPatches", r.ConfigValuesJSONPath, res.ValuesPatchCollector(utils.ConfigMapPatch)},
+		{"validatingResponse", r.ValidatingResponsePath, res.ValidatingResponse()},
+		{"admissionResponse", r.AdmissionResponsePath, res.MutatingResponse()},
+		{"conversionResponse", r.ConversionResponsePath, res.ConversionResponse()},
 	}
 
-	for path, collector := range collectors {
-		if path == "" || collector == nil {
+	manifest := &gohook.OutputManifest{Hook: r.hookName, Outputs: make([]gohook.OutputManifestEntry, 0, len(collectors))}
+
+	var errs error
+	for _, c := range collectors {
+		if c.path == "" || c.collector == nil {
 			continue
 		}
-		err := r.send(path, collector)
+
+		entry, err := r.send(c.path, c.collector)
 		if err != nil {
-			r.logger.Error("sending output", slog.String("path", path), slog.String("error", err.Error()))
+			errs = errors.Join(errs, fmt.Errorf("send %s to %q: %w", c.name, c.path, err))
+			continue
 		}
+
+		entry.Name = c.name
+		// the output written later to the same path replaces the previous one
+		manifest.Outputs = slices.DeleteFunc(manifest.Outputs, func(e gohook.OutputManifestEntry) bool {
+			return e.Path == entry.Path
+		})
+		manifest.Outputs = append(manifest.Outputs, *entry)
 	}
 
-	return nil
+	if r.OutputManifestPath != "" {
+		if err := r.sendManifest(manifest); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("send manifest to %q: %w", r.OutputManifestPath, err))
+		}
+	}
+
+	return errs
+}
+
+func (r *Response) send(path string, outputer pkg.Outputer) (*gohook.OutputManifestEntry, error) {
+	buf := bytes.NewBuffer(nil)
+
+	err := outputer.WriteOutput(buf)
+	if err != nil {
+		return nil, fmt.Errorf("write output: %w", err)
+	}
+
+	if err := r.writeFile(path, buf.Bytes()); err != nil {
+		return nil, err
+	}
+
+	checksum := sha256.Sum256(buf.Bytes())
+
+	return &gohook.OutputManifestEntry{
+		Path:   path,
+		Size:   buf.Len(),
+		SHA256: hex.EncodeToString(checksum[:]),
+	}, nil
 }
 
-func (r *Response) send(path string, outputer pkg.Outputer) error {
+func (r *Response) sendManifest(manifest *gohook.OutputManifest) error {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+
+	return r.writeFile(r.OutputManifestPath, data)
+}
+
+func (r *Response) writeFile(path string, data []byte) error {
 	if r.CreateFilesByYourself {
 		dir := filepath.Dir(path)
 
@@ -241,18 +314,59 @@ func (r *Response) send(path string, outputer pkg.Outputer) error {
 		}
 	}
 
-	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	return writeFileAtomic(path, data)
+}
+
+// writeFileAtomic writes data to a temporary file in the directory of path, syncs it and renames it to path,
+// so readers of path see either the previous content or the whole data.
+// Paths which are not regular files, e.g. pipes, can not be replaced and are written directly.
+func writeFileAtomic(path string, data []byte) error {
+	if info, err := os.Stat(path); err == nil && !info.Mode().IsRegular() {
+		f, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return fmt.Errorf("open file: %w", err)
+		}
+
+		_, err = f.Write(data)
+
+		return errors.Join(err, f.Close())
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+
+	renamed := false
 	defer func() {
-		_ = f.Close()
+		if !renamed {
+			_ = os.Remove(tmp.Name())
+		}
 	}()
-	if err != nil {
-		return fmt.Errorf("open file: %w", err)
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
 	}
 
-	err = outputer.WriteOutput(f)
 	if err != nil {
-		return fmt.Errorf("write output: %w", err)
+		return fmt.Errorf("write temp file: %w", err)
 	}
 
+	// temp files are created with 0600
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("chmod temp file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("rename temp file: %w", err)
+	}
+
+	renamed = true
+
 	return nil
 }
@@ -357,6 +357,24 @@ func Test_Request_GetBindingContexts(t *testing.T) {
 	},
 	"type": "Group"
 	}
+]`
+		bindingContextEventAndSynchronization = `
+[
+  {
+    "binding": "pods",
+    "type": "Synchronization",
+    "objects": [
+      {"object":{"name":"pod-1"}},
+      {"object":"{}","filterResult":{"name":"pod-2"}}
+    ]
+  },
+  {
+    "binding": "pods",
+    "type": "Event",
+    "watchEvent": "Deleted",
+    "object": {"name":"pod-1"},
+    "filterResult": {}
+  }
 ]`
 		bindingContextBadJSON               = `{{{{`
 		bindingContextEmptySnapshotsObjects = `
@@ -525,6 +543,40 @@ func Test_Request_GetBindingContexts(t *testing.T) {
 					},
 				},
 			},
+		},
+		{
+			meta: meta{
+				name:    "event and synchronization binding contexts",
+				enabled: true,
+			},
+			fields: fields{},
+			args: args{
+				filesContent: map[string]file{
+					bindingContextsFilePath: {
+						Name:    generateFileNameWithTS(bindingContextsFilePath),
+						Content: bindingContextEventAndSynchronization,
+					},
+				},
+				filesPermissions: 0777,
+			},
+			wants: wants{
+				bcs: []bindingcontext.BindingContext{
+					{
+						Binding: "pods",
+						Type:    "Synchronization",
+						Objects: bindingcontext.ObjectAndFilterResults{
+							{Object: []byte(`{"name":"pod-1"}`)},
+							{FilterResult: []byte(`{"name":"pod-2"}`)},
+						},
+					},
+					{
+						Binding:    "pods",
+						Type:       "Event",
+						WatchEvent: "Deleted",
+						Object:     []byte(`{"name":"pod-1"}`),
+					},
+				},
+			},
 		}, {
 			meta: meta{
 				name:    "bad json",
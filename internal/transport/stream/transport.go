@@ -0,0 +1,269 @@
+package stream
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/deckhouse/deckhouse/pkg/log"
+
+	bindingcontext "github.com/deckhouse/module-sdk/internal/binding-context"
+	"github.com/deckhouse/module-sdk/internal/executor"
+	"github.com/deckhouse/module-sdk/pkg"
+	gohook "github.com/deckhouse/module-sdk/pkg/hook"
+	"github.com/deckhouse/module-sdk/pkg/utils"
+)
+
+// Transport reads the hook input as a single JSON document, see gohook.StreamInput,
+// and writes the hook output as a single JSON envelope, see gohook.StreamOutput.
+type Transport struct {
+	in  io.Reader
+	out io.Writer
+
+	dc pkg.DependencyContainer
+
+	logger *log.Logger
+}
+
+func NewTransport(in io.Reader, out io.Writer, dc pkg.DependencyContainer, logger *log.Logger) *Transport {
+	return &Transport{
+		in:     in,
+		out:    out,
+		dc:     dc,
+		logger: logger,
+	}
+}
+
+// NewRequest reads and decodes the input document. The input must contain exactly one JSON document.
+func (t *Transport) NewRequest() (*Request, error) {
+	dec := json.NewDecoder(t.in)
+
+	input := new(gohook.StreamInput)
+	if err := dec.Decode(input); err != nil {
+		return nil, fmt.Errorf("decode input: %w", err)
+	}
+
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("decode input: unexpected data after the input document")
+	}
+
+	contexts := make([]bindingcontext.BindingContext, 0)
+	if len(input.BindingContexts) > 0 {
+		if err := json.Unmarshal(input.BindingContexts, &contexts); err != nil {
+			return nil, fmt.Errorf("decode binding context: %w", err)
+		}
+	}
+
+	bindingcontext.DropEmptyObjects(contexts)
+
+	return &Request{
+		values:          input.Values,
+		configValues:    input.ConfigValues,
+		bindingContexts: contexts,
+		dc:              t.dc,
+	}, nil
+}
+
+var _ executor.Request = (*Request)(nil)
+
+type Request struct {
+	values          map[string]any
+	configValues    map[string]any
+	bindingContexts []bindingcontext.BindingContext
+
+	dc pkg.DependencyContainer
+}
+
+func (r *Request) GetValues() (map[string]any, error) {
+	return r.values, nil
+}
+
+func (r *Request) GetConfigValues() (map[string]any, error) {
+	return r.configValues, nil
+}
+
+func (r *Request) GetBindingContexts() ([]bindingcontext.BindingContext, error) {
+	return r.bindingContexts, nil
+}
+
+func (r *Request) GetDependencyContainer() pkg.DependencyContainer {
+	return r.dc
+}
+
+func (t *Transport) NewResponse() *Response {
+	return &Response{
+		out:    t.out,
+		logger: t.logger,
+	}
+}
+
+type Response struct {
+	out io.Writer
+
+	logger *log.Logger
+}
+
+// Send writes the output envelope. The result is nil if the hook run failed before the execution,
+// hookErr is nil if the run succeeded.
+func (r *Response) Send(res executor.Result, hookErr *gohook.Error) error {
+	output := &gohook.StreamOutput{Error: hookErr}
+
+	if res != nil {
+		var err error
+
+		documents := []struct {
+			field     *json.RawMessage
+			collector pkg.Outputer
+		}{
+			{&output.ValuesPatches, res.ValuesPatchCollector(utils.MemoryValuesPatch)},
+			{&output.ConfigValuesPatches, res.ValuesPatchCollector(utils.ConfigMapPatch)},
+			{&output.ValidatingResponse, res.ValidatingResponse()},
+			{&output.AdmissionResponse, res.MutatingResponse()},
+			{&output.ConversionResponse, res.ConversionResponse()},
+		}
+
+		for _, d := range documents {
+			*d.field, err = writeDocument(d.collector)
+			if err != nil {
+				return err
+			}
+		}
+
+		output.KubernetesOperations, err = writeStream(res.ObjectPatchCollector())
+		if err != nil {
+			return fmt.Errorf("kubernetes operations: %w", err)
+		}
+
+		output.Metrics, err = writeStream(res.MetricsCollector())
+		if err != nil {
+			return fmt.Errorf("metrics: %w", err)
+		}
+	}
+
+	if err := json.NewEncoder(r.out).Encode(output); err != nil {
+		return fmt.Errorf("encode output: %w", err)
+	}
+
+	return nil
+}
+
+// writeDocument returns the output of the outputer, nil if it is empty.
+func writeDocument(outputer pkg.Outputer) (json.RawMessage, error) {
+	if outputer == nil {
+		return nil, nil
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := outputer.WriteOutput(buf); err != nil {
+		return nil, fmt.Errorf("write output: %w", err)
+	}
+
+	data := bytes.TrimSpace(buf.Bytes())
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	return data, nil
+}
+
+// writeStream returns documents of the JSON stream written by the outputer.
+func writeStream(outputer pkg.Outputer) ([]json.RawMessage, error) {
+	data, err := writeDocument(outputer)
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	var documents []json.RawMessage
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc json.RawMessage
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return documents, nil
+			}
+
+			return nil, fmt.Errorf("decode output: %w", err)
+		}
+
+		documents = append(documents, doc)
+	}
+}
+
+var _ executor.Result = (*Result)(nil)
+
+// Result is the hook result decoded from the output envelope, e.g. received from the hook server,
+// so it can be sent by another transport.
+type Result struct {
+	output *gohook.StreamOutput
+}
+
+func NewResult(output *gohook.StreamOutput) *Result {
+	return &Result{output: output}
+}
+
+func (r *Result) MetricsCollector() pkg.Outputer {
+	return rawStream(r.output.Metrics)
+}
+
+func (r *Result) ObjectPatchCollector() pkg.Outputer {
+	return rawStream(r.output.KubernetesOperations)
+}
+
+func (r *Result) ValuesPatchCollector(key utils.ValuesPatchType) pkg.Outputer {
+	if key == utils.ConfigMapPatch {
+		return rawDocument(r.output.ConfigValuesPatches)
+	}
+
+	return rawDocument(r.output.ValuesPatches)
+}
+
+func (r *Result) ValidatingResponse() pkg.Outputer {
+	return optionalDocument(r.output.ValidatingResponse)
+}
+
+func (r *Result) MutatingResponse() pkg.Outputer {
+	return optionalDocument(r.output.AdmissionResponse)
+}
+
+func (r *Result) ConversionResponse() pkg.Outputer {
+	return optionalDocument(r.output.ConversionResponse)
+}
+
+// optionalDocument returns nil for an empty document, as webhook responses are nil
+// if the hook is not triggered by the webhook binding.
+func optionalDocument(doc json.RawMessage) pkg.Outputer {
+	if len(doc) == 0 {
+		return nil
+	}
+
+	return rawDocument(doc)
+}
+
+type rawDocument json.RawMessage
+
+func (d rawDocument) WriteOutput(w io.Writer) error {
+	if len(d) == 0 {
+		return nil
+	}
+
+	_, err := w.Write(d)
+
+	return err
+}
+
+// rawStream writes documents as a JSON stream, one document per line.
+type rawStream []json.RawMessage
+
+func (s rawStream) WriteOutput(w io.Writer) error {
+	for _, doc := range s {
+		if _, err := w.Write(append(slices.Clip(doc), '\n')); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
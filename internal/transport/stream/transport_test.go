@@ -0,0 +1,196 @@
+package stream_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/deckhouse/deckhouse/pkg/log"
+
+	"github.com/deckhouse/module-sdk/internal/transport/stream"
+	"github.com/deckhouse/module-sdk/pkg"
+	gohook "github.com/deckhouse/module-sdk/pkg/hook"
+	"github.com/deckhouse/module-sdk/pkg/utils"
+)
+
+func Test_NewRequest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		input           string
+		values          map[string]any
+		configValues    map[string]any
+		bindingContexts int
+		err             string
+	}{
+		{
+			name: "full input",
+			input: `{
+				"bindingContexts": [{"binding": "nodes", "type": "Synchronization", "objects": [{"object": {"kind": "Node"}}, {"object": {}}]}],
+				"values": {"myModule": {"replicas": 2}},
+				"configValues": {"myModule": {}}
+			}`,
+			values:          map[string]any{"myModule": map[string]any{"replicas": float64(2)}},
+			configValues:    map[string]any{"myModule": map[string]any{}},
+			bindingContexts: 1,
+		},
+		{
+			name:  "empty input document",
+			input: `{}`,
+		},
+		{
+			name:  "no input",
+			input: ``,
+			err:   "decode input: EOF",
+		},
+		{
+			name:  "invalid json",
+			input: `{{{`,
+			err:   "decode input: invalid character '{' looking for beginning of object key string",
+		},
+		{
+			name:  "several documents",
+			input: `{} {}`,
+			err:   "decode input: unexpected data after the input document",
+		},
+		{
+			name:  "invalid binding contexts",
+			input: `{"bindingContexts": {}}`,
+			err:   "decode binding context: json: cannot unmarshal object into Go value of type []bindingcontext.BindingContext",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			transport := stream.NewTransport(strings.NewReader(tt.input), io.Discard, nil, log.NewNop())
+
+			req, err := transport.NewRequest()
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+
+			values, err := req.GetValues()
+			require.NoError(t, err)
+			assert.Equal(t, tt.values, values)
+
+			configValues, err := req.GetConfigValues()
+			require.NoError(t, err)
+			assert.Equal(t, tt.configValues, configValues)
+
+			contexts, err := req.GetBindingContexts()
+			require.NoError(t, err)
+			require.Len(t, contexts, tt.bindingContexts)
+
+			if tt.bindingContexts > 0 {
+				// empty objects are dropped as in the file transport
+				require.Len(t, contexts[0].Objects, 2)
+				assert.JSONEq(t, `{"kind": "Node"}`, string(contexts[0].Objects[0].Object))
+				assert.Nil(t, contexts[0].Objects[1].Object)
+			}
+		})
+	}
+}
+
+func Test_ResponseSend(t *testing.T) {
+	t.Parallel()
+
+	t.Run("successful run", func(t *testing.T) {
+		t.Parallel()
+
+		res := &result{
+			metrics:      "{\"name\":\"a\",\"action\":\"set\",\"value\":1}\n{\"name\":\"b\",\"action\":\"add\",\"value\":2}\n",
+			objects:      "{\"operation\":\"Delete\",\"kind\":\"Pod\",\"name\":\"p\"}\n",
+			values:       `[{"op":"add","path":"/myModule/replicas","value":2}]`,
+			configValues: "",
+		}
+
+		buf := bytes.NewBuffer(nil)
+		err := stream.NewTransport(nil, buf, nil, log.NewNop()).NewResponse().Send(res, nil)
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{
+			"valuesPatches": [{"op":"add","path":"/myModule/replicas","value":2}],
+			"kubernetesOperations": [{"operation":"Delete","kind":"Pod","name":"p"}],
+			"metrics": [{"name":"a","action":"set","value":1}, {"name":"b","action":"add","value":2}]
+		}`, buf.String())
+		// the envelope is a single line
+		assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+	})
+
+	t.Run("failed run", func(t *testing.T) {
+		t.Parallel()
+
+		hookErr := &gohook.Error{Hook: "my-hook", Code: pkg.ErrorCodePermanent, Message: "boom"}
+
+		buf := bytes.NewBuffer(nil)
+		err := stream.NewTransport(nil, buf, nil, log.NewNop()).NewResponse().Send(nil, hookErr)
+		require.NoError(t, err)
+
+		output := new(gohook.StreamOutput)
+		require.NoError(t, json.Unmarshal(buf.Bytes(), output))
+		assert.Equal(t, &gohook.StreamOutput{Error: hookErr}, output)
+	})
+}
+
+type outputer string
+
+func (o outputer) WriteOutput(w io.Writer) error {
+	_, err := io.WriteString(w, string(o))
+	return err
+}
+
+type result struct {
+	metrics      outputer
+	objects      outputer
+	values       outputer
+	configValues outputer
+}
+
+func (r *result) MetricsCollector() pkg.Outputer     { return r.metrics }
+func (r *result) ObjectPatchCollector() pkg.Outputer { return r.objects }
+func (r *result) ValuesPatchCollector(key utils.ValuesPatchType) pkg.Outputer {
+	if key == utils.ConfigMapPatch {
+		return r.configValues
+	}
+
+	return r.values
+}
+func (r *result) ValidatingResponse() pkg.Outputer { return nil }
+func (r *result) MutatingResponse() pkg.Outputer   { return nil }
+func (r *result) ConversionResponse() pkg.Outputer { return nil }
+
+func Test_Result(t *testing.T) {
+	t.Parallel()
+
+	envelope := `{"valuesPatches":[{"op":"remove","path":"/myModule/a"}],"kubernetesOperations":[{"operation":"Delete"},{"operation":"Create"}],"metrics":[{"name":"a"}],"conversionResponse":{"convertedObjects":[]}}`
+
+	output := new(gohook.StreamOutput)
+	require.NoError(t, json.Unmarshal([]byte(envelope), output))
+
+	res := stream.NewResult(output)
+	assert.Nil(t, res.ValidatingResponse())
+	assert.Nil(t, res.MutatingResponse())
+
+	objects := bytes.NewBuffer(nil)
+	require.NoError(t, res.ObjectPatchCollector().WriteOutput(objects))
+	assert.Equal(t, "{\"operation\":\"Delete\"}\n{\"operation\":\"Create\"}\n", objects.String())
+
+	configValues := bytes.NewBuffer(nil)
+	require.NoError(t, res.ValuesPatchCollector(utils.ConfigMapPatch).WriteOutput(configValues))
+	assert.Empty(t, configValues.String())
+
+	// the result sent by the stream transport is the same envelope
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, stream.NewTransport(nil, buf, nil, log.NewNop()).NewResponse().Send(res, nil))
+	assert.JSONEq(t, envelope, buf.String())
+}
@@ -0,0 +1,178 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/deckhouse/module-sdk/pkg/utils/patch"
+)
+
+// =============================================================================
+// Validating Webhook Configuration
+// =============================================================================
+
+// ValidatingConfig defines a validating admission webhook served by the hook.
+// Shell-operator registers the ValidatingWebhookConfiguration and runs the hook
+// on every admission request matching Rules.
+type ValidatingConfig struct {
+	// Name is a fully qualified name of the webhook, e.g. "policy.example-module.deckhouse.io".
+	Name string
+	// Rules describe operations and resources to validate.
+	Rules []admissionregv1.RuleWithOperations
+	// FailurePolicy is "Fail" by default.
+	FailurePolicy *admissionregv1.FailurePolicyType
+	// SideEffects is "None" by default.
+	SideEffects *admissionregv1.SideEffectClass
+	// TimeoutSeconds is 10 by default, must be between 1 and 30.
+	TimeoutSeconds *int32
+	// MatchConditions are CEL expressions to filter requests before they are sent to the hook.
+	MatchConditions []admissionregv1.MatchCondition
+	// NamespaceSelector used to validate objects in namespaces. Only LabelSelector is supported.
+	NamespaceSelector *NamespaceSelector
+	// LabelSelector used to validate objects by matching their labels.
+	LabelSelector *metav1.LabelSelector
+
+	// IncludeSnapshotsFrom is a list of kubernetes bindings which snapshots are passed to the hook.
+	IncludeSnapshotsFrom []string
+	// Group combines bindings with the same group name into a single hook run
+	// with snapshots of all group bindings.
+	Group string
+}
+
+// Validate checks the ValidatingConfig for errors.
+func (cfg *ValidatingConfig) Validate() error {
+	var errs error
+
+	if len(strings.Split(cfg.Name, ".")) < 3 {
+		errs = errors.Join(errs, errors.New("name should be a domain with at least three segments separated by dots"))
+	}
+
+	if len(cfg.Rules) == 0 {
+		errs = errors.Join(errs, errors.New("rules are empty"))
+	}
+
+	if cfg.TimeoutSeconds != nil && (*cfg.TimeoutSeconds < 1 || *cfg.TimeoutSeconds > 30) {
+		errs = errors.Join(errs, fmt.Errorf("timeoutSeconds should be between 1 and 30, got %d", *cfg.TimeoutSeconds))
+	}
+
+	if cfg.NamespaceSelector != nil && cfg.NamespaceSelector.NameSelector != nil {
+		errs = errors.Join(errs, errors.New("namespace name selector is not supported for admission webhooks, use labelSelector"))
+	}
+
+	if cfg.NamespaceSelector != nil {
+		if err := validateLabelSelector("namespace labelSelector", cfg.NamespaceSelector.LabelSelector); err != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+
+	if err := validateLabelSelector("labelSelector", cfg.LabelSelector); err != nil {
+		errs = errors.Join(errs, err)
+	}
+
+	return errs
+}
+
+// =============================================================================
+// Validating Review
+// =============================================================================
+
+// ValidatingResponse is a result of the validating webhook hook.
+// It is sent to the API server as a part of the AdmissionReview response.
+type ValidatingResponse struct {
+	Allowed  bool     `json:"allowed" yaml:"allowed"`
+	Message  string   `json:"message,omitempty" yaml:"message,omitempty"`
+	Warnings []string `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+}
+
+// ValidatingReview provides the admission request to the hook and collects its response.
+// Request is allowed if the hook returns without calling Deny.
+type ValidatingReview interface {
+	// Request returns the admission request sent by the API server.
+	Request() *admissionv1.AdmissionRequest
+	// Allow admits the request, warnings are shown to the user.
+	Allow(warnings ...string)
+	// Deny rejects the request with a message shown to the user.
+	Deny(message string, warnings ...string)
+	// Response returns the current response.
+	Response() ValidatingResponse
+}
+
+// =============================================================================
+// Mutating Webhook Configuration
+// =============================================================================
+
+// MutatingConfig defines a mutating admission webhook served by the hook.
+// Shell-operator registers the MutatingWebhookConfiguration and runs the hook
+// on every admission request matching Rules.
+type MutatingConfig struct {
+	// Name is a fully qualified name of the webhook, e.g. "defaults.example-module.deckhouse.io".
+	Name string
+	// Rules describe operations and resources to mutate.
+	Rules []admissionregv1.RuleWithOperations
+	// FailurePolicy is "Fail" by default.
+	FailurePolicy *admissionregv1.FailurePolicyType
+	// SideEffects is "None" by default.
+	SideEffects *admissionregv1.SideEffectClass
+	// TimeoutSeconds is 10 by default, must be between 1 and 30.
+	TimeoutSeconds *int32
+	// MatchConditions are CEL expressions to filter requests before they are sent to the hook.
+	MatchConditions []admissionregv1.MatchCondition
+	// NamespaceSelector used to mutate objects in namespaces. Only LabelSelector is supported.
+	NamespaceSelector *NamespaceSelector
+	// LabelSelector used to mutate objects by matching their labels.
+	LabelSelector *metav1.LabelSelector
+
+	// IncludeSnapshotsFrom is a list of kubernetes bindings which snapshots are passed to the hook.
+	IncludeSnapshotsFrom []string
+	// Group combines bindings with the same group name into a single hook run
+	// with snapshots of all group bindings.
+	Group string
+}
+
+// Validate checks the MutatingConfig for errors.
+func (cfg *MutatingConfig) Validate() error {
+	// both webhooks have the same restrictions
+	v := ValidatingConfig(*cfg)
+
+	return v.Validate()
+}
+
+// =============================================================================
+// Mutating Review
+// =============================================================================
+
+// MutatingResponse is a result of the mutating webhook hook.
+// Patch is a JSON patch for the admitted object, it is base64 encoded in JSON.
+type MutatingResponse struct {
+	Allowed  bool     `json:"allowed" yaml:"allowed"`
+	Message  string   `json:"message,omitempty" yaml:"message,omitempty"`
+	Warnings []string `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+	Patch    []byte   `json:"patch,omitempty" yaml:"patch,omitempty"`
+}
+
+// ErrNoAdmissionObject is returned by MutatingReview.Mutate for requests without the object, e.g. DELETE requests.
+var ErrNoAdmissionObject = errors.New("admission request has no object to mutate")
+
+// MutatingReview provides the admission request to the hook and collects its response.
+// Request is allowed without changes if the hook returns without calling Patch, Mutate or Deny.
+type MutatingReview interface {
+	// Request returns the admission request sent by the API server.
+	Request() *admissionv1.AdmissionRequest
+	// Patch admits the request with the JSON patch applied to the object.
+	Patch(p patch.Patch, warnings ...string) error
+	// Mutate admits the request with the object replaced by the mutated one.
+	// JSON patch is calculated as a difference between the requested object and obj.
+	// It fails with ErrNoAdmissionObject for requests without the object, e.g. DELETE, use Allow or Deny for them.
+	Mutate(obj any, warnings ...string) error
+	// Allow admits the request without changes.
+	Allow(warnings ...string)
+	// Deny rejects the request with a message shown to the user.
+	Deny(message string, warnings ...string)
+	// Response returns the current response.
+	Response() MutatingResponse
+}
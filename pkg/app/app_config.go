@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/caarlos0/env/v11"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/deckhouse/module-sdk/internal/controller"
 	"github.com/deckhouse/module-sdk/pkg"
 	"github.com/deckhouse/module-sdk/pkg/settingscheck"
+	"github.com/deckhouse/module-sdk/pkg/utils"
 )
 
 type hookConfig struct {
@@ -26,6 +28,12 @@ type hookConfig struct {
 	ValuesJSONPath       string `env:"VALUES_JSON_PATCH_PATH" envDefault:"out/values.json"`
 	ConfigValuesJSONPath string `env:"CONFIG_VALUES_JSON_PATCH_PATH" envDefault:"out/config_values.json"`
 
+	ValidatingResponsePath string `env:"VALIDATING_RESPONSE_PATH" envDefault:"out/validating_response.json"`
+	AdmissionResponsePath  string `env:"ADMISSION_RESPONSE_PATH" envDefault:"out/admission_response.json"`
+	ConversionResponsePath string `env:"CONVERSION_RESPONSE_PATH" envDefault:"out/conversion_response.json"`
+
+	OutputManifestPath string `env:"OUTPUT_MANIFEST_PATH"`
+
 	CreateFilesByYourself bool `env:"CREATE_FILES" envDefault:"false"`
 }
 
@@ -46,9 +54,23 @@ type config struct {
 	HookConfig      *hookConfig
 	ReadinessConfig *readinessConfig `envPrefix:"READINESS_"`
 	SettingsCheck   settingscheck.Check
+	Middlewares     []pkg.Middleware
+
+	DisableExecutionMetrics bool
+	DefaultTimeout          time.Duration `env:"HOOK_DEFAULT_TIMEOUT"`
+	OpenAPIDir              string
+	// Transport is "file" or "stream", see controller.TransportFile and controller.TransportStream
+	Transport string `env:"HOOK_TRANSPORT" envDefault:"file"`
+	// ServerSocket is the socket of `hooks serve`, hook runs are forwarded to the server if the socket exists
+	ServerSocket string `env:"HOOK_SERVER_SOCKET"`
+	// RecordDir is the directory hook runs are recorded into, each run into its own tarball, see `hooks replay`
+	RecordDir string `env:"HOOK_RECORD_DIR"`
 
 	LogLevelRaw string    `env:"LOG_LEVEL" envDefault:"FATAL"`
 	LogLevel    log.Level `env:"-"`
+
+	// ModuleNameSet is true if MODULE_NAME is set explicitly, values patches are restricted to the module values key then
+	ModuleNameSet bool `env:"-"`
 }
 
 func newConfig() *config {
@@ -60,6 +82,11 @@ func newConfig() *config {
 func (cfg *config) Parse() error {
 	opts := env.Options{
 		Prefix: "",
+		OnSet: func(tag string, _ any, isDefault bool) {
+			if tag == "MODULE_NAME" && !isDefault {
+				cfg.ModuleNameSet = true
+			}
+		},
 	}
 
 	err := env.ParseWithOptions(cfg, opts)
@@ -69,6 +96,10 @@ func (cfg *config) Parse() error {
 
 	cfg.LogLevel = log.LogLevelFromStr(cfg.LogLevelRaw)
 
+	if err := controller.ValidateTransport(cfg.Transport); err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+
 	return nil
 }
 
@@ -76,21 +107,35 @@ func remapConfigToControllerConfig(input *config) *controller.Config {
 	cfg := &controller.Config{
 		ModuleName: input.ModuleName,
 		HookConfig: &controller.HookConfig{
-			BindingContextPath:    input.HookConfig.BindingContextPath,
-			ValuesPath:            input.HookConfig.ValuesPath,
-			ConfigValuesPath:      input.HookConfig.ConfigValuesPath,
-			HookConfigPath:        input.HookConfig.HookConfigPath,
-			MetricsPath:           input.HookConfig.MetricsPath,
-			KubernetesPath:        input.HookConfig.KubernetesPath,
-			ValuesJSONPath:        input.HookConfig.ValuesJSONPath,
-			ConfigValuesJSONPath:  input.HookConfig.ConfigValuesJSONPath,
-			CreateFilesByYourself: input.HookConfig.CreateFilesByYourself,
+			BindingContextPath:     input.HookConfig.BindingContextPath,
+			ValuesPath:             input.HookConfig.ValuesPath,
+			ConfigValuesPath:       input.HookConfig.ConfigValuesPath,
+			HookConfigPath:         input.HookConfig.HookConfigPath,
+			MetricsPath:            input.HookConfig.MetricsPath,
+			KubernetesPath:         input.HookConfig.KubernetesPath,
+			ValuesJSONPath:         input.HookConfig.ValuesJSONPath,
+			ConfigValuesJSONPath:   input.HookConfig.ConfigValuesJSONPath,
+			ValidatingResponsePath: input.HookConfig.ValidatingResponsePath,
+			AdmissionResponsePath:  input.HookConfig.AdmissionResponsePath,
+			ConversionResponsePath: input.HookConfig.ConversionResponsePath,
+			OutputManifestPath:     input.HookConfig.OutputManifestPath,
+			CreateFilesByYourself:  input.HookConfig.CreateFilesByYourself,
 		},
+		Middlewares:             input.Middlewares,
+		DisableExecutionMetrics: input.DisableExecutionMetrics,
+		DefaultTimeout:          input.DefaultTimeout,
+		Transport:               input.Transport,
+		ServerSocket:            input.ServerSocket,
+		RecordDir:               input.RecordDir,
 
 		LogLevelRaw: input.LogLevelRaw,
 		LogLevel:    input.LogLevel,
 	}
 
+	if input.ModuleNameSet {
+		cfg.ValuesRootKey = utils.ModuleNameToValuesKey(input.ModuleName)
+	}
+
 	if input.ReadinessConfig != nil {
 		cfg.ReadinessConfig = &controller.ReadinessConfig{
 			ModuleName:        input.ModuleName,
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Config_DefaultTimeout(t *testing.T) {
+	t.Run("no timeout by default", func(t *testing.T) {
+		cfg := newConfig()
+		require.NoError(t, cfg.Parse())
+		assert.Zero(t, cfg.DefaultTimeout)
+	})
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("HOOK_DEFAULT_TIMEOUT", "10m")
+
+		cfg := newConfig()
+		require.NoError(t, cfg.Parse())
+		assert.Equal(t, 10*time.Minute, cfg.DefaultTimeout)
+	})
+}
+
+func Test_Config_ValuesRootKey(t *testing.T) {
+	t.Run("module name is not set", func(t *testing.T) {
+		cfg := newConfig()
+		require.NoError(t, cfg.Parse())
+
+		controllerCfg := remapConfigToControllerConfig(cfg)
+		assert.Equal(t, "default-module", controllerCfg.ModuleName)
+		assert.Empty(t, controllerCfg.ValuesRootKey)
+	})
+
+	t.Run("module name is set", func(t *testing.T) {
+		t.Setenv("MODULE_NAME", "my-module")
+
+		cfg := newConfig()
+		require.NoError(t, cfg.Parse())
+
+		controllerCfg := remapConfigToControllerConfig(cfg)
+		assert.Equal(t, "myModule", controllerCfg.ValuesRootKey)
+	})
+}
@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"time"
 
 	"github.com/deckhouse/module-sdk/pkg"
 	"github.com/deckhouse/module-sdk/pkg/settingscheck"
@@ -39,3 +40,37 @@ func WithSettingsCheck(check settingscheck.Check) RunConfigOption {
 		c.SettingsCheck = check
 	}
 }
+
+// WithMiddlewares wraps every hook with the middlewares, the first one is the outermost.
+// Global middlewares run outside of middlewares registered with a hook.
+func WithMiddlewares(middlewares ...pkg.Middleware) RunConfigOption {
+	return func(c *config) {
+		c.Middlewares = append(c.Middlewares, middlewares...)
+	}
+}
+
+// WithoutExecutionMetrics disables metrics emitted by the SDK for every hook run:
+// duration, success and failure counters, emitted patches and snapshot sizes.
+func WithoutExecutionMetrics() RunConfigOption {
+	return func(c *config) {
+		c.DisableExecutionMetrics = true
+	}
+}
+
+// WithDefaultTimeout sets the timeout of hooks without pkg.HookConfig.Timeout.
+// Zero (the default) disables the timeout.
+func WithDefaultTimeout(timeout time.Duration) RunConfigOption {
+	return func(c *config) {
+		c.DefaultTimeout = timeout
+	}
+}
+
+// WithOpenAPIValidation validates values and config values patched by module hooks
+// against the module schemas in dir (usually "openapi"): values.yaml, with x-extend
+// resolved, and config-values.yaml. A hook setting invalid values fails with
+// pkg.ErrorCodeConfiguration, so the values are not passed to Helm.
+func WithOpenAPIValidation(dir string) RunConfigOption {
+	return func(c *config) {
+		c.OpenAPIDir = dir
+	}
+}
@@ -1,12 +1,18 @@
 package app
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
 
 	"github.com/deckhouse/deckhouse/pkg/log"
 
@@ -23,6 +29,12 @@ func newCMD(controller *controller.HookController, logger *log.Logger) *cmd {
 type cmd struct {
 	controller *controller.HookController
 	logger     *log.Logger
+
+	// transport is the transport from the config, overridden by the --transport flag
+	transport string
+	// newController creates the controller if it is not set, after the logger output is chosen,
+	// as loggers derived by the controller do not follow changes of the logger output
+	newController func() *controller.HookController
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -30,13 +42,38 @@ type cmd struct {
 func (c *cmd) Execute() {
 	rootCmd := c.buildCommand()
 
-	err := rootCmd.Execute()
+	ctx, stop := notifyContext(context.Background())
+	defer stop()
+
+	err := rootCmd.ExecuteContext(ctx)
 	if err != nil {
 		c.logger.Error("failed to execute root command", "error", err)
 		os.Exit(1)
 	}
 }
 
+// notifyContext returns a context cancelled on SIGTERM or SIGINT, so hooks can stop gracefully.
+// The received signal is the cause of the context cancellation.
+func notifyContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+
+	go func() {
+		select {
+		case sig := <-signals:
+			cancel(fmt.Errorf("received signal %s", sig))
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel(nil)
+	}
+}
+
 // buildCommand creates the complete command structure with all subcommands
 func (c *cmd) buildCommand() *cobra.Command {
 	rootCmd := &cobra.Command{
@@ -61,22 +98,79 @@ func (c *cmd) hooksCmd() *cobra.Command {
 		Aliases: []string{"hook"},
 		Short:   "Working with hooks",
 		Long:    `Command for working with nested hooks`,
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			transport := c.transport
+			if cmd.Flags().Changed("transport") {
+				transport, _ = cmd.Flags().GetString("transport")
+			}
+
+			if err := controller.ValidateTransport(transport); err != nil {
+				c.logger.Error("invalid transport", "error", err)
+
+				return err
+			}
+
+			// stdout is the output of the stream transport
+			if transport == controller.TransportStream {
+				c.logger.SetOutput(os.Stderr)
+			}
+
+			if c.controller == nil {
+				c.controller = c.newController()
+			}
+
+			if cmd.Flags().Changed("transport") {
+				// the transport is validated above
+				_ = c.controller.SetTransport(transport)
+			}
+
+			return nil
+		},
 	}
+	hooksCmd.PersistentFlags().String("transport", controller.TransportFile,
+		`hook input and output transport: "file" or "stream" (stdin/stdout), overrides HOOK_TRANSPORT`)
 
-	hooksCmd.AddCommand(&cobra.Command{
+	var listOutput string
+
+	listCmd := &cobra.Command{
 		Use:   "list",
 		Short: "Listing hooks",
 		Long:  `Get list of hooks from binary registry`,
-		Run: func(_ *cobra.Command, _ []string) {
-			hmetas := c.controller.ListHooksMeta()
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			hooks := c.controller.ListHooks()
+
+			switch listOutput {
+			case "":
+				fmt.Fprintf(cmd.OutOrStdout(), "Found %d items:\n", len(hooks))
+
+				for _, hook := range hooks {
+					fmt.Fprintf(cmd.OutOrStdout(), "%d - %s\n", hook.Index, hook.Name)
+				}
+			case "json":
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
 
-			fmt.Printf("Found %d items:\n", len(hmetas))
+				if err := enc.Encode(hooks); err != nil {
+					return fmt.Errorf("json encode: %w", err)
+				}
+			case "yaml":
+				data, err := yaml.Marshal(hooks)
+				if err != nil {
+					return fmt.Errorf("yaml encode: %w", err)
+				}
 
-			for idx, meta := range hmetas {
-				fmt.Printf("%d - %s\n", idx, meta.Name)
+				_, _ = cmd.OutOrStdout().Write(data)
+			default:
+				c.logger.Error("unknown output format", "output", listOutput)
+
+				return fmt.Errorf("unknown output format %q, expected json or yaml", listOutput)
 			}
+
+			return nil
 		},
-	})
+	}
+	listCmd.Flags().StringVarP(&listOutput, "output", "o", "", "output format: json or yaml, a plain list by default")
+	hooksCmd.AddCommand(listCmd)
 
 	hooksCmd.AddCommand(&cobra.Command{
 		Use:    "check",
@@ -128,10 +222,13 @@ func (c *cmd) hooksCmd() *cobra.Command {
 	}
 	hooksCmd.AddCommand(dumpCmd)
 
+	var recordPath string
+
 	runCmd := &cobra.Command{
-		Use:    "run",
-		Short:  "Running hook",
-		Long:   `Run hook from binary registry`,
+		Use:   "run <index|name|path>",
+		Short: "Running hook",
+		Long: `Run hook from binary registry by index, name or path.
+The index depends on the registration order, see "hooks list"`,
 		Hidden: true,
 		Args: func(_ *cobra.Command, args []string) error {
 			if len(args) != 1 {
@@ -144,15 +241,18 @@ func (c *cmd) hooksCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 
-			idxRaw := args[0]
-			idx, err := strconv.Atoi(idxRaw)
-			if err != nil {
-				c.logger.Error("invalid argument", "argument", idxRaw, "error", err)
+			if cmd.Flags().Changed("record") {
+				c.controller.SetRecordPath(recordPath)
+			}
+
+			var err error
 
-				return fmt.Errorf("invalid argument: %w", err)
+			if idx, atoiErr := strconv.Atoi(args[0]); atoiErr == nil {
+				err = c.controller.RunHook(ctx, idx)
+			} else {
+				err = c.controller.RunHookByName(ctx, args[0])
 			}
 
-			err = c.controller.RunHook(ctx, idx)
 			if err != nil {
 				c.logger.Warn("hook shutdown", "error", err)
 				return fmt.Errorf("hook shutdown: %w", err)
@@ -161,8 +261,64 @@ func (c *cmd) hooksCmd() *cobra.Command {
 			return nil
 		},
 	}
+	runCmd.Flags().StringVar(&recordPath, "record", "",
+		"record inputs, outputs and interactions of the run into the directory or the tarball (.tar.gz), overrides HOOK_RECORD_DIR. "+
+			"The recording contains secrets of values, snapshots and HTTP requests")
 	hooksCmd.AddCommand(runCmd)
 
+	replayCmd := &cobra.Command{
+		Use:   "replay <path>",
+		Short: "Replay recorded hook run",
+		Long: `Run the hook recorded by "hooks run --record" with the recorded inputs
+and HTTP and registry responses, and compare outputs with the recorded ones`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := c.controller.Replay(cmd.Context(), args[0], cmd.OutOrStdout())
+			if err != nil {
+				c.logger.Error("replay failed", "error", err)
+				return fmt.Errorf("replay: %w", err)
+			}
+
+			return nil
+		},
+	}
+	hooksCmd.AddCommand(replayCmd)
+
+	var (
+		socket          string
+		shutdownTimeout time.Duration
+	)
+
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve hooks",
+		Long: `Run hooks on requests to the Unix socket API, reusing clients between runs.
+"hooks run" and "hooks ready" forward runs to the server if HOOK_SERVER_SOCKET exists`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if !cmd.Flags().Changed("socket") {
+				socket = c.controller.ServerSocket()
+			}
+
+			if socket == "" {
+				c.logger.Error("socket is not set", "flag", "--socket", "env", "HOOK_SERVER_SOCKET")
+
+				return fmt.Errorf("socket is not set")
+			}
+
+			err := c.controller.Serve(cmd.Context(), socket, shutdownTimeout)
+			if err != nil {
+				c.logger.Error("hook server failed", "error", err)
+
+				return fmt.Errorf("serve: %w", err)
+			}
+
+			return nil
+		},
+	}
+	serveCmd.Flags().StringVar(&socket, "socket", "", "path to the Unix socket, HOOK_SERVER_SOCKET by default")
+	serveCmd.Flags().DurationVar(&shutdownTimeout, "shutdown-timeout", controller.DefaultServerShutdownTimeout, "time given to running hooks to finish on shutdown")
+	hooksCmd.AddCommand(serveCmd)
+
 	readyCmd := &cobra.Command{
 		Use:    "ready",
 		Short:  "Check readiness",
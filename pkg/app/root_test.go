@@ -2,8 +2,12 @@ package app
 
 import (
 	"bytes"
+	"context"
+	"os"
 	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -34,6 +38,11 @@ func Test_HooksRun_NoErrorOrUsageOutput(t *testing.T) {
 			args:        []string{"hooks", "run", "999"},
 			description: "should not output Error or Usage when hook index is invalid",
 		},
+		{
+			name:        "unknown transport",
+			args:        []string{"hooks", "run", "0", "--transport", "socket"},
+			description: "should not output Error or Usage when transport is unknown",
+		},
 	}
 
 	for _, tt := range tests {
@@ -153,3 +162,98 @@ func Test_HooksConfig_NoErrorOrUsageOutput(t *testing.T) {
 		require.True(t, strings.HasPrefix(strings.TrimSpace(stdout.String()), "{"), "config command should output valid JSON")
 	}
 }
+
+func Test_NotifyContext(t *testing.T) {
+	ctx, stop := notifyContext(context.Background())
+	defer stop()
+
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("context is not cancelled on SIGTERM")
+	}
+
+	assert.EqualError(t, context.Cause(ctx), "received signal terminated")
+}
+
+func Test_HooksList_Output(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+		err      string
+	}{
+		{args: []string{"hooks", "list", "-o", "json"}, expected: "[]\n"},
+		{args: []string{"hooks", "list", "--output", "yaml"}, expected: "[]\n"},
+		{args: []string{"hooks", "list", "-o", "table"}, err: `unknown output format "table", expected json or yaml`},
+	}
+
+	for _, tt := range tests {
+		var stdout bytes.Buffer
+
+		logger := log.NewNop()
+		hookController := controller.NewHookController(&controller.Config{HookConfig: &controller.HookConfig{}}, logger)
+
+		rootCmd := newCMD(hookController, logger).buildCommand()
+		rootCmd.SetOut(&stdout)
+		rootCmd.SetArgs(tt.args)
+
+		err := rootCmd.Execute()
+		if tt.err != "" {
+			assert.EqualError(t, err, tt.err, tt.args)
+			continue
+		}
+
+		assert.NoError(t, err, tt.args)
+		assert.Equal(t, tt.expected, stdout.String(), tt.args)
+	}
+}
+
+func Test_Hooks_Transport(t *testing.T) {
+	tests := []struct {
+		transport string
+		args      []string
+		err       string
+		stream    bool
+	}{
+		{args: []string{"hooks", "list"}},
+		{transport: controller.TransportStream, args: []string{"hooks", "list"}, stream: true},
+		{args: []string{"hooks", "list", "--transport", "stream"}, stream: true},
+		{transport: controller.TransportStream, args: []string{"hooks", "list", "--transport", "file"}},
+		{args: []string{"hooks", "list", "--transport=bogus"}, err: `unknown transport "bogus"`},
+	}
+
+	for _, tt := range tests {
+		var logBuf bytes.Buffer
+
+		logger := log.NewLogger(log.WithOutput(&logBuf))
+
+		created := false
+		c := newCMD(nil, logger)
+		c.transport = tt.transport
+		c.newController = func() *controller.HookController {
+			created = true
+
+			return controller.NewHookController(&controller.Config{HookConfig: &controller.HookConfig{}}, logger)
+		}
+
+		rootCmd := c.buildCommand()
+		rootCmd.SetOut(&bytes.Buffer{})
+		rootCmd.SetArgs(tt.args)
+
+		err := rootCmd.Execute()
+		if tt.err != "" {
+			assert.ErrorContains(t, err, tt.err, tt.args)
+			assert.False(t, created, "controller must not be created on invalid transport: %v", tt.args)
+
+			continue
+		}
+
+		require.NoError(t, err, tt.args)
+		assert.True(t, created, tt.args)
+
+		logger.Info("probe")
+		assert.Equal(t, !tt.stream, strings.Contains(logBuf.String(), "probe"), tt.args)
+	}
+}
@@ -1,12 +1,16 @@
 package app
 
 import (
+	"fmt"
 	"log/slog"
 	"runtime/debug"
 
 	"github.com/deckhouse/deckhouse/pkg/log"
 
 	"github.com/deckhouse/module-sdk/internal/controller"
+	"github.com/deckhouse/module-sdk/internal/openapi"
+	"github.com/deckhouse/module-sdk/pkg/registry"
+	"github.com/deckhouse/module-sdk/pkg/utils"
 )
 
 func Run(opts ...RunConfigOption) {
@@ -34,9 +38,27 @@ func Run(opts ...RunConfigOption) {
 		opt(cfg)
 	}
 
-	controller := controller.NewHookController(remapConfigToControllerConfig(cfg), logger.Named("hook-controller").With("module", cfg.ModuleName))
+	// RunAfter and RunBefore dependencies are known only when all hooks are registered
+	if err := registry.Registry().ResolveOrder(); err != nil {
+		panic(fmt.Errorf("resolve hooks order: %w", err))
+	}
+
+	controllerCfg := remapConfigToControllerConfig(cfg)
+
+	if cfg.OpenAPIDir != "" {
+		validator, err := openapi.NewValidator(cfg.OpenAPIDir, utils.ModuleNameToValuesKey(cfg.ModuleName))
+		if err != nil {
+			panic(fmt.Errorf("load openapi schemas: %w", err))
+		}
 
-	c := newCMD(controller, logger)
+		controllerCfg.ValuesValidator = validator
+	}
+
+	c := newCMD(nil, logger)
+	c.transport = cfg.Transport
+	c.newController = func() *controller.HookController {
+		return controller.NewHookController(controllerCfg, logger.Named("hook-controller").With("module", cfg.ModuleName))
+	}
 
 	c.Execute()
 }
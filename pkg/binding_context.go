@@ -0,0 +1,130 @@
+package pkg
+
+// BindingType is a kind of binding which triggered the hook.
+type BindingType string
+
+const (
+	BindingTypeSchedule             BindingType = "schedule"
+	BindingTypeOnStartup            BindingType = "onStartup"
+	BindingTypeKubernetes           BindingType = "kubernetes"
+	BindingTypeKubernetesConversion BindingType = "kubernetesCustomResourceConversion"
+	BindingTypeKubernetesValidating BindingType = "kubernetesValidating"
+	BindingTypeKubernetesMutating   BindingType = "kubernetesMutating"
+)
+
+// KubeEventType is a type of binding context.
+type KubeEventType string
+
+const (
+	KubeEventTypeSynchronization KubeEventType = "Synchronization"
+	KubeEventTypeEvent           KubeEventType = "Event"
+	KubeEventTypeSchedule        KubeEventType = "Schedule"
+	KubeEventTypeGroup           KubeEventType = "Group"
+	KubeEventTypeValidating      KubeEventType = "Validating"
+	KubeEventTypeMutating        KubeEventType = "Mutating"
+	KubeEventTypeConversion      KubeEventType = "Conversion"
+)
+
+// WatchEventType is a type of Kubernetes watch event.
+type WatchEventType string
+
+const (
+	WatchEventAdded    WatchEventType = "Added"
+	WatchEventModified WatchEventType = "Modified"
+	WatchEventDeleted  WatchEventType = "Deleted"
+)
+
+// BindingContext describes a single reason of the hook execution.
+// Hook receives one or more binding contexts per run, in order of their arrival.
+type BindingContext struct {
+	// Binding is a name of the binding or group which triggered the hook.
+	Binding string
+	// BindingType is a kind of the binding (schedule, kubernetes, etc.).
+	BindingType BindingType
+	// Type is a type of the context: Synchronization, Event, Schedule, Group, Validating, Mutating or Conversion.
+	Type KubeEventType
+	// Group is a name of the group, if binding belongs to one.
+	Group string
+
+	// WatchEvent is set for "Event" contexts only.
+	WatchEvent WatchEventType
+	// Object is a changed object, set for "Event" contexts only.
+	Object Snapshot
+	// FilterResult is a jq filter result of the changed object, set for "Event" contexts only.
+	FilterResult Snapshot
+
+	// Objects is a list of existing objects, set for "Synchronization" contexts only.
+	Objects []ObjectAndFilterResult
+}
+
+// ObjectAndFilterResult is an object with its jq filter result.
+// Object can be nil if KeepFullObjectsInMemory is disabled for binding.
+type ObjectAndFilterResult struct {
+	Object       Snapshot
+	FilterResult Snapshot
+}
+
+// IsSynchronization returns true if context contains the full list of objects for the kubernetes binding.
+func (bc *BindingContext) IsSynchronization() bool {
+	return bc.Type == KubeEventTypeSynchronization
+}
+
+// IsEvent returns true if context describes a single kubernetes watch event.
+func (bc *BindingContext) IsEvent() bool {
+	return bc.Type == KubeEventTypeEvent
+}
+
+// IsGroup returns true if context is triggered by a group of bindings.
+func (bc *BindingContext) IsGroup() bool {
+	return bc.Type == KubeEventTypeGroup
+}
+
+// IsValidating returns true if context is triggered by a validating admission webhook.
+func (bc *BindingContext) IsValidating() bool {
+	return bc.Type == KubeEventTypeValidating
+}
+
+// IsMutating returns true if context is triggered by a mutating admission webhook.
+func (bc *BindingContext) IsMutating() bool {
+	return bc.Type == KubeEventTypeMutating
+}
+
+// IsConversion returns true if context is triggered by a CRD conversion webhook.
+func (bc *BindingContext) IsConversion() bool {
+	return bc.Type == KubeEventTypeConversion
+}
+
+// IsSchedule returns true if context is triggered by a schedule binding.
+func (bc *BindingContext) IsSchedule() bool {
+	return bc.BindingType == BindingTypeSchedule || bc.Type == KubeEventTypeSchedule
+}
+
+// Snapshot returns a filter result of the changed object if it exists, otherwise the object itself.
+// It returns nil if context has no changed object.
+func (bc *BindingContext) Snapshot() Snapshot {
+	if bc.FilterResult != nil {
+		return bc.FilterResult
+	}
+
+	return bc.Object
+}
+
+// Snapshot returns a filter result if it exists, otherwise the object itself.
+func (o *ObjectAndFilterResult) Snapshot() Snapshot {
+	if o.FilterResult != nil {
+		return o.FilterResult
+	}
+
+	return o.Object
+}
+
+// triggeredGroup returns the name of the first group found in binding contexts.
+func triggeredGroup(bcs []BindingContext) string {
+	for _, bc := range bcs {
+		if bc.Group != "" {
+			return bc.Group
+		}
+	}
+
+	return ""
+}
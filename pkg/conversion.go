@@ -0,0 +1,92 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// =============================================================================
+// Conversion Webhook Configuration
+// =============================================================================
+
+// ConversionConfig defines a CRD conversion webhook served by the hook.
+// Shell-operator configures the conversion strategy of the CRD and runs the hook
+// every time the API server needs to convert objects between versions.
+type ConversionConfig struct {
+	Name string
+	// CRDName is a full name of the CRD, e.g. "widgets.example.deckhouse.io".
+	CRDName string
+	// Conversions is a list of version pairs the hook is able to convert.
+	Conversions []ConversionRule
+
+	// IncludeSnapshotsFrom is a list of kubernetes bindings which snapshots are passed to the hook.
+	IncludeSnapshotsFrom []string
+	// Group combines bindings with the same group name into a single hook run
+	// with snapshots of all group bindings.
+	Group string
+}
+
+// ConversionRule is a pair of versions supported by the conversion hook.
+type ConversionRule struct {
+	// FromVersion is a full apiVersion, e.g. "example.deckhouse.io/v1alpha1".
+	FromVersion string
+	// ToVersion is a full apiVersion, e.g. "example.deckhouse.io/v1".
+	ToVersion string
+}
+
+// Validate checks the ConversionConfig for errors.
+func (cfg *ConversionConfig) Validate() error {
+	var errs error
+
+	if len(strings.Split(cfg.CRDName, ".")) < 3 {
+		errs = errors.Join(errs, errors.New("crdName should be a full CRD name, e.g. 'widgets.example.deckhouse.io'"))
+	}
+
+	if len(cfg.Conversions) == 0 {
+		errs = errors.Join(errs, errors.New("conversions are empty"))
+	}
+
+	for _, rule := range cfg.Conversions {
+		if !strings.Contains(rule.FromVersion, "/") || !strings.Contains(rule.ToVersion, "/") {
+			errs = errors.Join(errs, fmt.Errorf("conversion '%s' -> '%s': versions should be in 'group/version' format", rule.FromVersion, rule.ToVersion))
+
+			continue
+		}
+
+		if rule.FromVersion == rule.ToVersion {
+			errs = errors.Join(errs, fmt.Errorf("conversion '%s' -> '%s': versions should differ", rule.FromVersion, rule.ToVersion))
+		}
+	}
+
+	return errs
+}
+
+// =============================================================================
+// Conversion Review
+// =============================================================================
+
+// ConversionResponse is a result of the conversion webhook hook.
+type ConversionResponse struct {
+	FailedMessage    string                       `json:"failedMessage,omitempty" yaml:"failedMessage,omitempty"`
+	ConvertedObjects []*unstructured.Unstructured `json:"convertedObjects,omitempty" yaml:"convertedObjects,omitempty"`
+}
+
+// ConversionReview provides objects to convert to the hook and collects converted ones.
+// Conversion fails if the hook returns without calling Respond.
+type ConversionReview interface {
+	// FromVersion returns the version from the triggered conversion rule.
+	FromVersion() string
+	// ToVersion returns the version objects should be converted to.
+	ToVersion() string
+	// Objects returns objects to convert, objects may have different versions.
+	Objects() []*unstructured.Unstructured
+	// Respond sets converted objects, in the same order as Objects.
+	Respond(objects ...*unstructured.Unstructured)
+	// Fail rejects the conversion with a message.
+	Fail(message string)
+	// Response returns the current response.
+	Response() ConversionResponse
+}
@@ -0,0 +1,195 @@
+package conversion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/deckhouse/module-sdk/pkg"
+)
+
+// Func converts a single object to the next version.
+// The returned object gets the target apiVersion automatically.
+type Func func(ctx context.Context, obj *unstructured.Unstructured) (*unstructured.Unstructured, error)
+
+var ErrNoConversionPath = errors.New("no conversion path")
+
+// Registry stores conversion functions between adjacent versions of a CRD
+// and chains them to convert objects between any connected versions,
+// e.g. v1alpha1 -> v1beta1 -> v1.
+//
+//	conversions := conversion.NewRegistry().
+//		Add("example.io/v1alpha1", "example.io/v1beta1", alphaToBeta).
+//		Add("example.io/v1beta1", "example.io/v1", betaToV1)
+//
+//	var _ = registry.RegisterFunc(&pkg.HookConfig{
+//		KubernetesConversion: []pkg.ConversionConfig{{
+//			Name:        "widgets",
+//			CRDName:     "widgets.example.io",
+//			Conversions: conversions.Rules(),
+//		}},
+//	}, conversions.Handle)
+type Registry struct {
+	// versions keep the order of addition to produce stable rules
+	versions []string
+	funcs    map[string]map[string]Func
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		funcs: make(map[string]map[string]Func),
+	}
+}
+
+// Add registers a conversion function between two versions in "group/version" format.
+// Panics if a conversion between the versions is already registered.
+func (r *Registry) Add(fromVersion, toVersion string, f Func) *Registry {
+	if fromVersion == toVersion {
+		panic(fmt.Sprintf("conversion from '%s' to itself", fromVersion))
+	}
+
+	if _, ok := r.funcs[fromVersion][toVersion]; ok {
+		panic(fmt.Sprintf("conversion from '%s' to '%s' is already registered", fromVersion, toVersion))
+	}
+
+	for _, version := range []string{fromVersion, toVersion} {
+		if !slices.Contains(r.versions, version) {
+			r.versions = append(r.versions, version)
+		}
+	}
+
+	if r.funcs[fromVersion] == nil {
+		r.funcs[fromVersion] = make(map[string]Func)
+	}
+
+	r.funcs[fromVersion][toVersion] = f
+
+	return r
+}
+
+// Rules returns all version pairs the registry can convert, including chained ones.
+// Use them as Conversions of the pkg.ConversionConfig.
+func (r *Registry) Rules() []pkg.ConversionRule {
+	rules := make([]pkg.ConversionRule, 0)
+
+	for _, from := range r.versions {
+		for _, to := range r.versions {
+			if from == to {
+				continue
+			}
+
+			if _, err := r.path(from, to); err != nil {
+				continue
+			}
+
+			rules = append(rules, pkg.ConversionRule{FromVersion: from, ToVersion: to})
+		}
+	}
+
+	return rules
+}
+
+// Convert converts the object to the desired version through the shortest chain of registered functions.
+// The object is returned as is if it already has the desired version.
+func (r *Registry) Convert(ctx context.Context, obj *unstructured.Unstructured, toVersion string) (*unstructured.Unstructured, error) {
+	path, err := r.path(obj.GetAPIVersion(), toVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := obj
+	for i := 1; i < len(path); i++ {
+		from, to := path[i-1], path[i]
+
+		converted, err = r.funcs[from][to](ctx, converted.DeepCopy())
+		if err != nil {
+			return nil, fmt.Errorf("convert '%s' from '%s' to '%s': %w", obj.GetName(), from, to, err)
+		}
+
+		if converted == nil {
+			return nil, fmt.Errorf("convert '%s' from '%s' to '%s': conversion returned nil object", obj.GetName(), from, to)
+		}
+
+		converted.SetAPIVersion(to)
+	}
+
+	return converted, nil
+}
+
+// Handle is a hook function which converts all objects of the conversion review.
+// Conversion errors are sent to the API server as a failed conversion response.
+func (r *Registry) Handle(ctx context.Context, input *pkg.HookInput) error {
+	if input.Conversion == nil {
+		return errors.New("hook is not triggered by a conversion binding")
+	}
+
+	objects := input.Conversion.Objects()
+	converted := make([]*unstructured.Unstructured, 0, len(objects))
+
+	for _, obj := range objects {
+		res, err := r.Convert(ctx, obj, input.Conversion.ToVersion())
+		if err != nil {
+			input.Conversion.Fail(err.Error())
+
+			return nil
+		}
+
+		converted = append(converted, res)
+	}
+
+	input.Conversion.Respond(converted...)
+
+	return nil
+}
+
+// path returns the shortest list of versions from one version to another, both included.
+func (r *Registry) path(fromVersion, toVersion string) ([]string, error) {
+	if fromVersion == toVersion {
+		return []string{fromVersion}, nil
+	}
+
+	previous := map[string]string{fromVersion: ""}
+	queue := []string{fromVersion}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, next := range r.sortedTargets(current) {
+			if _, visited := previous[next]; visited {
+				continue
+			}
+
+			previous[next] = current
+
+			if next != toVersion {
+				queue = append(queue, next)
+				continue
+			}
+
+			path := []string{toVersion}
+			for v := current; v != ""; v = previous[v] {
+				path = append([]string{v}, path...)
+			}
+
+			return path, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w from '%s' to '%s'", ErrNoConversionPath, fromVersion, toVersion)
+}
+
+// sortedTargets returns versions directly convertible from the version, in stable order.
+func (r *Registry) sortedTargets(version string) []string {
+	targets := make([]string, 0, len(r.funcs[version]))
+	for to := range r.funcs[version] {
+		targets = append(targets, to)
+	}
+
+	slices.Sort(targets)
+
+	return targets
+}
@@ -0,0 +1,147 @@
+package conversion_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	internalconversion "github.com/deckhouse/module-sdk/internal/conversion"
+	"github.com/deckhouse/module-sdk/pkg"
+	"github.com/deckhouse/module-sdk/pkg/conversion"
+)
+
+const (
+	v1alpha1 = "example.io/v1alpha1"
+	v1beta1  = "example.io/v1beta1"
+	v1       = "example.io/v1"
+)
+
+func newWidget(apiVersion, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind("Widget")
+	obj.SetName(name)
+
+	return obj
+}
+
+// appendStep records the passed conversion steps in spec.steps of the object.
+func appendStep(step string) conversion.Func {
+	return func(_ context.Context, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+		steps, _, _ := unstructured.NestedStringSlice(obj.Object, "spec", "steps")
+		if err := unstructured.SetNestedStringSlice(obj.Object, append(steps, step), "spec", "steps"); err != nil {
+			return nil, err
+		}
+
+		return obj, nil
+	}
+}
+
+func newChainedRegistry() *conversion.Registry {
+	return conversion.NewRegistry().
+		Add(v1alpha1, v1beta1, appendStep("alpha-to-beta")).
+		Add(v1beta1, v1, appendStep("beta-to-v1")).
+		Add(v1, v1beta1, appendStep("v1-to-beta"))
+}
+
+func TestRegistry_Convert(t *testing.T) {
+	reg := newChainedRegistry()
+
+	t.Run("chained conversion", func(t *testing.T) {
+		obj := newWidget(v1alpha1, "w-1")
+
+		converted, err := reg.Convert(context.Background(), obj, v1)
+		require.NoError(t, err)
+
+		assert.Equal(t, v1, converted.GetAPIVersion())
+		steps, _, _ := unstructured.NestedStringSlice(converted.Object, "spec", "steps")
+		assert.Equal(t, []string{"alpha-to-beta", "beta-to-v1"}, steps)
+
+		// source object is not modified
+		assert.Equal(t, v1alpha1, obj.GetAPIVersion())
+	})
+
+	t.Run("same version", func(t *testing.T) {
+		obj := newWidget(v1, "w-1")
+
+		converted, err := reg.Convert(context.Background(), obj, v1)
+		require.NoError(t, err)
+		assert.Same(t, obj, converted)
+	})
+
+	t.Run("no path", func(t *testing.T) {
+		_, err := reg.Convert(context.Background(), newWidget(v1, "w-1"), v1alpha1)
+		assert.ErrorIs(t, err, conversion.ErrNoConversionPath)
+	})
+
+	t.Run("conversion error", func(t *testing.T) {
+		reg := conversion.NewRegistry().
+			Add(v1alpha1, v1, func(_ context.Context, _ *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+				return nil, errors.New("spec.size is required")
+			})
+
+		_, err := reg.Convert(context.Background(), newWidget(v1alpha1, "w-1"), v1)
+		assert.EqualError(t, err, "convert 'w-1' from 'example.io/v1alpha1' to 'example.io/v1': spec.size is required")
+	})
+}
+
+func TestRegistry_Rules(t *testing.T) {
+	reg := newChainedRegistry()
+
+	assert.Equal(t, []pkg.ConversionRule{
+		{FromVersion: v1alpha1, ToVersion: v1beta1},
+		{FromVersion: v1alpha1, ToVersion: v1},
+		{FromVersion: v1beta1, ToVersion: v1},
+		{FromVersion: v1, ToVersion: v1beta1},
+	}, reg.Rules())
+}
+
+func TestRegistry_AddDuplicatePanics(t *testing.T) {
+	assert.Panics(t, func() {
+		conversion.NewRegistry().
+			Add(v1alpha1, v1, appendStep("first")).
+			Add(v1alpha1, v1, appendStep("second"))
+	})
+}
+
+func TestRegistry_Handle(t *testing.T) {
+	reg := newChainedRegistry()
+
+	t.Run("mixed versions are converted", func(t *testing.T) {
+		review := internalconversion.NewReview(v1alpha1, v1, []*unstructured.Unstructured{
+			newWidget(v1alpha1, "w-1"),
+			newWidget(v1beta1, "w-2"),
+			newWidget(v1, "w-3"),
+		})
+
+		require.NoError(t, reg.Handle(context.Background(), &pkg.HookInput{Conversion: review}))
+
+		res := review.Response()
+		assert.Empty(t, res.FailedMessage)
+		require.Len(t, res.ConvertedObjects, 3)
+		for i, name := range []string{"w-1", "w-2", "w-3"} {
+			assert.Equal(t, name, res.ConvertedObjects[i].GetName())
+			assert.Equal(t, v1, res.ConvertedObjects[i].GetAPIVersion())
+		}
+	})
+
+	t.Run("failed conversion", func(t *testing.T) {
+		review := internalconversion.NewReview(v1, v1alpha1, []*unstructured.Unstructured{
+			newWidget(v1, "w-1"),
+		})
+
+		require.NoError(t, reg.Handle(context.Background(), &pkg.HookInput{Conversion: review}))
+
+		res := review.Response()
+		assert.Contains(t, res.FailedMessage, "no conversion path")
+		assert.Empty(t, res.ConvertedObjects)
+	})
+
+	t.Run("not a conversion binding", func(t *testing.T) {
+		assert.Error(t, reg.Handle(context.Background(), &pkg.HookInput{}))
+	})
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2021 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dependency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/deckhouse/module-sdk/pkg"
+)
+
+var (
+	_ pkg.HTTPOptionApplier     = (*httpClientKey)(nil)
+	_ pkg.RegistryOptionApplier = (*registryClientKey)(nil)
+)
+
+// httpClientKey is the comparable form of HTTP client options, clients are cached by it.
+type httpClientKey struct {
+	timeout            time.Duration
+	insecureSkipVerify bool
+	// caCerts are SHA-256 checksums of additional CA certificates
+	caCerts       string
+	tlsServerName string
+}
+
+func newHTTPClientKey(options []pkg.HTTPOption) httpClientKey {
+	key := new(httpClientKey)
+	for _, opt := range options {
+		opt.Apply(key)
+	}
+
+	return *key
+}
+
+func (k *httpClientKey) WithTimeout(t time.Duration) {
+	k.timeout = t
+}
+
+func (k *httpClientKey) WithInsecureSkipVerify() {
+	k.insecureSkipVerify = true
+}
+
+func (k *httpClientKey) WithAdditionalCACerts(certs [][]byte) {
+	for _, cert := range certs {
+		k.caCerts += checksum(string(cert)) + ","
+	}
+}
+
+func (k *httpClientKey) WithTLSServerName(name string) {
+	k.tlsServerName = name
+}
+
+// registryClientKey is the comparable form of the registry client repository and options, clients are cached by it.
+type registryClientKey struct {
+	repo     string
+	ca       string
+	insecure bool
+	// auth is the SHA-256 checksum of the docker config, authSet distinguishes the empty config from no config
+	auth      string
+	authSet   bool
+	userAgent string
+	timeout   time.Duration
+}
+
+func newRegistryClientKey(repo string, options []pkg.RegistryOption) registryClientKey {
+	key := &registryClientKey{repo: repo}
+	for _, opt := range options {
+		opt.Apply(key)
+	}
+
+	return *key
+}
+
+func (k *registryClientKey) WithCA(ca string) {
+	k.ca = checksum(ca)
+}
+
+func (k *registryClientKey) WithInsecureSchema(insecure bool) {
+	k.insecure = insecure
+}
+
+func (k *registryClientKey) WithAuth(dockerCfg string) {
+	k.auth = checksum(dockerCfg)
+	k.authSet = true
+}
+
+func (k *registryClientKey) WithUserAgent(ua string) {
+	k.userAgent = ua
+}
+
+func (k *registryClientKey) WithTimeout(timeout time.Duration) {
+	k.timeout = timeout
+}
+
+func checksum(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
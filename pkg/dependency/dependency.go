@@ -41,27 +41,37 @@ func NewDependencyContainer() pkg.DependencyContainer {
 	return &dependencyContainer{}
 }
 
+// dependencyContainer caches clients, so they are reused by hook runs of the hook server.
+// HTTP and registry clients are cached by their options, so options of one hook are never applied
+// to clients of another. Kubernetes clients with options are created on every call, as options can not be compared.
 type dependencyContainer struct {
+	k8smu     sync.Mutex
 	k8sClient *k8s.Client
 
-	httpmu     sync.RWMutex
-	httpClient *stdhttp.Client
+	httpmu      sync.RWMutex
+	httpClients map[httpClientKey]*stdhttp.Client
 
-	crmu     sync.RWMutex
-	crClient *cr.Client
+	crmu      sync.RWMutex
+	crClients map[registryClientKey]*cr.Client
 }
 
 func (dc *dependencyContainer) GetHTTPClient(options ...pkg.HTTPOption) pkg.HTTPClient {
+	key := newHTTPClientKey(options)
+
 	dc.httpmu.RLock()
-	if dc.httpClient != nil {
+	if client, ok := dc.httpClients[key]; ok {
 		defer dc.httpmu.RUnlock()
-		return dc.httpClient
+		return client
 	}
 	dc.httpmu.RUnlock()
 
 	dc.httpmu.Lock()
 	defer dc.httpmu.Unlock()
 
+	if client, ok := dc.httpClients[key]; ok {
+		return client
+	}
+
 	var opts []pkg.HTTPOption
 	opts = append(opts, options...)
 
@@ -70,18 +80,35 @@ func (dc *dependencyContainer) GetHTTPClient(options ...pkg.HTTPOption) pkg.HTTP
 		opts = append(opts, httpclient.WithAdditionalCACerts([][]byte{contentCA}))
 	}
 
-	dc.httpClient = httpclient.NewClient(opts...)
+	client := httpclient.NewClient(opts...)
+
+	if dc.httpClients == nil {
+		dc.httpClients = make(map[httpClientKey]*stdhttp.Client)
+	}
+	dc.httpClients[key] = client
 
-	return dc.httpClient
+	return client
 }
 
 func (dc *dependencyContainer) GetK8sClient(options ...pkg.KubernetesOption) (pkg.KubernetesClient, error) {
-	if dc.k8sClient == nil {
+	if len(options) > 0 {
 		kc, err := k8s.NewClient(options...)
 		if err != nil {
 			return nil, err
 		}
 
+		return kc, nil
+	}
+
+	dc.k8smu.Lock()
+	defer dc.k8smu.Unlock()
+
+	if dc.k8sClient == nil {
+		kc, err := k8s.NewClient()
+		if err != nil {
+			return nil, err
+		}
+
 		dc.k8sClient = kc
 	}
 
@@ -98,23 +125,33 @@ func (dc *dependencyContainer) MustGetK8sClient(options ...pkg.KubernetesOption)
 }
 
 func (dc *dependencyContainer) GetRegistryClient(repo string, options ...pkg.RegistryOption) (pkg.RegistryClient, error) {
+	key := newRegistryClientKey(repo, options)
+
 	dc.crmu.RLock()
-	if dc.crClient != nil {
+	if client, ok := dc.crClients[key]; ok {
 		defer dc.crmu.RUnlock()
-		return dc.crClient, nil
+		return client, nil
 	}
 	dc.crmu.RUnlock()
 
 	dc.crmu.Lock()
 	defer dc.crmu.Unlock()
 
-	var err error
-	dc.crClient, err = cr.NewClient(repo, options...)
+	if client, ok := dc.crClients[key]; ok {
+		return client, nil
+	}
+
+	client, err := cr.NewClient(repo, options...)
 	if err != nil {
 		return nil, err
 	}
 
-	return dc.crClient, nil
+	if dc.crClients == nil {
+		dc.crClients = make(map[registryClientKey]*cr.Client)
+	}
+	dc.crClients[key] = client
+
+	return client, nil
 }
 
 func (dc *dependencyContainer) MustGetRegistryClient(repo string, options ...pkg.RegistryOption) pkg.RegistryClient {
@@ -0,0 +1,56 @@
+package dependency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/deckhouse/module-sdk/pkg/dependency/cr"
+	"github.com/deckhouse/module-sdk/pkg/dependency/httpclient"
+)
+
+func TestDependencyContainer_ClientsCache(t *testing.T) {
+	dc := NewDependencyContainer()
+
+	t.Run("http client", func(t *testing.T) {
+		client := dc.GetHTTPClient()
+		assert.Same(t, client, dc.GetHTTPClient())
+
+		insecure := dc.GetHTTPClient(httpclient.WithInsecureSkipVerify())
+		assert.NotSame(t, client, insecure)
+		assert.Same(t, insecure, dc.GetHTTPClient(httpclient.WithInsecureSkipVerify()))
+
+		withCA := dc.GetHTTPClient(httpclient.WithAdditionalCACerts([][]byte{[]byte("ca")}))
+		assert.NotSame(t, client, withCA)
+		assert.NotSame(t, withCA, dc.GetHTTPClient(httpclient.WithAdditionalCACerts([][]byte{[]byte("other ca")})))
+	})
+
+	t.Run("registry client", func(t *testing.T) {
+		client, err := dc.GetRegistryClient("registry.example.com/app", cr.WithAuth(""))
+		require.NoError(t, err)
+
+		cached, err := dc.GetRegistryClient("registry.example.com/app", cr.WithAuth(""))
+		require.NoError(t, err)
+		assert.Same(t, client, cached)
+
+		other, err := dc.GetRegistryClient("registry.example.com/other", cr.WithAuth(""))
+		require.NoError(t, err)
+		assert.NotSame(t, client, other)
+
+		withTimeout, err := dc.GetRegistryClient("registry.example.com/app", cr.WithAuth(""), cr.WithTimeout(time.Second))
+		require.NoError(t, err)
+		assert.NotSame(t, client, withTimeout)
+
+		auth := `{"auths":{"registry.example.com":{"auth":"dXNlcjpwYXNz"}}}`
+		withAuth, err := dc.GetRegistryClient("registry.example.com/app", cr.WithAuth(auth))
+		require.NoError(t, err)
+		assert.NotSame(t, client, withAuth)
+
+		otherAuth, err := dc.GetRegistryClient("registry.example.com/app",
+			cr.WithAuth(`{"auths":{"registry.example.com":{"auth":"b3RoZXI6cGFzcw=="}}}`))
+		require.NoError(t, err)
+		assert.NotSame(t, withAuth, otherAuth, "credentials of one hook are not used by another")
+	})
+}
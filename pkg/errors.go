@@ -1,18 +1,118 @@
 package pkg
 
-import "strconv"
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Error codes reported to addon-operator with the hook error.
+// Addon-operator uses them to decide whether the failed hook run should be retried.
+const (
+	// ErrorCodeUnknown is the code of errors without a code.
+	ErrorCodeUnknown = 0
+	// ErrorCodeRetryable means the hook run failed due to a transient problem and may succeed on retry.
+	ErrorCodeRetryable = 1
+	// ErrorCodePermanent means the hook run will fail again until something changes in the cluster.
+	ErrorCodePermanent = 2
+	// ErrorCodeConfiguration means the module configuration or values are invalid.
+	ErrorCodeConfiguration = 3
+	// ErrorCodeDependencyUnavailable means an external dependency (Kubernetes API, registry, etc.) is not available.
+	ErrorCodeDependencyUnavailable = 4
+	// ErrorCodePanic means the hook panicked.
+	ErrorCodePanic = 5
+)
 
 var _ error = (*Error)(nil)
 
+// Error is an error with a code. Return it from the hook to tell
+// addon-operator how to handle the failure, see NewRetryableError,
+// NewPermanentError, NewConfigurationError and NewDependencyUnavailableError.
 type Error struct {
 	Message string
 	Code    int
+
+	// Err is the wrapped error, it is used as the message if Message is empty.
+	Err error
+}
+
+// NewError wraps err into an error with the code.
+func NewError(code int, err error) *Error {
+	return &Error{Code: code, Err: err}
+}
+
+// NewRetryableError wraps err into an error with ErrorCodeRetryable.
+func NewRetryableError(err error) *Error {
+	return NewError(ErrorCodeRetryable, err)
+}
+
+// NewPermanentError wraps err into an error with ErrorCodePermanent.
+func NewPermanentError(err error) *Error {
+	return NewError(ErrorCodePermanent, err)
+}
+
+// NewConfigurationError wraps err into an error with ErrorCodeConfiguration.
+func NewConfigurationError(err error) *Error {
+	return NewError(ErrorCodeConfiguration, err)
+}
+
+// NewDependencyUnavailableError wraps err into an error with ErrorCodeDependencyUnavailable.
+func NewDependencyUnavailableError(err error) *Error {
+	return NewError(ErrorCodeDependencyUnavailable, err)
 }
 
 func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" && e.Err != nil {
+		msg = e.Err.Error()
+	}
+
 	if e.Code != 0 {
-		return e.Message + " (code: " + strconv.Itoa(e.Code) + ")"
+		return msg + " (code: " + strconv.Itoa(e.Code) + ")"
+	}
+
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCode returns the code of the first *Error in the err chain,
+// ErrorCodePanic if the hook panicked, or ErrorCodeUnknown.
+func ErrorCode(err error) int {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+
+	var p *PanicError
+	if errors.As(err, &p) {
+		return ErrorCodePanic
 	}
 
-	return e.Message
+	return ErrorCodeUnknown
+}
+
+// IsRetryable reports whether the hook run failed with err may succeed on retry.
+// Errors without a code are considered retryable, as addon-operator retries them by default.
+func IsRetryable(err error) bool {
+	switch ErrorCode(err) {
+	case ErrorCodeUnknown, ErrorCodeRetryable, ErrorCodeDependencyUnavailable:
+		return true
+	}
+
+	return false
+}
+
+// PanicError is a panic recovered in the hook run.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the panicked goroutine.
+	Stack string
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprint(e.Value)
 }
@@ -0,0 +1,45 @@
+package pkg_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/deckhouse/module-sdk/pkg"
+)
+
+func TestError(t *testing.T) {
+	cause := errors.New("connection refused")
+
+	err := pkg.NewDependencyUnavailableError(cause)
+	assert.EqualError(t, err, "connection refused (code: 4)")
+	assert.ErrorIs(t, err, cause)
+
+	assert.EqualError(t, &pkg.Error{Message: "custom", Err: cause}, "custom")
+}
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		code      int
+		retryable bool
+	}{
+		{name: "plain error", err: errors.New("error"), code: pkg.ErrorCodeUnknown, retryable: true},
+		{name: "retryable", err: pkg.NewRetryableError(errors.New("error")), code: pkg.ErrorCodeRetryable, retryable: true},
+		{name: "permanent", err: pkg.NewPermanentError(errors.New("error")), code: pkg.ErrorCodePermanent},
+		{name: "configuration", err: pkg.NewConfigurationError(errors.New("error")), code: pkg.ErrorCodeConfiguration},
+		{name: "dependency unavailable", err: pkg.NewDependencyUnavailableError(errors.New("error")), code: pkg.ErrorCodeDependencyUnavailable, retryable: true},
+		{name: "wrapped", err: fmt.Errorf("reconcile: %w", pkg.NewPermanentError(errors.New("error"))), code: pkg.ErrorCodePermanent},
+		{name: "panic", err: fmt.Errorf("hook panicked: %w", &pkg.PanicError{Value: "boom"}), code: pkg.ErrorCodePanic},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.code, pkg.ErrorCode(tt.err))
+			assert.Equal(t, tt.retryable, pkg.IsRetryable(tt.err))
+		})
+	}
+}
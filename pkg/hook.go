@@ -5,9 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strings"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/deckhouse/module-sdk/pkg/jq"
 )
 
 // =============================================================================
@@ -42,6 +46,8 @@ type Config interface {
 type Hook[C Config, T Input] struct {
 	Config   C
 	HookFunc HookFunc[T]
+	// Middlewares wrap HookFunc, the first middleware is the outermost one.
+	Middlewares []Middleware
 }
 
 // HookFunc is the function signature for hook execution logic.
@@ -54,12 +60,21 @@ type HookFunc[T Input] func(ctx context.Context, input T) error
 // HookInput provides context and utilities for module hook execution.
 type HookInput struct {
 	Snapshots Snapshots
+	// BindingContexts are the reasons of the current hook run, in order of their arrival.
+	BindingContexts []BindingContext
 
 	Values           PatchableValuesCollector
 	ConfigValues     PatchableValuesCollector
 	PatchCollector   PatchCollector
 	MetricsCollector MetricsCollector
 
+	// Review is set only when the hook is triggered by a KubernetesValidating binding.
+	Review ValidatingReview
+	// Mutation is set only when the hook is triggered by a KubernetesMutating binding.
+	Mutation MutatingReview
+	// Conversion is set only when the hook is triggered by a KubernetesConversion binding.
+	Conversion ConversionReview
+
 	DC DependencyContainer
 
 	Logger Logger
@@ -68,6 +83,8 @@ type HookInput struct {
 // ApplicationHookInput provides context and utilities for application hook execution.
 type ApplicationHookInput struct {
 	Snapshots Snapshots
+	// BindingContexts are the reasons of the current hook run, in order of their arrival.
+	BindingContexts []BindingContext
 
 	Instance Instance
 
@@ -81,6 +98,18 @@ type ApplicationHookInput struct {
 	Logger Logger
 }
 
+// TriggeredGroup returns the name of the binding group which triggered the hook run.
+// It returns an empty string if the hook is triggered by bindings without a group.
+func (input *HookInput) TriggeredGroup() string {
+	return triggeredGroup(input.BindingContexts)
+}
+
+// TriggeredGroup returns the name of the binding group which triggered the hook run.
+// It returns an empty string if the hook is triggered by bindings without a group.
+func (input *ApplicationHookInput) TriggeredGroup() string {
+	return triggeredGroup(input.BindingContexts)
+}
+
 // Instance provides access to application instance metadata.
 type Instance interface {
 	// Name returns application instance name
@@ -114,14 +143,65 @@ type HookConfigInterface interface {
 }
 
 // OrderedConfig specifies execution order for lifecycle hooks.
+// Hooks with lower Order run first.
 type OrderedConfig struct {
 	Order uint
+	// RunAfter lists hooks which must run before this hook in the same phase.
+	// Order is increased on registry.ResolveOrder to satisfy the dependencies.
+	RunAfter []HookRef
+	// RunBefore lists hooks which must run after this hook in the same phase.
+	RunBefore []HookRef
+}
+
+// HookRef references a hook in OrderedConfig.RunAfter and OrderedConfig.RunBefore.
+// Use HookName or a handle returned by registry.Register.
+type HookRef interface {
+	// RefName returns the name of the referenced hook, see HookMetadata.Name.
+	RefName() string
 }
 
+// HookName references a hook by its name, e.g. "subfolder/my_hook".
+type HookName string
+
+// RefName implements HookRef.
+func (n HookName) RefName() string { return string(n) }
+
 // HookConfigSettings contains rate limiting settings for hook execution.
 type HookConfigSettings struct {
 	ExecutionMinInterval time.Duration
 	ExecutionBurst       int
+	// EnableSchedulesOnStartup runs 'Schedule' bindings without waiting for addon-operator readiness.
+	EnableSchedulesOnStartup *bool
+}
+
+// PartialOutputPolicy defines how the output collected by a hook run is handled
+// if the run is cancelled by the timeout or a termination signal.
+type PartialOutputPolicy string
+
+const (
+	// PartialOutputDiscard fails the run and drops values patches, object patches
+	// and webhook responses collected before the cancellation. It is the default policy.
+	PartialOutputDiscard PartialOutputPolicy = "Discard"
+	// PartialOutputFlush reports the run as successful, so the output collected
+	// before the cancellation is applied by addon-operator.
+	PartialOutputFlush PartialOutputPolicy = "Flush"
+)
+
+func validatePartialOutputPolicy(policy PartialOutputPolicy) error {
+	switch policy {
+	case "", PartialOutputDiscard, PartialOutputFlush:
+		return nil
+	}
+
+	return fmt.Errorf("partial output policy %q is not one of: %s, %s", policy, PartialOutputDiscard, PartialOutputFlush)
+}
+
+func validateTimeout(timeout time.Duration) error {
+	if timeout < 0 {
+		return fmt.Errorf("timeout %s must not be negative", timeout)
+	}
+
+	return nil
 }
 
 // =============================================================================
@@ -135,6 +215,13 @@ type HookConfig struct {
 
 	Kubernetes []KubernetesConfig
 
+	// KubernetesValidating turns the hook into a validating admission webhook.
+	KubernetesValidating []ValidatingConfig
+	// KubernetesMutating turns the hook into a mutating admission webhook.
+	KubernetesMutating []MutatingConfig
+	// KubernetesConversion turns the hook into a CRD conversion webhook.
+	KubernetesConversion []ConversionConfig
+
 	// OnStartup runs hook on module/global startup
 	// Attention! During the startup you don't have snapshots available
 	// use native KubeClient to fetch resources
@@ -148,25 +235,71 @@ type HookConfig struct {
 	Queue        string
 
 	Settings *HookConfigSettings
+
+	// LogLevel overrides the log level of the hook execution, e.g. "debug".
+	LogLevel string
+
+	// Timeout cancels the context of the hook run after the duration.
+	// Zero uses the SDK default, see app.WithDefaultTimeout, hooks have no timeout by default.
+	Timeout time.Duration
+	// PartialOutput defines how the output of a run cancelled by the timeout
+	// or a termination signal is handled. PartialOutputDiscard by default.
+	PartialOutput PartialOutputPolicy
+
+	// AllowedValuesRoots are root keys of values, besides the module values key,
+	// the hook is allowed to patch, e.g. "global". Patches of other roots fail the hook run.
+	AllowedValuesRoots []string
+
+	// Sensitive marks values and snapshot fields which are redacted in debug output.
+	Sensitive *SensitiveConfig
 }
 
 // Validate checks the HookConfig for errors.
+// Every line of the returned error describes a single problem.
 func (cfg *HookConfig) Validate() error {
 	var errs error
 	// list of not validated fields:
 	// Metadata (filled by registry)
+	names := newBindingNames()
+
 	for _, s := range cfg.Schedule {
-		if err := s.Validate(); err != nil {
-			errs = errors.Join(errs, fmt.Errorf("schedule with name '%s': %w", s.Name, err))
-		}
+		errs = errors.Join(errs, names.add("schedule", s.Name))
+		errs = errors.Join(errs, prefixErrors(s.Validate(), "schedule with name '%s'", s.Name))
 	}
 
 	for _, k := range cfg.Kubernetes {
-		if err := k.Validate(); err != nil {
-			errs = errors.Join(errs, fmt.Errorf("kubernetes config with name '%s': %w", k.Name, err))
+		errs = errors.Join(errs, names.add("kubernetes", k.Name))
+		errs = errors.Join(errs, prefixErrors(k.Validate(), "kubernetes config with name '%s'", k.Name))
+	}
+
+	for _, v := range cfg.KubernetesValidating {
+		errs = errors.Join(errs, names.add("validating", v.Name))
+		errs = errors.Join(errs, prefixErrors(v.Validate(), "validating config with name '%s'", v.Name))
+	}
+
+	for _, m := range cfg.KubernetesMutating {
+		errs = errors.Join(errs, names.add("mutating", m.Name))
+		errs = errors.Join(errs, prefixErrors(m.Validate(), "mutating config with name '%s'", m.Name))
+	}
+
+	for _, c := range cfg.KubernetesConversion {
+		errs = errors.Join(errs, names.add("conversion", c.Name))
+		errs = errors.Join(errs, prefixErrors(c.Validate(), "conversion config with name '%s'", c.Name))
+	}
+
+	errs = errors.Join(errs, validateTimeout(cfg.Timeout))
+	errs = errors.Join(errs, validatePartialOutputPolicy(cfg.PartialOutput))
+
+	for _, root := range cfg.AllowedValuesRoots {
+		if root == "" {
+			errs = errors.Join(errs, errors.New("allowed values root must not be empty"))
 		}
 	}
 
+	if cfg.Sensitive != nil {
+		errs = errors.Join(errs, cfg.Sensitive.Validate())
+	}
+
 	return errs
 }
 
@@ -206,23 +339,37 @@ type ApplicationHookConfig struct {
 	Queue        string
 
 	Settings *HookConfigSettings
+
+	// LogLevel overrides the log level of the hook execution, e.g. "debug".
+	LogLevel string
+
+	// Timeout cancels the context of the hook run after the duration.
+	// Zero uses the SDK default, see app.WithDefaultTimeout, hooks have no timeout by default.
+	Timeout time.Duration
+	// PartialOutput defines how the output of a run cancelled by the timeout
+	// or a termination signal is handled. PartialOutputDiscard by default.
+	PartialOutput PartialOutputPolicy
 }
 
 // Validate checks the ApplicationHookConfig for errors.
+// Every line of the returned error describes a single problem.
 func (cfg *ApplicationHookConfig) Validate() error {
 	var errs error
+	names := newBindingNames()
+
 	for _, s := range cfg.Schedule {
-		if err := s.Validate(); err != nil {
-			errs = errors.Join(errs, fmt.Errorf("schedule with name '%s': %w", s.Name, err))
-		}
+		errs = errors.Join(errs, names.add("schedule", s.Name))
+		errs = errors.Join(errs, prefixErrors(s.Validate(), "schedule with name '%s'", s.Name))
 	}
 
 	for _, k := range cfg.Kubernetes {
-		if err := k.Validate(); err != nil {
-			errs = errors.Join(errs, fmt.Errorf("kubernetes config with name '%s': %w", k.Name, err))
-		}
+		errs = errors.Join(errs, names.add("kubernetes", k.Name))
+		errs = errors.Join(errs, prefixErrors(k.Validate(), "kubernetes config with name '%s'", k.Name))
 	}
 
+	errs = errors.Join(errs, validateTimeout(cfg.Timeout))
+	errs = errors.Join(errs, validatePartialOutputPolicy(cfg.PartialOutput))
+
 	return errs
 }
 
@@ -249,6 +396,11 @@ type ScheduleConfig struct {
 	Name string
 	// Crontab is a schedule config in crontab format. (5 or 6 fields)
 	Crontab string
+	// Queue overrides the hook queue for this binding.
+	Queue string
+	// Group combines bindings with the same group name into a single hook run
+	// with snapshots of all group bindings.
+	Group string
 }
 
 // Validate checks the ScheduleConfig for errors.
@@ -284,16 +436,30 @@ type KubernetesConfig struct {
 	FieldSelector *FieldSelector
 	// ExecuteHookOnEvents is true by default. Set to false if only snapshot update is needed.
 	ExecuteHookOnEvents *bool
+	// ExecuteHookOnEventTypes limits watch events which trigger the hook, all events are used if empty.
+	// For example, set it to []WatchEventType{WatchEventDeleted} for cleanup hooks.
+	ExecuteHookOnEventTypes []WatchEventType
 	// ExecuteHookOnSynchronization is true by default. Set to false if only snapshot update is needed.
 	ExecuteHookOnSynchronization *bool
 	// WaitForSynchronization is true by default. Set to false if beforeHelm is not required this snapshot on start.
 	WaitForSynchronization *bool
 	// JqFilter filters results from kubernetes objects.
 	JqFilter string
+	// KeepFullObjectsInMemory is false by default. Set to true to keep full objects along with filter results.
+	// It is always true if JqFilter is empty.
+	KeepFullObjectsInMemory *bool
 	// AllowFailure allows the hook to fail without stopping execution.
 	AllowFailure *bool
 
 	ResynchronizationPeriod string
+
+	// IncludeSnapshotsFrom is a list of bindings which snapshots are passed along with this binding context.
+	IncludeSnapshotsFrom []string
+	// Queue overrides the hook queue for this binding.
+	Queue string
+	// Group combines bindings with the same group name into a single hook run
+	// with snapshots of all group bindings.
+	Group string
 }
 
 // Validate checks the KubernetesConfig for errors.
@@ -304,6 +470,32 @@ func (cfg *KubernetesConfig) Validate() error {
 		errs = errors.Join(errs, errors.New("kind has not letter symbols"))
 	}
 
+	if err := validateAPIVersion(cfg.APIVersion); err != nil {
+		errs = errors.Join(errs, err)
+	}
+
+	if err := validateLabelSelector("labelSelector", cfg.LabelSelector); err != nil {
+		errs = errors.Join(errs, err)
+	}
+
+	if cfg.NamespaceSelector != nil {
+		if err := validateLabelSelector("namespace labelSelector", cfg.NamespaceSelector.LabelSelector); err != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+
+	if err := validateJqFilter(cfg.JqFilter); err != nil {
+		errs = errors.Join(errs, err)
+	}
+
+	if err := validateResynchronizationPeriod(cfg.ResynchronizationPeriod); err != nil {
+		errs = errors.Join(errs, err)
+	}
+
+	if err := validateExecuteHookOnEventTypes(cfg.ExecuteHookOnEvents, cfg.ExecuteHookOnEventTypes); err != nil {
+		errs = errors.Join(errs, err)
+	}
+
 	return errs
 }
 
@@ -325,16 +517,30 @@ type ApplicationKubernetesConfig struct {
 	FieldSelector *FieldSelector
 	// ExecuteHookOnEvents is true by default. Set to false if only snapshot update is needed.
 	ExecuteHookOnEvents *bool
+	// ExecuteHookOnEventTypes limits watch events which trigger the hook, all events are used if empty.
+	// For example, set it to []WatchEventType{WatchEventDeleted} for cleanup hooks.
+	ExecuteHookOnEventTypes []WatchEventType
 	// ExecuteHookOnSynchronization is true by default. Set to false if only snapshot update is needed.
 	ExecuteHookOnSynchronization *bool
 	// WaitForSynchronization is true by default. Set to false if beforeHelm is not required this snapshot on start.
 	WaitForSynchronization *bool
 	// JqFilter filters results from kubernetes objects.
 	JqFilter string
+	// KeepFullObjectsInMemory is false by default. Set to true to keep full objects along with filter results.
+	// It is always true if JqFilter is empty.
+	KeepFullObjectsInMemory *bool
 	// AllowFailure allows the hook to fail without stopping execution.
 	AllowFailure *bool
 
 	ResynchronizationPeriod string
+
+	// IncludeSnapshotsFrom is a list of bindings which snapshots are passed along with this binding context.
+	IncludeSnapshotsFrom []string
+	// Queue overrides the hook queue for this binding.
+	Queue string
+	// Group combines bindings with the same group name into a single hook run
+	// with snapshots of all group bindings.
+	Group string
 }
 
 // Validate checks the ApplicationKubernetesConfig for errors.
@@ -345,6 +551,157 @@ func (cfg *ApplicationKubernetesConfig) Validate() error {
 		errs = errors.Join(errs, errors.New("kind has not letter symbols"))
 	}
 
+	if err := validateAPIVersion(cfg.APIVersion); err != nil {
+		errs = errors.Join(errs, err)
+	}
+
+	if err := validateLabelSelector("labelSelector", cfg.LabelSelector); err != nil {
+		errs = errors.Join(errs, err)
+	}
+
+	if err := validateJqFilter(cfg.JqFilter); err != nil {
+		errs = errors.Join(errs, err)
+	}
+
+	if err := validateResynchronizationPeriod(cfg.ResynchronizationPeriod); err != nil {
+		errs = errors.Join(errs, err)
+	}
+
+	if err := validateExecuteHookOnEventTypes(cfg.ExecuteHookOnEvents, cfg.ExecuteHookOnEventTypes); err != nil {
+		errs = errors.Join(errs, err)
+	}
+
+	return errs
+}
+
+func validateExecuteHookOnEventTypes(executeOnEvents *bool, eventTypes []WatchEventType) error {
+	var errs error
+
+	if executeOnEvents != nil && !*executeOnEvents && len(eventTypes) > 0 {
+		errs = errors.Join(errs, errors.New("executeHookOnEventTypes can not be used with disabled executeHookOnEvents"))
+	}
+
+	for _, eventType := range eventTypes {
+		switch eventType {
+		case WatchEventAdded, WatchEventModified, WatchEventDeleted:
+		default:
+			errs = errors.Join(errs, fmt.Errorf("unknown watch event type '%s'", eventType))
+		}
+	}
+
+	return errs
+}
+
+// validateAPIVersion checks that apiVersion is "version" or "group/version".
+// Empty apiVersion is allowed, "v1" is used in this case.
+func validateAPIVersion(apiVersion string) error {
+	if apiVersion == "" {
+		return nil
+	}
+
+	group, version, found := strings.Cut(apiVersion, "/")
+	if !found {
+		group, version = "", apiVersion
+	}
+
+	var errs error
+
+	if group != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(group) {
+			errs = errors.Join(errs, fmt.Errorf("apiVersion '%s' has invalid group: %s", apiVersion, msg))
+		}
+	}
+
+	for _, msg := range validation.IsDNS1123Label(version) {
+		errs = errors.Join(errs, fmt.Errorf("apiVersion '%s' has invalid version: %s", apiVersion, msg))
+	}
+
+	return errs
+}
+
+func validateLabelSelector(field string, selector *metav1.LabelSelector) error {
+	if selector == nil {
+		return nil
+	}
+
+	if _, err := metav1.LabelSelectorAsSelector(selector); err != nil {
+		return fmt.Errorf("%s is not valid: %w", field, err)
+	}
+
+	return nil
+}
+
+func validateJqFilter(filter string) error {
+	if filter == "" {
+		return nil
+	}
+
+	if _, err := jq.NewQuery(filter); err != nil {
+		return fmt.Errorf("jqFilter '%s' is not valid: %w", filter, err)
+	}
+
+	return nil
+}
+
+func validateResynchronizationPeriod(period string) error {
+	if period == "" {
+		return nil
+	}
+
+	d, err := time.ParseDuration(period)
+	if err != nil {
+		return fmt.Errorf("resynchronizationPeriod is not valid: %w", err)
+	}
+
+	if d <= 0 {
+		return fmt.Errorf("resynchronizationPeriod should be positive, got '%s'", period)
+	}
+
+	return nil
+}
+
+// bindingNames detects bindings with the same name in a hook,
+// shell-operator can not tell such bindings apart in binding contexts.
+type bindingNames map[string]string
+
+func newBindingNames() bindingNames {
+	return make(bindingNames)
+}
+
+func (n bindingNames) add(bindingType, name string) error {
+	// shell-operator uses the binding type as a name for unnamed bindings
+	if name == "" {
+		name = bindingType
+	}
+
+	if existing, ok := n[name]; ok {
+		return fmt.Errorf("%s binding name '%s' is already used by %s binding", bindingType, name, existing)
+	}
+
+	n[name] = bindingType
+
+	return nil
+}
+
+// prefixErrors adds the prefix to every error joined in err,
+// so each line of the aggregated error is self-explanatory.
+func prefixErrors(err error, format string, args ...any) error {
+	if err == nil {
+		return nil
+	}
+
+	prefix := fmt.Sprintf(format, args...)
+
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return fmt.Errorf("%s: %w", prefix, err)
+	}
+
+	var errs error
+	for _, e := range joined.Unwrap() {
+		errs = errors.Join(errs, prefixErrors(e, "%s", prefix))
+	}
+
 	return errs
 }
 
@@ -1,8 +1,11 @@
 package hook
 
 import (
+	"bytes"
+	"encoding/json"
 	"time"
 
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
@@ -22,6 +25,31 @@ type BatchHookConfig struct {
 	Hooks            []HookConfig `yaml:"hooks" json:"hooks"`
 	Readiness        *HookConfig  `yaml:"readiness,omitempty" json:"readiness,omitempty"`
 	HasSettingsCheck bool         `yaml:"has_settings_check,omitempty" json:"has_settings_check,omitempty"`
+	// OrderFingerprint identifies the order of Hooks. Hooks are run by index in this order,
+	// so the index of a hook changes with the fingerprint.
+	OrderFingerprint string `yaml:"orderFingerprint,omitempty" json:"orderFingerprint,omitempty"`
+}
+
+const (
+	HookKindModule      = "module"
+	HookKindApplication = "application"
+)
+
+// HookInfo describes a registered hook in the `hooks list` output.
+type HookInfo struct {
+	// Index of the hook in `hooks run <index>`, it depends on the registration order.
+	Index int    `yaml:"index" json:"index"`
+	Name  string `yaml:"name" json:"name"`
+	Path  string `yaml:"path" json:"path"`
+	// Kind is HookKindModule or HookKindApplication.
+	Kind     string        `yaml:"kind" json:"kind"`
+	Bindings []HookBinding `yaml:"bindings,omitempty" json:"bindings,omitempty"`
+}
+
+type HookBinding struct {
+	// Type is the binding type as in the hook config, e.g. "kubernetes" or "beforeHelm".
+	Type string `yaml:"type" json:"type"`
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
 }
 
 type HookConfig struct {
@@ -29,6 +57,11 @@ type HookConfig struct {
 	Metadata      GoHookMetadata     `yaml:"metadata" json:"metadata"`
 	Schedule      []ScheduleConfig   `yaml:"schedule,omitempty" json:"schedule,omitempty"`
 	Kubernetes    []KubernetesConfig `yaml:"kubernetes,omitempty" json:"kubernetes,omitempty"`
+
+	KubernetesValidating []ValidatingConfig `yaml:"kubernetesValidating,omitempty" json:"kubernetesValidating,omitempty"`
+	KubernetesMutating   []MutatingConfig   `yaml:"kubernetesMutating,omitempty" json:"kubernetesMutating,omitempty"`
+	KubernetesConversion []ConversionConfig `yaml:"kubernetesCustomResourceConversion,omitempty" json:"kubernetesCustomResourceConversion,omitempty"`
+
 	// OnStartup runs hook on module/global startup
 	// Attention! During the startup you don't have snapshots available
 	// use native KubeClient to fetch resources
@@ -66,6 +99,8 @@ type ScheduleConfig struct {
 	Crontab string `yaml:"crontab" json:"crontab"`
 
 	Queue string `yaml:"queue" json:"queue,omitempty"`
+
+	Group string `yaml:"group,omitempty" json:"group,omitempty"`
 }
 
 type FilterResult any
@@ -108,6 +143,10 @@ type KubernetesConfig struct {
 	// ExecuteHookOnEvents is true by default. Set to false if only snapshot update is needed.
 	// *bool --> ExecuteHookOnEvents: [All events] || empty slice || nil
 	ExecuteHookOnEvents *bool `yaml:"executeHookOnEvent,omitempty" json:"executeHookOnEvent,omitempty"`
+	// ExecuteHookOnEventTypes narrows the list of watch events which trigger the hook.
+	// Added || Modified || Deleted
+	// It is marshaled as the executeHookOnEvent list parsed by shell-operator, see MarshalJSON.
+	ExecuteHookOnEventTypes []string `yaml:"-" json:"-"`
 	// ExecuteHookOnSynchronization is true by default. Set to false if only snapshot update is needed.
 	// true || false
 	ExecuteHookOnSynchronization *bool `yaml:"executeHookOnSynchronization,omitempty" json:"executeHookOnSynchronization,omitempty"`
@@ -126,9 +165,140 @@ type KubernetesConfig struct {
 	IncludeSnapshotsFrom []string `yaml:"includeSnapshotsFrom,omitempty" json:"includeSnapshotsFrom,omitempty"`
 
 	Queue string `yaml:"queue,omitempty" json:"queue,omitempty"`
+
+	Group string `yaml:"group,omitempty" json:"group,omitempty"`
+}
+
+// MarshalJSON writes ExecuteHookOnEventTypes as the executeHookOnEvent list, e.g. [Added, Modified],
+// the key shell-operator parses. ExecuteHookOnEvents set to false is written as is.
+func (c KubernetesConfig) MarshalJSON() ([]byte, error) {
+	type config KubernetesConfig
+
+	if len(c.ExecuteHookOnEventTypes) == 0 {
+		return json.Marshal(config(c))
+	}
+
+	return json.Marshal(struct {
+		config
+		ExecuteHookOnEvent []string `json:"executeHookOnEvent"`
+	}{
+		config:             config(c),
+		ExecuteHookOnEvent: c.ExecuteHookOnEventTypes,
+	})
+}
+
+// UnmarshalJSON reads executeHookOnEvent as ExecuteHookOnEventTypes if it is a list
+// and as ExecuteHookOnEvents if it is a boolean.
+func (c *KubernetesConfig) UnmarshalJSON(data []byte) error {
+	type config KubernetesConfig
+
+	aux := struct {
+		*config
+		ExecuteHookOnEvent json.RawMessage `json:"executeHookOnEvent,omitempty"`
+	}{
+		config: (*config)(c),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	raw := bytes.TrimSpace(aux.ExecuteHookOnEvent)
+	switch {
+	case len(raw) == 0 || bytes.Equal(raw, []byte("null")):
+		return nil
+	case raw[0] == '[':
+		return json.Unmarshal(raw, &c.ExecuteHookOnEventTypes)
+	default:
+		return json.Unmarshal(raw, &c.ExecuteHookOnEvents)
+	}
+}
+
+type AdmissionNamespaceSelector struct {
+	LabelSelector *metav1.LabelSelector `yaml:"labelSelector,omitempty" json:"labelSelector,omitempty"`
 }
 
+type ValidatingConfig struct {
+	Name            string                              `yaml:"name" json:"name"`
+	Rules           []admissionregv1.RuleWithOperations `yaml:"rules" json:"rules"`
+	FailurePolicy   *admissionregv1.FailurePolicyType   `yaml:"failurePolicy,omitempty" json:"failurePolicy,omitempty"`
+	SideEffects     *admissionregv1.SideEffectClass     `yaml:"sideEffects,omitempty" json:"sideEffects,omitempty"`
+	TimeoutSeconds  *int32                              `yaml:"timeoutSeconds,omitempty" json:"timeoutSeconds,omitempty"`
+	MatchConditions []admissionregv1.MatchCondition     `yaml:"matchConditions,omitempty" json:"matchConditions,omitempty"`
+	Namespace       *AdmissionNamespaceSelector         `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+	LabelSelector   *metav1.LabelSelector               `yaml:"labelSelector,omitempty" json:"labelSelector,omitempty"`
+
+	IncludeSnapshotsFrom []string `yaml:"includeSnapshotsFrom,omitempty" json:"includeSnapshotsFrom,omitempty"`
+
+	Group string `yaml:"group,omitempty" json:"group,omitempty"`
+}
+
+// MutatingConfig has the same format as ValidatingConfig
+type MutatingConfig ValidatingConfig
+
+type ConversionRule struct {
+	FromVersion string `yaml:"fromVersion" json:"fromVersion"`
+	ToVersion   string `yaml:"toVersion" json:"toVersion"`
+}
+
+type ConversionConfig struct {
+	Name        string           `yaml:"name,omitempty" json:"name,omitempty"`
+	CRDName     string           `yaml:"crdName" json:"crdName"`
+	Conversions []ConversionRule `yaml:"conversions" json:"conversions"`
+
+	IncludeSnapshotsFrom []string `yaml:"includeSnapshotsFrom,omitempty" json:"includeSnapshotsFrom,omitempty"`
+
+	Group string `yaml:"group,omitempty" json:"group,omitempty"`
+}
+
+// Error is written to stderr if the hook run fails, or to StreamOutput by the stream transport.
 type Error struct {
 	Message string `yaml:"message" json:"message"`
-	Code    int    `yaml:"code,omitempty" json:"code,omitempty"`
+	// Code is one of pkg.ErrorCode* constants.
+	Code      int  `yaml:"code,omitempty" json:"code,omitempty"`
+	Retryable bool `yaml:"retryable" json:"retryable"`
+
+	Hook     string   `yaml:"hook,omitempty" json:"hook,omitempty"`
+	Bindings []string `yaml:"bindings,omitempty" json:"bindings,omitempty"`
+	// Stacktrace is set if the hook panicked.
+	Stacktrace string `yaml:"stacktrace,omitempty" json:"stacktrace,omitempty"`
+}
+
+// OutputManifest lists outputs written by the file transport with their checksums,
+// so the caller can check that all outputs are complete.
+type OutputManifest struct {
+	Hook    string                `yaml:"hook" json:"hook"`
+	Outputs []OutputManifestEntry `yaml:"outputs" json:"outputs"`
+}
+
+type OutputManifestEntry struct {
+	// Name is the output, e.g. "metrics" or "valuesPatches", as in StreamOutput.
+	Name string `yaml:"name" json:"name"`
+	Path string `yaml:"path" json:"path"`
+	Size int    `yaml:"size" json:"size"`
+	// SHA256 is the hex encoded checksum of the output.
+	SHA256 string `yaml:"sha256" json:"sha256"`
+}
+
+// StreamInput is the hook input read from stdin by the stream transport.
+type StreamInput struct {
+	// BindingContexts are binding contexts in the shell-operator format.
+	BindingContexts json.RawMessage `json:"bindingContexts,omitempty"`
+	Values          map[string]any  `json:"values,omitempty"`
+	ConfigValues    map[string]any  `json:"configValues,omitempty"`
+}
+
+// StreamOutput is the hook output written to stdout by the stream transport.
+// Fields hold the documents of the corresponding file transport outputs,
+// JSON streams of kubernetes operations and metrics are written as arrays.
+type StreamOutput struct {
+	ValuesPatches        json.RawMessage   `json:"valuesPatches,omitempty"`
+	ConfigValuesPatches  json.RawMessage   `json:"configValuesPatches,omitempty"`
+	KubernetesOperations []json.RawMessage `json:"kubernetesOperations,omitempty"`
+	Metrics              []json.RawMessage `json:"metrics,omitempty"`
+	ValidatingResponse   json.RawMessage   `json:"validatingResponse,omitempty"`
+	AdmissionResponse    json.RawMessage   `json:"admissionResponse,omitempty"`
+	ConversionResponse   json.RawMessage   `json:"conversionResponse,omitempty"`
+	// Error is set if the hook run fails.
+	Error *Error `json:"error,omitempty"`
 }
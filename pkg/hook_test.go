@@ -0,0 +1,117 @@
+package pkg_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/deckhouse/module-sdk/pkg"
+)
+
+func TestHookConfigValidate(t *testing.T) {
+	t.Run("valid config", func(t *testing.T) {
+		cfg := &pkg.HookConfig{
+			Schedule: []pkg.ScheduleConfig{{Name: "every-minute", Crontab: "* * * * *"}},
+			Kubernetes: []pkg.KubernetesConfig{
+				{
+					Name:                    "deployments",
+					APIVersion:              "apps/v1",
+					Kind:                    "Deployment",
+					LabelSelector:           &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+					JqFilter:                `{"name": .metadata.name}`,
+					ResynchronizationPeriod: "10m",
+				},
+				{Name: "nodes", Kind: "Node"},
+			},
+		}
+
+		assert.NoError(t, cfg.Validate())
+	})
+
+	t.Run("all problems are reported", func(t *testing.T) {
+		cfg := &pkg.HookConfig{
+			Schedule: []pkg.ScheduleConfig{{Name: "pods", Crontab: "* * * * *"}},
+			Kubernetes: []pkg.KubernetesConfig{
+				{
+					Name:       "pods",
+					APIVersion: "apps/v1/beta",
+					Kind:       "Pod",
+					LabelSelector: &metav1.LabelSelector{
+						MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Contains"}},
+					},
+					NamespaceSelector: &pkg.NamespaceSelector{
+						LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"bad key!": "x"}},
+					},
+					JqFilter:                ".metadata | {name",
+					ResynchronizationPeriod: "5 minutes",
+				},
+			},
+		}
+
+		err := cfg.Validate()
+		require.Error(t, err)
+
+		lines := strings.Split(err.Error(), "\n")
+		require.Len(t, lines, 6)
+		assert.Equal(t, "kubernetes binding name 'pods' is already used by schedule binding", lines[0])
+		for _, line := range lines[1:] {
+			assert.True(t, strings.HasPrefix(line, "kubernetes config with name 'pods': "), line)
+		}
+		assert.Contains(t, lines[1], "apiVersion 'apps/v1/beta' has invalid version")
+		assert.Contains(t, lines[2], "labelSelector is not valid")
+		assert.Contains(t, lines[3], "namespace labelSelector is not valid")
+		assert.Contains(t, lines[4], "jqFilter '.metadata | {name' is not valid")
+		assert.Contains(t, lines[5], "resynchronizationPeriod is not valid")
+	})
+
+	t.Run("duplicate names across binding types", func(t *testing.T) {
+		cfg := &pkg.HookConfig{
+			Kubernetes: []pkg.KubernetesConfig{{Name: "policy.example.deckhouse.io", Kind: "Pod"}},
+			KubernetesValidating: []pkg.ValidatingConfig{
+				{Name: "policy.example.deckhouse.io"},
+			},
+		}
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "validating binding name 'policy.example.deckhouse.io' is already used by kubernetes binding")
+		assert.Contains(t, err.Error(), "validating config with name 'policy.example.deckhouse.io': rules are empty")
+	})
+
+	t.Run("timeout and partial output policy", func(t *testing.T) {
+		cfg := &pkg.HookConfig{Timeout: time.Minute, PartialOutput: pkg.PartialOutputFlush}
+		assert.NoError(t, cfg.Validate())
+
+		cfg = &pkg.HookConfig{Timeout: -time.Second, PartialOutput: "Keep"}
+		assert.EqualError(t, cfg.Validate(), "timeout -1s must not be negative\n"+
+			`partial output policy "Keep" is not one of: Discard, Flush`)
+	})
+
+	t.Run("allowed values roots", func(t *testing.T) {
+		cfg := &pkg.HookConfig{AllowedValuesRoots: []string{"global"}}
+		assert.NoError(t, cfg.Validate())
+
+		cfg = &pkg.HookConfig{AllowedValuesRoots: []string{""}}
+		assert.EqualError(t, cfg.Validate(), "allowed values root must not be empty")
+	})
+}
+
+func TestApplicationHookConfigValidate(t *testing.T) {
+	cfg := &pkg.ApplicationHookConfig{
+		Kubernetes: []pkg.ApplicationKubernetesConfig{
+			{Name: "pods", APIVersion: "v1", Kind: "Pod"},
+			{Name: "pods", APIVersion: "Apps/v1", Kind: "Deployment", JqFilter: "."},
+		},
+	}
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	lines := strings.Split(err.Error(), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, "kubernetes binding name 'pods' is already used by kubernetes binding", lines[0])
+	assert.Contains(t, lines[1], "kubernetes config with name 'pods': apiVersion 'Apps/v1' has invalid group")
+}
@@ -0,0 +1,49 @@
+package pkg
+
+import (
+	"context"
+)
+
+// Middleware intercepts runs of module and application hooks.
+// It calls next to continue with inner middlewares and the hook itself,
+// or returns without calling next to skip the run.
+// Middlewares are registered globally with app.WithMiddlewares
+// or per hook with registry.RegisterFunc.
+type Middleware func(ctx context.Context, run *HookRun, next func(ctx context.Context) error) error
+
+// HookRun describes the current hook run for middlewares.
+// It contains the part of the input shared by module and application hooks.
+type HookRun struct {
+	Metadata        HookMetadata
+	BindingContexts []BindingContext
+
+	Values           PatchableValuesCollector
+	MetricsCollector MetricsCollector
+	Logger           Logger
+
+	// Input is *HookInput for module hooks and *ApplicationHookInput for application hooks.
+	Input any
+}
+
+// ModuleInput returns the input of a module hook run.
+func (r *HookRun) ModuleInput() (*HookInput, bool) {
+	input, ok := r.Input.(*HookInput)
+	return input, ok
+}
+
+// ApplicationInput returns the input of an application hook run.
+func (r *HookRun) ApplicationInput() (*ApplicationHookInput, bool) {
+	input, ok := r.Input.(*ApplicationHookInput)
+	return input, ok
+}
+
+// RunMiddlewares runs f wrapped by middlewares, the first middleware is the outermost one.
+func RunMiddlewares(ctx context.Context, run *HookRun, middlewares []Middleware, f func(ctx context.Context) error) error {
+	if len(middlewares) == 0 {
+		return f(ctx)
+	}
+
+	return middlewares[0](ctx, run, func(ctx context.Context) error {
+		return RunMiddlewares(ctx, run, middlewares[1:], f)
+	})
+}
@@ -0,0 +1,98 @@
+// Package middleware contains built-in pkg.Middleware implementations.
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"github.com/deckhouse/module-sdk/pkg"
+	metrics "github.com/deckhouse/module-sdk/pkg/metric/operation"
+	"github.com/deckhouse/module-sdk/pkg/utils/set"
+)
+
+const (
+	// DurationMetric is a gauge with the duration of the last hook run in seconds.
+	// The SDK emits the d8_module_sdk_hook_execution_seconds histogram for every run,
+	// use it for latency distributions and the gauge for the last run of the hook only.
+	DurationMetric = "d8_module_sdk_hook_run_duration_seconds"
+	// TimingMetricsGroup prefixes the per-hook metrics group of Timing middleware,
+	// the group of a hook is TimingMetricsGroup + ":" + hook name.
+	TimingMetricsGroup = "module-sdk-hook-timing"
+)
+
+// Recovery converts panics in the hook into errors, so the panic is logged with the
+// stack trace and reported as a hook failure instead of crashing the hook binary.
+// The returned error wraps *pkg.PanicError.
+func Recovery() pkg.Middleware {
+	return func(ctx context.Context, run *pkg.HookRun, next func(ctx context.Context) error) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				stack := string(debug.Stack())
+
+				run.Logger.Error("hook panic recovered",
+					slog.Any("panic", r),
+					slog.String("stacktrace", stack))
+
+				err = fmt.Errorf("hook %q panicked: %w", run.Metadata.Name, &pkg.PanicError{Value: r, Stack: stack})
+			}
+		}()
+
+		return next(ctx)
+	}
+}
+
+// Timing measures the hook run duration. It logs the duration on debug level
+// and sets DurationMetric labeled by the hook name and run result.
+// The gauge of the previous run of the same hook is expired, gauges of other hooks are kept.
+func Timing() pkg.Middleware {
+	return func(ctx context.Context, run *pkg.HookRun, next func(ctx context.Context) error) error {
+		start := time.Now()
+
+		err := next(ctx)
+
+		elapsed := time.Since(start)
+
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+
+		run.Logger.Debug("hook run finished",
+			slog.String("hook", run.Metadata.Name),
+			slog.Duration("duration", elapsed),
+			slog.String("status", status))
+
+		// metric groups are shared by all hooks of the module
+		group := TimingMetricsGroup + ":" + run.Metadata.Name
+		run.MetricsCollector.Expire(group)
+		run.MetricsCollector.Set(DurationMetric, elapsed.Seconds(), map[string]string{
+			"hook":   run.Metadata.Name,
+			"status": status,
+		}, metrics.WithGroup(group))
+
+		return err
+	}
+}
+
+// SkipIf skips the hook run if skip returns true. Inner middlewares are skipped as well.
+func SkipIf(skip func(ctx context.Context, run *pkg.HookRun) bool) pkg.Middleware {
+	return func(ctx context.Context, run *pkg.HookRun, next func(ctx context.Context) error) error {
+		if skip(ctx, run) {
+			run.Logger.Debug("hook run skipped", slog.String("hook", run.Metadata.Name))
+
+			return nil
+		}
+
+		return next(ctx)
+	}
+}
+
+// SkipUnlessModuleEnabled skips the hook run if the module is not listed in "global.enabledModules" values.
+func SkipUnlessModuleEnabled(moduleName string) pkg.Middleware {
+	return SkipIf(func(_ context.Context, run *pkg.HookRun) bool {
+		return !set.NewFromValues(run.Values, "global.enabledModules").Has(moduleName)
+	})
+}
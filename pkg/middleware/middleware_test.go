@@ -0,0 +1,132 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/deckhouse/deckhouse/pkg/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/deckhouse/module-sdk/internal/metric"
+	"github.com/deckhouse/module-sdk/pkg"
+	"github.com/deckhouse/module-sdk/pkg/middleware"
+	"github.com/deckhouse/module-sdk/testing/helpers"
+)
+
+func newRun(t *testing.T, valuesJSON string) (*pkg.HookRun, *metric.Collector) {
+	t.Helper()
+
+	collector := metric.NewCollector()
+	input := helpers.NewInputBuilder(t).
+		WithValuesJSON(valuesJSON).
+		WithMetricsCollector(collector).
+		Build()
+
+	return &pkg.HookRun{
+		Metadata:         pkg.HookMetadata{Name: "002-hook/main"},
+		Values:           input.Values,
+		MetricsCollector: input.MetricsCollector,
+		Logger:           log.NewNop(),
+		Input:            input,
+	}, collector
+}
+
+func TestRecovery(t *testing.T) {
+	run, _ := newRun(t, `{}`)
+
+	err := pkg.RunMiddlewares(context.Background(), run, []pkg.Middleware{middleware.Recovery()}, func(_ context.Context) error {
+		panic("nil map")
+	})
+	assert.EqualError(t, err, `hook "002-hook/main" panicked: nil map`)
+
+	var panicErr *pkg.PanicError
+	require.ErrorAs(t, err, &panicErr)
+	assert.Equal(t, "nil map", panicErr.Value)
+	assert.NotEmpty(t, panicErr.Stack)
+	assert.Equal(t, pkg.ErrorCodePanic, pkg.ErrorCode(err))
+
+	err = pkg.RunMiddlewares(context.Background(), run, []pkg.Middleware{middleware.Recovery()}, func(_ context.Context) error {
+		return errors.New("hook error")
+	})
+	assert.EqualError(t, err, "hook error")
+}
+
+func TestTiming(t *testing.T) {
+	run, collector := newRun(t, `{}`)
+
+	err := pkg.RunMiddlewares(context.Background(), run, []pkg.Middleware{middleware.Timing()}, func(_ context.Context) error {
+		return errors.New("hook error")
+	})
+	require.EqualError(t, err, "hook error")
+
+	ops := collector.CollectedMetrics()
+	require.Len(t, ops, 2)
+	assert.Equal(t, "expire", ops[0].Action)
+	assert.Equal(t, middleware.TimingMetricsGroup+":002-hook/main", ops[0].Group)
+	assert.Equal(t, middleware.DurationMetric, ops[1].Name)
+	assert.Equal(t, middleware.TimingMetricsGroup+":002-hook/main", ops[1].Group)
+	assert.Equal(t, map[string]string{"hook": "002-hook/main", "status": "error"}, ops[1].Labels)
+	require.NotNil(t, ops[1].Value)
+	assert.GreaterOrEqual(t, *ops[1].Value, 0.0)
+
+	// a run of another hook does not expire the gauge of the first one
+	run.Metadata.Name = "003-hook/main"
+	require.NoError(t, pkg.RunMiddlewares(context.Background(), run, []pkg.Middleware{middleware.Timing()}, func(_ context.Context) error {
+		return nil
+	}))
+
+	ops = collector.CollectedMetrics()
+	require.Len(t, ops, 4)
+	assert.Equal(t, "expire", ops[2].Action)
+	assert.Equal(t, middleware.TimingMetricsGroup+":003-hook/main", ops[2].Group)
+	assert.Equal(t, map[string]string{"hook": "003-hook/main", "status": "success"}, ops[3].Labels)
+}
+
+func TestSkipUnlessModuleEnabled(t *testing.T) {
+	mw := []pkg.Middleware{middleware.SkipUnlessModuleEnabled("user-authn")}
+
+	for name, tc := range map[string]struct {
+		values string
+		runs   int
+	}{
+		"module enabled":     {values: `{"global":{"enabledModules":["user-authn"]}}`, runs: 1},
+		"module disabled":    {values: `{"global":{"enabledModules":["cert-manager"]}}`, runs: 0},
+		"no enabled modules": {values: `{}`, runs: 0},
+	} {
+		t.Run(name, func(t *testing.T) {
+			run, _ := newRun(t, tc.values)
+
+			runs := 0
+			err := pkg.RunMiddlewares(context.Background(), run, mw, func(_ context.Context) error {
+				runs++
+				return nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tc.runs, runs)
+		})
+	}
+}
+
+func TestRunMiddlewaresOrder(t *testing.T) {
+	run, _ := newRun(t, `{}`)
+
+	var calls []string
+	trace := func(name string) pkg.Middleware {
+		return func(ctx context.Context, _ *pkg.HookRun, next func(ctx context.Context) error) error {
+			calls = append(calls, name+":before")
+			err := next(ctx)
+			calls = append(calls, name+":after")
+
+			return err
+		}
+	}
+
+	err := pkg.RunMiddlewares(context.Background(), run, []pkg.Middleware{trace("outer"), trace("inner")}, func(_ context.Context) error {
+		calls = append(calls, "hook")
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"outer:before", "inner:before", "hook", "inner:after", "outer:after"}, calls)
+}
@@ -114,7 +114,11 @@ type PatchableValuesCollector interface {
 	GetPatches() []*utils.ValuesPatchOperation
 	GetRaw(path string) any
 	Remove(path string)
+	// RemovePath removes the value at the path defined by keys, which can contain any characters.
+	RemovePath(keys []string)
 	Set(path string, value any)
+	// SetPath sets the value at the path defined by keys, which can contain any characters.
+	SetPath(keys []string, value any)
 }
 
 type ReadableValuesCollector interface {
@@ -0,0 +1,41 @@
+package patchablevalues
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// diffValues calls remove and set for the changes turning oldValue into newValue at keys.
+// Objects are compared field by field, other values, including arrays, are replaced as a whole.
+func diffValues(keys []string, oldValue, newValue any, set func(keys []string, value any), remove func(keys []string)) {
+	oldObj, oldIsObj := oldValue.(map[string]any)
+	newObj, newIsObj := newValue.(map[string]any)
+
+	if !oldIsObj || !newIsObj {
+		if !reflect.DeepEqual(oldValue, newValue) {
+			set(keys, newValue)
+		}
+
+		return
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(oldObj)) {
+		if _, ok := newObj[key]; !ok {
+			remove(slices.Concat(keys, []string{key}))
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(newObj)) {
+		keyPath := slices.Concat(keys, []string{key})
+
+		oldField, ok := oldObj[key]
+		if !ok {
+			set(keyPath, newObj[key])
+
+			continue
+		}
+
+		diffValues(keyPath, oldField, newObj[key], set, remove)
+	}
+}
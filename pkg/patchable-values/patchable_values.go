@@ -1,11 +1,12 @@
 package patchablevalues
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
-	"strings"
 
 	"github.com/tidwall/gjson"
 
@@ -17,9 +18,19 @@ import (
 
 var _ service.PatchableValuesCollector = (*PatchableValues)(nil)
 
+// PatchableValues collects values patches. Reads reflect the patches collected so far,
+// use Original to read the values passed to the hook.
 type PatchableValues struct {
-	values          *gjson.Result
+	// values is the current view with collected patches applied,
+	// nil if doc is patched after the view was rendered, see view
+	values *gjson.Result
+	// doc is the decoded current view, patches are applied to it, nil until the first patch
+	doc any
+	// original is the values passed to the hook
+	original        *gjson.Result
 	patchOperations []*utils.ValuesPatchOperation
+	// sensitivePaths are paths set to service.Sensitive values, see sensitivePaths
+	sensitivePaths [][]string
 }
 
 func NewPatchableValues(values map[string]any) (*PatchableValues, error) {
@@ -29,17 +40,32 @@ func NewPatchableValues(values map[string]any) (*PatchableValues, error) {
 	}
 	res := gjson.ParseBytes(data)
 
-	return &PatchableValues{values: &res}, nil
+	return &PatchableValues{values: &res, original: &res}, nil
+}
+
+// Original returns read-only values passed to the hook, without collected patches.
+func (p *PatchableValues) Original() *PatchableValues {
+	return &PatchableValues{values: p.original, original: p.original}
+}
+
+// Original returns values passed to the hook without collected patches if values
+// is *PatchableValues, e.g. input.Values, or values itself otherwise.
+func Original(values service.ReadableValuesCollector) service.ReadableValuesCollector {
+	if pv, ok := values.(*PatchableValues); ok {
+		return pv.Original()
+	}
+
+	return values
 }
 
 // Get value from patchable. It could be null value
 func (p *PatchableValues) Get(path string) gjson.Result {
-	return p.values.Get(path)
+	return p.view().Get(path)
 }
 
 // GetOk returns value and `exists` flag
 func (p *PatchableValues) GetOk(path string) (gjson.Result, bool) {
-	v := p.values.Get(path)
+	v := p.view().Get(path)
 	if v.Exists() {
 		return v, true
 	}
@@ -49,17 +75,17 @@ func (p *PatchableValues) GetOk(path string) (gjson.Result, bool) {
 
 // GetRaw get empty interface
 func (p *PatchableValues) GetRaw(path string) any {
-	return p.values.Get(path).Value()
+	return p.view().Get(path).Value()
 }
 
 // Exists checks whether a path exists
 func (p *PatchableValues) Exists(path string) bool {
-	return p.values.Get(path).Exists()
+	return p.view().Get(path).Exists()
 }
 
 // ArrayCount counts the number of elements in a JSON array at a path
 func (p *PatchableValues) ArrayCount(path string) (int, error) {
-	v := p.values.Get(path)
+	v := p.view().Get(path)
 	if !v.IsArray() {
 		return 0, fmt.Errorf("value at %q path is not an array", path)
 	}
@@ -67,50 +93,89 @@ func (p *PatchableValues) ArrayCount(path string) (int, error) {
 	return len(v.Array()), nil
 }
 
+// Set sets the value at the gjson-style dotted path, see SplitPath.
 func (p *PatchableValues) Set(path string, value any) {
+	p.SetPath(SplitPath(path), value)
+}
+
+// SetPath sets the value at the path defined by keys, which can contain any characters.
+// Paths of service.Sensitive values are redacted in debug output, see SensitivePaths.
+// It applies to the value itself and to Sensitive fields, map values and elements nested in it.
+func (p *PatchableValues) SetPath(keys []string, value any) {
 	data, err := json.Marshal(value)
 	if err != nil {
 		// The struct returned from a Go hook expected to be marshalable in all cases.
 		// TODO(nabokihms): return a meaningful error.
 		log.Error("patch path",
-			slog.String("path", path),
+			slog.String("path", jsonPointer(keys)),
 			log.Err(err))
 		return
 	}
 
+	p.sensitivePaths = append(p.sensitivePaths, sensitivePaths(keys, value)...)
+
 	op := &utils.ValuesPatchOperation{
 		Op:    "add",
-		Path:  convertDotFilePathToSlashPath(path),
+		Path:  jsonPointer(keys),
 		Value: data,
 	}
 
 	p.patchOperations = append(p.patchOperations, op)
+	p.updateView(func(doc any) any {
+		var value any
+		// data is marshaled above, so it can be unmarshaled
+		_ = unmarshal(data, &value)
+
+		return setPath(doc, keys, value)
+	})
 }
 
+// Remove removes the value at the gjson-style dotted path, see SplitPath.
 func (p *PatchableValues) Remove(path string) {
-	if !p.Exists(path) {
+	p.RemovePath(SplitPath(path))
+}
+
+// RemovePath removes the value at the path defined by keys, which can contain any characters.
+func (p *PatchableValues) RemovePath(keys []string) {
+	if !p.Exists(JoinPath(keys...)) {
 		// return if path not exists
 		return
 	}
 
 	op := &utils.ValuesPatchOperation{
 		Op:   "remove",
-		Path: convertDotFilePathToSlashPath(path),
+		Path: jsonPointer(keys),
 	}
 
 	p.patchOperations = append(p.patchOperations, op)
+	p.updateView(func(doc any) any {
+		return removePath(doc, keys)
+	})
 }
 
 func (p *PatchableValues) GetPatches() []*utils.ValuesPatchOperation {
 	return p.patchOperations
 }
 
+// SensitivePaths returns paths set to service.Sensitive values, including ones nested in set values.
+func (p *PatchableValues) SensitivePaths() [][]string {
+	return p.sensitivePaths
+}
+
+// WriteOutput writes compacted patches: the result of applying them is the same,
+// but no-op sets, repeated writes of the same path and removals of added values are dropped.
+// GetPatches returns patches as they were collected.
 func (p *PatchableValues) WriteOutput(w io.Writer) error {
-	if len(p.patchOperations) == 0 {
+	ops, err := p.CompactedPatches()
+	if err != nil {
+		return err
+	}
+
+	if len(ops) == 0 {
 		return nil
 	}
 
-	err := json.NewEncoder(w).Encode(p.patchOperations)
+	err = json.NewEncoder(w).Encode(ops)
 	if err != nil {
 		return err
 	}
@@ -118,6 +183,130 @@ func (p *PatchableValues) WriteOutput(w io.Writer) error {
 	return nil
 }
 
-func convertDotFilePathToSlashPath(dotPath string) string {
-	return strings.ReplaceAll("/"+dotPath, ".", "/")
+// CompactedPatches returns patches written by WriteOutput, see WriteOutput.
+func (p *PatchableValues) CompactedPatches() ([]*utils.ValuesPatchOperation, error) {
+	if len(p.patchOperations) == 0 {
+		return nil, nil
+	}
+
+	ops, err := p.compactedPatches()
+	if err != nil {
+		return nil, fmt.Errorf("compact patches: %w", err)
+	}
+
+	return ops, nil
+}
+
+// compactedPatches returns patches turning the original values into the current view.
+func (p *PatchableValues) compactedPatches() ([]*utils.ValuesPatchOperation, error) {
+	original, err := decodeObject(p.original)
+	if err != nil {
+		return nil, fmt.Errorf("decode original values: %w", err)
+	}
+
+	current := p.doc
+	if current == nil {
+		current, err = decodeObject(p.view())
+		if err != nil {
+			return nil, fmt.Errorf("decode values: %w", err)
+		}
+	}
+
+	ops := make([]*utils.ValuesPatchOperation, 0, len(p.patchOperations))
+
+	var setErr error
+	diffValues(nil, original, current,
+		func(keys []string, value any) {
+			data, err := json.Marshal(value)
+			if err != nil {
+				setErr = errors.Join(setErr, err)
+				return
+			}
+
+			ops = append(ops, &utils.ValuesPatchOperation{Op: "add", Path: jsonPointer(keys), Value: data})
+		},
+		func(keys []string) {
+			ops = append(ops, &utils.ValuesPatchOperation{Op: "remove", Path: jsonPointer(keys)})
+		})
+
+	if setErr != nil {
+		return nil, setErr
+	}
+
+	return ops, nil
+}
+
+// decodeObject decodes values, absent values are decoded as an empty object.
+func decodeObject(values *gjson.Result) (any, error) {
+	var doc any
+	if err := unmarshal([]byte(values.Raw), &doc); err != nil {
+		return nil, err
+	}
+
+	if doc == nil {
+		return map[string]any{}, nil
+	}
+
+	return doc, nil
+}
+
+// updateView applies the change to the decoded values. The view is rendered on the next read,
+// so consecutive patches do not re-encode the whole document.
+func (p *PatchableValues) updateView(change func(doc any) any) {
+	if p.doc == nil {
+		doc, err := decodeObject(p.view())
+		if err != nil {
+			doc = map[string]any{}
+		}
+
+		p.doc = doc
+	}
+
+	p.doc = change(p.doc)
+	p.values = nil
+}
+
+// view returns the current values view, rendering it if doc is patched after the last read.
+func (p *PatchableValues) view() *gjson.Result {
+	if p.values != nil {
+		return p.values
+	}
+
+	data, err := json.Marshal(p.doc)
+	if err != nil {
+		log.Error("render values view", log.Err(err))
+	}
+
+	res := gjson.ParseBytes(data)
+	p.values = &res
+
+	return p.values
+}
+
+// Values returns the values with collected patches applied.
+// Numbers are decoded as json.Number, so integers above 2^53 are not rounded.
+func (p *PatchableValues) Values() (map[string]any, error) {
+	values := map[string]any{}
+	if err := unmarshal([]byte(p.view().Raw), &values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// unmarshal decodes JSON like json.Unmarshal, but decodes numbers as json.Number,
+// so integers above 2^53 are not rounded by float64.
+func unmarshal(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("invalid character after top-level value")
+	}
+
+	return nil
 }
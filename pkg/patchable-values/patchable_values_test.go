@@ -0,0 +1,221 @@
+package patchablevalues_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	service "github.com/deckhouse/module-sdk/pkg"
+	patchablevalues "github.com/deckhouse/module-sdk/pkg/patchable-values"
+)
+
+func TestPatchableValuesReadYourWrites(t *testing.T) {
+	pv := newValues(t, `{"myModule": {"replicas": 1, "zones": ["a", "c"], "internal": {"token": "x"}}}`)
+
+	pv.Set("myModule.replicas", 3)
+	pv.Set("myModule.https.mode", "Disabled")
+	pv.Set("myModule.zones.1", "b")
+	pv.Set("myModule.zones.-", "d")
+	pv.Remove("myModule.internal.token")
+
+	assert.Equal(t, int64(3), pv.Get("myModule.replicas").Int())
+	assert.Equal(t, "Disabled", pv.Get("myModule.https.mode").String())
+	assert.False(t, pv.Exists("myModule.internal.token"))
+	assert.True(t, pv.Exists("myModule.internal"))
+
+	count, err := pv.ArrayCount("myModule.zones")
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+	assert.Equal(t, []any{"a", "b", "c", "d"}, pv.GetRaw("myModule.zones"))
+
+	// the emitted patches are not changed
+	assert.JSONEq(t, `[
+		{"op": "add", "path": "/myModule/replicas", "value": 3},
+		{"op": "add", "path": "/myModule/https/mode", "value": "Disabled"},
+		{"op": "add", "path": "/myModule/zones/1", "value": "b"},
+		{"op": "add", "path": "/myModule/zones/-", "value": "d"},
+		{"op": "remove", "path": "/myModule/internal/token"}
+	]`, patchesJSON(t, pv))
+
+	original := pv.Original()
+	assert.Equal(t, int64(1), original.Get("myModule.replicas").Int())
+	assert.True(t, original.Exists("myModule.internal.token"))
+	assert.False(t, original.Exists("myModule.https"))
+	assert.Empty(t, original.GetPatches())
+
+	assert.Equal(t, int64(1), patchablevalues.Original(pv).Get("myModule.replicas").Int())
+}
+
+func TestPatchableValuesRemoveSetValue(t *testing.T) {
+	pv := newValues(t, `{}`)
+
+	// a value set in the same hook run can be removed
+	pv.Set("myModule.internal.token", "x")
+	pv.Remove("myModule.internal.token")
+	// a missing value is not removed
+	pv.Remove("myModule.missing")
+
+	assert.False(t, pv.Exists("myModule.internal.token"))
+	assert.JSONEq(t, `[
+		{"op": "add", "path": "/myModule/internal/token", "value": "x"},
+		{"op": "remove", "path": "/myModule/internal/token"}
+	]`, patchesJSON(t, pv))
+}
+
+func TestPatchableValuesWriteOutputCompaction(t *testing.T) {
+	writeOutput := func(t *testing.T, pv *patchablevalues.PatchableValues) string {
+		t.Helper()
+
+		buf := bytes.NewBuffer(nil)
+		require.NoError(t, pv.WriteOutput(buf))
+
+		return buf.String()
+	}
+
+	t.Run("no-op sets", func(t *testing.T) {
+		pv := newValues(t, `{"myModule": {"replicas": 2, "internal": {"zones": ["a"]}}}`)
+
+		pv.Set("myModule.replicas", 2)
+		pv.Set("myModule.internal", map[string]any{"zones": []string{"a"}})
+
+		assert.Len(t, pv.GetPatches(), 2)
+		assert.Empty(t, writeOutput(t, pv))
+	})
+
+	t.Run("repeated writes", func(t *testing.T) {
+		pv := newValues(t, `{"myModule": {"replicas": 1}}`)
+
+		pv.Set("myModule.replicas", 2)
+		pv.Set("myModule.replicas", 3)
+		pv.Set("myModule.internal.token", "a")
+		pv.Set("myModule.internal", map[string]any{"token": "b"})
+
+		assert.JSONEq(t, `[
+			{"op": "add", "path": "/myModule/internal", "value": {"token": "b"}},
+			{"op": "add", "path": "/myModule/replicas", "value": 3}
+		]`, writeOutput(t, pv))
+	})
+
+	t.Run("add and remove", func(t *testing.T) {
+		pv := newValues(t, `{"myModule": {"replicas": 1, "internal": {"token": "a"}}}`)
+
+		pv.Set("myModule.debug", true)
+		pv.Remove("myModule.debug")
+		pv.Remove("myModule.internal.token")
+		pv.Set("myModule.internal.token", "a")
+		pv.Remove("myModule.replicas")
+
+		assert.JSONEq(t, `[{"op": "remove", "path": "/myModule/replicas"}]`, writeOutput(t, pv))
+	})
+
+	t.Run("arrays are replaced", func(t *testing.T) {
+		pv := newValues(t, `{"myModule": {"zones": ["a", "c"]}}`)
+
+		pv.Set("myModule.zones.1", "b")
+
+		assert.JSONEq(t, `[{"op": "add", "path": "/myModule/zones", "value": ["a", "b", "c"]}]`, writeOutput(t, pv))
+	})
+}
+
+func TestPatchableValuesLargeIntegers(t *testing.T) {
+	// 2^53 + 1 is rounded by float64
+	const big = int64(9007199254740993)
+
+	pv := newValues(t, `{"myModule": {"replicas": 1}}`)
+
+	pv.Set("myModule.id", big)
+	pv.Set("myModule.ids", []int64{big})
+	require.NoError(t, patchablevalues.Apply(pv, "myModule.limits", map[string]int64{"max": big}))
+
+	assert.Equal(t, big, pv.Get("myModule.id").Int())
+	assert.Equal(t, big, pv.Get("myModule.ids.0").Int())
+	assert.Equal(t, big, pv.Get("myModule.limits.max").Int())
+
+	values, err := pv.Values()
+	require.NoError(t, err)
+	assert.Equal(t, json.Number("9007199254740993"), values["myModule"].(map[string]any)["id"])
+
+	limits, err := patchablevalues.Decode[map[string]any](pv, "myModule.limits")
+	require.NoError(t, err)
+	assert.Equal(t, json.Number("9007199254740993"), limits["max"])
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, pv.WriteOutput(buf))
+	assert.Equal(t, `[`+
+		`{"op":"add","path":"/myModule/id","value":9007199254740993},`+
+		`{"op":"add","path":"/myModule/ids","value":[9007199254740993]},`+
+		`{"op":"add","path":"/myModule/limits","value":{"max":9007199254740993}}]`+"\n", buf.String())
+}
+
+func TestPatchableValuesSensitivePaths(t *testing.T) {
+	pv := newValues(t, `{}`)
+
+	pv.Set("myModule.internal.replicas", 2)
+	pv.Set("myModule.internal.password", service.Sensitive("s3cr3t"))
+	pv.SetPath([]string{"myModule", "auth.example.com", "token"}, service.Sensitive("token"))
+
+	assert.Equal(t, [][]string{
+		{"myModule", "internal", "password"},
+		{"myModule", "auth.example.com", "token"},
+	}, pv.SensitivePaths())
+
+	// the value itself is not redacted
+	assert.Equal(t, "s3cr3t", pv.Get("myModule.internal.password").String())
+	assert.Equal(t, `[{"op":"add","path":"/myModule/internal/replicas","value":2},{"op":"add","path":"/myModule/internal/password","value":"s3cr3t"},{"op":"add","path":"/myModule/auth.example.com/token","value":"token"}]`, patchesJSON(t, pv))
+}
+
+func BenchmarkPatchableValuesSet(b *testing.B) {
+	for b.Loop() {
+		pv, err := patchablevalues.NewPatchableValues(map[string]any{})
+		require.NoError(b, err)
+
+		for i := range 1000 {
+			pv.SetPath([]string{"myModule", "items", strconv.Itoa(i)}, i)
+		}
+
+		_ = pv.Get("myModule.items.999")
+	}
+}
+
+func TestPatchableValuesNestedSensitivePaths(t *testing.T) {
+	type auth struct {
+		Username string             `json:"username"`
+		Password service.Sensitive  `json:"password"`
+		Token    *service.Sensitive `json:"token,omitempty"`
+		Ignored  service.Sensitive  `json:"-"`
+	}
+
+	type Common struct {
+		Key service.Sensitive
+	}
+
+	type registry struct {
+		Common
+		Auth  auth                         `json:"auth"`
+		Certs []map[string]any             `json:"certs"`
+		Keys  map[string]service.Sensitive `json:"keys"`
+	}
+
+	pv := newValues(t, `{}`)
+
+	token := service.Sensitive("token")
+	pv.Set("myModule.registry", registry{
+		Common: Common{Key: "key"},
+		Auth:   auth{Username: "admin", Password: "s3cr3t", Token: &token},
+		Certs:  []map[string]any{{"crt": "crt", "key": service.Sensitive("tls")}},
+		Keys:   map[string]service.Sensitive{"a.b": "a"},
+	})
+	pv.Set("myModule.internal", map[string]any{"replicas": 2})
+
+	assert.ElementsMatch(t, [][]string{
+		{"myModule", "registry", "Key"},
+		{"myModule", "registry", "auth", "password"},
+		{"myModule", "registry", "auth", "token"},
+		{"myModule", "registry", "certs", "0", "key"},
+		{"myModule", "registry", "keys", "a.b"},
+	}, pv.SensitivePaths())
+}
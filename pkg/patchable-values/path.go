@@ -0,0 +1,54 @@
+package patchablevalues
+
+import (
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// SplitPath splits the gjson-style dotted path into keys.
+// A backslash escapes the next character, e.g. `annotations.example\.com/foo`
+// is split into "annotations" and "example.com/foo".
+func SplitPath(path string) []string {
+	keys := make([]string, 0, strings.Count(path, ".")+1)
+
+	var key strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; {
+		case c == '\\' && i+1 < len(path):
+			i++
+			key.WriteByte(path[i])
+		case c == '.':
+			keys = append(keys, key.String())
+			key.Reset()
+		default:
+			key.WriteByte(c)
+		}
+	}
+
+	return append(keys, key.String())
+}
+
+// JoinPath joins keys into the gjson-style dotted path, escaping special characters,
+// so keys like "example.com/foo" can be used with Get, Set and other path based methods.
+func JoinPath(keys ...string) string {
+	escaped := make([]string, 0, len(keys))
+	for _, key := range keys {
+		escaped = append(escaped, gjson.Escape(key))
+	}
+
+	return strings.Join(escaped, ".")
+}
+
+// jsonPointer converts keys into the JSON Pointer (RFC 6901) used in values patches.
+func jsonPointer(keys []string) string {
+	var b strings.Builder
+	for _, key := range keys {
+		b.WriteByte('/')
+		b.WriteString(jsonPointerEscaper.Replace(key))
+	}
+
+	return b.String()
+}
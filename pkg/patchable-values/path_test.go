@@ -0,0 +1,52 @@
+package patchablevalues_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	patchablevalues "github.com/deckhouse/module-sdk/pkg/patchable-values"
+)
+
+func TestSplitPath(t *testing.T) {
+	tests := []struct {
+		path string
+		keys []string
+	}{
+		{path: "a.b.c", keys: []string{"a", "b", "c"}},
+		{path: `annotations.example\.com/foo`, keys: []string{"annotations", "example.com/foo"}},
+		{path: `a\\.b`, keys: []string{`a\`, "b"}},
+		{path: "zones.0", keys: []string{"zones", "0"}},
+		{path: "a", keys: []string{"a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.keys, patchablevalues.SplitPath(tt.path))
+			assert.Equal(t, tt.keys, patchablevalues.SplitPath(patchablevalues.JoinPath(tt.keys...)))
+		})
+	}
+}
+
+func TestPatchableValuesEscaping(t *testing.T) {
+	pv := newValues(t, `{"myModule": {"annotations": {"example.com/foo": "a", "x~y": "b"}, "zones": ["a", "b"]}}`)
+
+	pv.Set(`myModule.annotations.example\.com/foo`, "c")
+	pv.SetPath([]string{"myModule", "annotations", "example.com/bar"}, "d")
+	pv.RemovePath([]string{"myModule", "annotations", "x~y"})
+	pv.Set("myModule.zones.0", "z")
+	pv.Remove("myModule.zones.2")
+
+	assert.JSONEq(t, `[
+		{"op": "add", "path": "/myModule/annotations/example.com~1foo", "value": "c"},
+		{"op": "add", "path": "/myModule/annotations/example.com~1bar", "value": "d"},
+		{"op": "remove", "path": "/myModule/annotations/x~0y"},
+		{"op": "add", "path": "/myModule/zones/0", "value": "z"},
+		{"op": "remove", "path": "/myModule/zones/2"}
+	]`, patchesJSON(t, pv))
+
+	assert.Equal(t, "c", pv.Get(patchablevalues.JoinPath("myModule", "annotations", "example.com/foo")).String())
+	assert.Equal(t, "d", pv.Get(`myModule.annotations.example\.com/bar`).String())
+	assert.False(t, pv.Exists(patchablevalues.JoinPath("myModule", "annotations", "x~y")))
+	assert.Equal(t, []any{"z", "a"}, pv.GetRaw("myModule.zones"))
+}
@@ -0,0 +1,134 @@
+package patchablevalues
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	service "github.com/deckhouse/module-sdk/pkg"
+)
+
+var (
+	sensitiveType     = reflect.TypeFor[service.Sensitive]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// sensitivePaths returns paths of service.Sensitive values in the value, prefixed with keys.
+// The value is walked as encoding/json marshals it: by json tags of struct fields,
+// keys of maps and indexes of slices. Types with custom marshalers are not walked into.
+// The value must be marshalable, so it has no cycles.
+func sensitivePaths(keys []string, value any) [][]string {
+	var paths [][]string
+
+	walkSensitive(reflect.ValueOf(value), slices.Clone(keys), &paths)
+
+	return paths
+}
+
+func walkSensitive(v reflect.Value, keys []string, paths *[][]string) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+
+		v = v.Elem()
+	}
+
+	if !v.IsValid() {
+		return
+	}
+
+	if v.Type() == sensitiveType {
+		*paths = append(*paths, slices.Clone(keys))
+
+		return
+	}
+
+	if v.Type().Implements(jsonMarshalerType) || reflect.PointerTo(v.Type()).Implements(jsonMarshalerType) {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		walkStruct(v, keys, paths)
+	case reflect.Map:
+		keyType := v.Type().Key()
+		if keyType.Kind() != reflect.String && keyType.Implements(textMarshalerType) {
+			// keys are marshaled by MarshalText, their names are unknown
+			return
+		}
+
+		iter := v.MapRange()
+		for iter.Next() {
+			walkSensitive(iter.Value(), append(keys, mapKey(iter.Key())), paths)
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			// []byte is marshaled as a base64 string
+			return
+		}
+
+		for i := range v.Len() {
+			walkSensitive(v.Index(i), append(keys, strconv.Itoa(i)), paths)
+		}
+	}
+}
+
+func walkStruct(v reflect.Value, keys []string, paths *[][]string) {
+	t := v.Type()
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := v.Field(i)
+			if embedded.Kind() == reflect.Pointer {
+				// encoding/json skips nil embedded pointers and pointers to unexported types
+				if embedded.IsNil() || !field.IsExported() {
+					continue
+				}
+
+				embedded = embedded.Elem()
+			}
+
+			// fields of embedded structs are promoted into the parent object
+			if embedded.Kind() == reflect.Struct {
+				walkStruct(embedded, keys, paths)
+
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		walkSensitive(v.Field(i), append(keys, name), paths)
+	}
+}
+
+func mapKey(key reflect.Value) string {
+	switch key.Kind() {
+	case reflect.String:
+		return key.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10)
+	default:
+		return strconv.FormatUint(key.Uint(), 10)
+	}
+}
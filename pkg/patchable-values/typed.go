@@ -0,0 +1,73 @@
+package patchablevalues
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	service "github.com/deckhouse/module-sdk/pkg"
+)
+
+// ErrPathNotFound is returned by Decode if there is no value at the path.
+var ErrPathNotFound = errors.New("path not found")
+
+// Decode unmarshals the values subtree at the dotted path into T.
+// Numbers decoded into interface values are json.Number, so large integers are not rounded.
+// It works with Values, ConfigValues and application Settings:
+//
+//	cfg, err := patchablevalues.Decode[InternalValues](input.Values, "myModule.internal")
+func Decode[T any](values service.ReadableValuesCollector, path string) (T, error) {
+	var out T
+
+	v, ok := values.GetOk(path)
+	if !ok {
+		return out, fmt.Errorf("decode %q: %w", path, ErrPathNotFound)
+	}
+
+	if err := unmarshal([]byte(v.Raw), &out); err != nil {
+		return out, fmt.Errorf("decode %q: %w", path, err)
+	}
+
+	return out, nil
+}
+
+// Apply sets the values subtree at the dotted path to value. Instead of replacing
+// the whole subtree, it emits patch operations only for changed object fields,
+// so unchanged values are not patched. Arrays are replaced as a whole.
+func Apply(values service.PatchableValuesCollector, path string, value any) error {
+	newValue, err := toJSONValue(value)
+	if err != nil {
+		return fmt.Errorf("apply %q: %w", path, err)
+	}
+
+	oldRaw, ok := values.GetOk(path)
+	if !ok {
+		values.Set(path, newValue)
+
+		return nil
+	}
+
+	var oldValue any
+	if err := unmarshal([]byte(oldRaw.Raw), &oldValue); err != nil {
+		return fmt.Errorf("apply %q: decode current value: %w", path, err)
+	}
+
+	diffValues(SplitPath(path), oldValue, newValue, values.SetPath, values.RemovePath)
+
+	return nil
+}
+
+// toJSONValue converts value to the generic JSON representation, e.g. structs to maps.
+func toJSONValue(value any) (any, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("marshal value: %w", err)
+	}
+
+	var out any
+	if err := unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("unmarshal value: %w", err)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,106 @@
+package patchablevalues_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	patchablevalues "github.com/deckhouse/module-sdk/pkg/patchable-values"
+)
+
+type internalValues struct {
+	Replicas int               `json:"replicas"`
+	Image    string            `json:"image,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Zones    []string          `json:"zones,omitempty"`
+}
+
+func newValues(t *testing.T, raw string) *patchablevalues.PatchableValues {
+	t.Helper()
+
+	var values map[string]any
+	require.NoError(t, json.Unmarshal([]byte(raw), &values))
+
+	pv, err := patchablevalues.NewPatchableValues(values)
+	require.NoError(t, err)
+
+	return pv
+}
+
+func patchesJSON(t *testing.T, pv *patchablevalues.PatchableValues) string {
+	t.Helper()
+
+	raw, err := json.Marshal(pv.GetPatches())
+	require.NoError(t, err)
+
+	return string(raw)
+}
+
+func TestDecode(t *testing.T) {
+	pv := newValues(t, `{"myModule": {"internal": {"replicas": 2, "labels": {"app": "web"}, "zones": ["a", "b"]}}}`)
+
+	got, err := patchablevalues.Decode[internalValues](pv, "myModule.internal")
+	require.NoError(t, err)
+	assert.Equal(t, internalValues{Replicas: 2, Labels: map[string]string{"app": "web"}, Zones: []string{"a", "b"}}, got)
+
+	replicas, err := patchablevalues.Decode[int](pv, "myModule.internal.replicas")
+	require.NoError(t, err)
+	assert.Equal(t, 2, replicas)
+
+	_, err = patchablevalues.Decode[internalValues](pv, "myModule.missing")
+	require.ErrorIs(t, err, patchablevalues.ErrPathNotFound)
+
+	_, err = patchablevalues.Decode[int](pv, "myModule.internal.labels")
+	require.Error(t, err)
+}
+
+func TestApply(t *testing.T) {
+	t.Run("changed fields only", func(t *testing.T) {
+		pv := newValues(t, `{"myModule": {"internal": {"replicas": 2, "image": "nginx", "labels": {"app": "web", "tier": "frontend"}, "zones": ["a"]}}}`)
+
+		err := patchablevalues.Apply(pv, "myModule.internal", internalValues{
+			Replicas: 3,
+			Labels:   map[string]string{"app": "web", "team": "platform"},
+			Zones:    []string{"a", "b"},
+		})
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `[
+			{"op": "remove", "path": "/myModule/internal/image"},
+			{"op": "remove", "path": "/myModule/internal/labels/tier"},
+			{"op": "add", "path": "/myModule/internal/labels/team", "value": "platform"},
+			{"op": "add", "path": "/myModule/internal/replicas", "value": 3},
+			{"op": "add", "path": "/myModule/internal/zones", "value": ["a", "b"]}
+		]`, patchesJSON(t, pv))
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		pv := newValues(t, `{"myModule": {"internal": {"replicas": 2}}}`)
+
+		require.NoError(t, patchablevalues.Apply(pv, "myModule.internal", internalValues{Replicas: 2}))
+		assert.Empty(t, pv.GetPatches())
+	})
+
+	t.Run("new path", func(t *testing.T) {
+		pv := newValues(t, `{"myModule": {}}`)
+
+		require.NoError(t, patchablevalues.Apply(pv, "myModule.internal", internalValues{Replicas: 1}))
+		assert.JSONEq(t, `[{"op": "add", "path": "/myModule/internal", "value": {"replicas": 1}}]`, patchesJSON(t, pv))
+	})
+
+	t.Run("keys with dots", func(t *testing.T) {
+		pv := newValues(t, `{"myModule": {"annotations": {"example.com/owner": "a"}}}`)
+
+		require.NoError(t, patchablevalues.Apply(pv, "myModule.annotations", map[string]string{"example.com/owner": "b"}))
+		assert.JSONEq(t, `[{"op": "add", "path": "/myModule/annotations/example.com~1owner", "value": "b"}]`, patchesJSON(t, pv))
+	})
+
+	t.Run("not marshalable", func(t *testing.T) {
+		pv := newValues(t, `{}`)
+
+		require.Error(t, patchablevalues.Apply(pv, "myModule.internal", make(chan int)))
+		assert.Empty(t, pv.GetPatches())
+	})
+}
@@ -0,0 +1,84 @@
+package patchablevalues
+
+import (
+	"slices"
+	"strconv"
+)
+
+// setPath sets the value in the JSON document like the "add" JSON patch operation:
+// array elements are inserted at the index, "-" appends to the array.
+// Missing objects on the path are created.
+func setPath(doc any, keys []string, value any) any {
+	if len(keys) == 0 {
+		return value
+	}
+
+	key, rest := keys[0], keys[1:]
+
+	switch node := doc.(type) {
+	case map[string]any:
+		node[key] = setPath(node[key], rest, value)
+
+		return node
+	case []any:
+		if key == "-" && len(rest) == 0 {
+			return append(node, value)
+		}
+
+		idx, err := strconv.Atoi(key)
+		if err != nil || idx < 0 || idx > len(node) {
+			return node
+		}
+
+		if len(rest) == 0 {
+			return slices.Insert(node, idx, value)
+		}
+
+		if idx < len(node) {
+			node[idx] = setPath(node[idx], rest, value)
+		}
+
+		return node
+	default:
+		return map[string]any{key: setPath(nil, rest, value)}
+	}
+}
+
+// removePath removes the value from the JSON document like the "remove" JSON patch operation.
+func removePath(doc any, keys []string) any {
+	if len(keys) == 0 {
+		return doc
+	}
+
+	key, rest := keys[0], keys[1:]
+
+	switch node := doc.(type) {
+	case map[string]any:
+		if len(rest) == 0 {
+			delete(node, key)
+
+			return node
+		}
+
+		if child, ok := node[key]; ok {
+			node[key] = removePath(child, rest)
+		}
+
+		return node
+	case []any:
+		idx, err := strconv.Atoi(key)
+		if err != nil || idx < 0 || idx >= len(node) {
+			return node
+		}
+
+		if len(rest) == 0 {
+			return slices.Delete(node, idx, idx+1)
+		}
+
+		node[idx] = removePath(node[idx], rest)
+
+		return node
+	default:
+		return doc
+	}
+}
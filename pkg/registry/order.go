@@ -0,0 +1,230 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/deckhouse/module-sdk/pkg"
+)
+
+// Handle references a hook registered with Register.
+type Handle struct {
+	name string
+}
+
+var _ pkg.HookRef = (*Handle)(nil)
+
+// RefName implements pkg.HookRef.
+func (h *Handle) RefName() string {
+	if h == nil {
+		return ""
+	}
+
+	return h.name
+}
+
+var phaseNames = []string{"OnStartup", "OnBeforeHelm", "OnAfterHelm", "OnBeforeDeleteHelm", "OnAfterDeleteHelm"}
+
+// orderedHook points to the lifecycle configs of a registered hook, in phaseNames order.
+type orderedHook struct {
+	name   string
+	phases []**pkg.OrderedConfig
+}
+
+// ResolveOrder computes Order of lifecycle bindings with RunAfter or RunBefore dependencies.
+// Hooks of every phase are sorted topologically: Order of a hook is increased to be greater
+// than Order of every hook it runs after. Orders of phases without dependencies are not changed.
+// Returns an error if a dependency is not registered, has no binding in the same phase or forms a cycle.
+func (h *HookRegistry) ResolveOrder() error {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	hooks := make([]orderedHook, 0, len(h.moduleHooks)+len(h.appHooks))
+
+	for i := range h.moduleHooks {
+		c := &h.moduleHooks[i].Config
+		hooks = append(hooks, orderedHook{
+			name:   c.Metadata.Name,
+			phases: []**pkg.OrderedConfig{&c.OnStartup, &c.OnBeforeHelm, &c.OnAfterHelm, &c.OnBeforeDeleteHelm, &c.OnAfterDeleteHelm},
+		})
+	}
+
+	for i := range h.appHooks {
+		c := &h.appHooks[i].Config
+		hooks = append(hooks, orderedHook{
+			name:   c.Metadata.Name,
+			phases: []**pkg.OrderedConfig{&c.OnStartup, &c.OnBeforeHelm, &c.OnAfterHelm, &c.OnBeforeDeleteHelm, &c.OnAfterDeleteHelm},
+		})
+	}
+
+	var errs error
+
+	for i, phase := range phaseNames {
+		configs := make(map[string]**pkg.OrderedConfig)
+		for _, hook := range hooks {
+			if *hook.phases[i] != nil {
+				configs[hook.name] = hook.phases[i]
+			}
+		}
+
+		errs = errors.Join(errs, resolvePhaseOrder(phase, configs))
+	}
+
+	return errs
+}
+
+func resolvePhaseOrder(phase string, configs map[string]**pkg.OrderedConfig) error {
+	successors := make(map[string][]string, len(configs))
+	inDegree := make(map[string]int, len(configs))
+
+	var errs error
+
+	addEdge := func(hook, direction string, ref pkg.HookRef, from, to string) {
+		name := ""
+		if ref != nil {
+			name = ref.RefName()
+		}
+
+		switch {
+		case name == "":
+			errs = errors.Join(errs, fmt.Errorf("%s of hook %q: %s reference is empty or the hook is not registered yet", phase, hook, direction))
+			return
+		case name == hook:
+			errs = errors.Join(errs, fmt.Errorf("%s of hook %q: %s references the hook itself", phase, hook, direction))
+			return
+		case configs[name] == nil:
+			errs = errors.Join(errs, fmt.Errorf("%s of hook %q: %s %q: hook is not registered or has no %s binding", phase, hook, direction, name, phase))
+			return
+		}
+
+		if from == "" {
+			from = name
+		} else {
+			to = name
+		}
+
+		successors[from] = append(successors[from], to)
+		inDegree[to]++
+	}
+
+	names := make([]string, 0, len(configs))
+	for name := range configs {
+		names = append(names, name)
+	}
+
+	// sorted names keep errors and orders stable
+	slices.Sort(names)
+
+	hasDependencies := false
+
+	for _, name := range names {
+		cfg := configs[name]
+
+		for _, ref := range (*cfg).RunAfter {
+			hasDependencies = true
+			addEdge(name, "runAfter", ref, "", name)
+		}
+
+		for _, ref := range (*cfg).RunBefore {
+			hasDependencies = true
+			addEdge(name, "runBefore", ref, name, "")
+		}
+	}
+
+	if errs != nil || !hasDependencies {
+		return errs
+	}
+
+	orders := make(map[string]uint, len(configs))
+	ready := make([]string, 0, len(configs))
+
+	for _, name := range names {
+		orders[name] = (*configs[name]).Order
+
+		if inDegree[name] == 0 {
+			ready = append(ready, name)
+		}
+	}
+
+	// Kahn's algorithm, ready hooks are processed in name order
+	processed := 0
+	for len(ready) > 0 {
+		name := ready[0]
+		ready = ready[1:]
+		processed++
+
+		for _, next := range successors[name] {
+			orders[next] = max(orders[next], orders[name]+1)
+
+			inDegree[next]--
+			if inDegree[next] == 0 {
+				idx, _ := slices.BinarySearch(ready, next)
+				ready = slices.Insert(ready, idx, next)
+			}
+		}
+	}
+
+	if processed < len(names) {
+		return fmt.Errorf("%s: dependency cycle: %s", phase, findCycle(names, successors, inDegree))
+	}
+
+	for _, name := range names {
+		if orders[name] == (*configs[name]).Order {
+			continue
+		}
+
+		// do not modify the config passed by the user
+		cfg := **configs[name]
+		cfg.Order = orders[name]
+		*configs[name] = &cfg
+	}
+
+	return nil
+}
+
+// findCycle returns a cycle among hooks left unprocessed by the topological sort, e.g. "a -> b -> a".
+func findCycle(names []string, successors map[string][]string, inDegree map[string]int) string {
+	predecessors := make(map[string][]string, len(names))
+	for _, name := range names {
+		for _, next := range successors[name] {
+			predecessors[next] = append(predecessors[next], name)
+		}
+	}
+
+	var start string
+	for _, name := range names {
+		if inDegree[name] > 0 {
+			start = name
+			break
+		}
+	}
+
+	// every unprocessed hook has an unprocessed predecessor,
+	// so walking predecessors from any of them ends up in a cycle
+	path := []string{start}
+	visited := map[string]int{start: 0}
+
+	for {
+		current := path[len(path)-1]
+
+		var prev string
+		for _, candidate := range predecessors[current] {
+			if inDegree[candidate] > 0 {
+				prev = candidate
+				break
+			}
+		}
+
+		if idx, ok := visited[prev]; ok {
+			cycle := append(path[idx:], prev)
+			slices.Reverse(cycle)
+
+			return strings.Join(cycle, " -> ")
+		}
+
+		visited[prev] = len(path)
+		path = append(path, prev)
+	}
+}
@@ -0,0 +1,128 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/deckhouse/module-sdk/pkg"
+)
+
+func newModuleHook(name string, onBeforeHelm *pkg.OrderedConfig) pkg.Hook[pkg.HookConfig, *pkg.HookInput] {
+	return pkg.Hook[pkg.HookConfig, *pkg.HookInput]{
+		Config: pkg.HookConfig{
+			Metadata:     pkg.HookMetadata{Name: name},
+			OnBeforeHelm: onBeforeHelm,
+		},
+	}
+}
+
+func beforeHelmOrders(r *HookRegistry) map[string]uint {
+	orders := make(map[string]uint)
+	for _, h := range r.moduleHooks {
+		if h.Config.OnBeforeHelm != nil {
+			orders[h.Config.Metadata.Name] = h.Config.OnBeforeHelm.Order
+		}
+	}
+
+	for _, h := range r.appHooks {
+		if h.Config.OnBeforeHelm != nil {
+			orders[h.Config.Metadata.Name] = h.Config.OnBeforeHelm.Order
+		}
+	}
+
+	return orders
+}
+
+func TestResolveOrder(t *testing.T) {
+	t.Run("dependencies are sorted topologically", func(t *testing.T) {
+		tls := &Handle{name: "tls-certificate"}
+		userConfig := &pkg.OrderedConfig{RunAfter: []pkg.HookRef{tls, pkg.HookName("external-auth")}}
+
+		r := &HookRegistry{
+			moduleHooks: []pkg.Hook[pkg.HookConfig, *pkg.HookInput]{
+				newModuleHook("tls-certificate", &pkg.OrderedConfig{Order: 5}),
+				newModuleHook("external-auth", &pkg.OrderedConfig{Order: 9}),
+				newModuleHook("render-config", userConfig),
+				newModuleHook("crds", &pkg.OrderedConfig{Order: 10, RunBefore: []pkg.HookRef{tls}}),
+				newModuleHook("independent", &pkg.OrderedConfig{Order: 3}),
+			},
+			appHooks: []pkg.Hook[pkg.ApplicationHookConfig, *pkg.ApplicationHookInput]{
+				{Config: pkg.ApplicationHookConfig{
+					Metadata:     pkg.HookMetadata{Name: "app-hook"},
+					OnBeforeHelm: &pkg.OrderedConfig{RunBefore: []pkg.HookRef{pkg.HookName("crds")}},
+				}},
+			},
+		}
+
+		require.NoError(t, r.ResolveOrder())
+
+		assert.Equal(t, map[string]uint{
+			"app-hook":        0,
+			"crds":            10,
+			"tls-certificate": 11,
+			"external-auth":   9,
+			"render-config":   12,
+			"independent":     3,
+		}, beforeHelmOrders(r))
+
+		// the config passed by the user is not modified
+		assert.Equal(t, uint(0), userConfig.Order)
+	})
+
+	t.Run("phases are resolved separately", func(t *testing.T) {
+		r := &HookRegistry{
+			moduleHooks: []pkg.Hook[pkg.HookConfig, *pkg.HookInput]{
+				{Config: pkg.HookConfig{
+					Metadata:    pkg.HookMetadata{Name: "a"},
+					OnStartup:   &pkg.OrderedConfig{Order: 1},
+					OnAfterHelm: &pkg.OrderedConfig{Order: 1, RunAfter: []pkg.HookRef{pkg.HookName("b")}},
+				}},
+				{Config: pkg.HookConfig{
+					Metadata:    pkg.HookMetadata{Name: "b"},
+					OnStartup:   &pkg.OrderedConfig{Order: 7},
+					OnAfterHelm: &pkg.OrderedConfig{Order: 7},
+				}},
+			},
+		}
+
+		require.NoError(t, r.ResolveOrder())
+		assert.Equal(t, uint(1), r.moduleHooks[0].Config.OnStartup.Order)
+		assert.Equal(t, uint(8), r.moduleHooks[0].Config.OnAfterHelm.Order)
+		assert.Equal(t, uint(7), r.moduleHooks[1].Config.OnAfterHelm.Order)
+	})
+
+	t.Run("cycle is reported", func(t *testing.T) {
+		r := &HookRegistry{
+			moduleHooks: []pkg.Hook[pkg.HookConfig, *pkg.HookInput]{
+				newModuleHook("a", &pkg.OrderedConfig{RunAfter: []pkg.HookRef{pkg.HookName("c")}}),
+				newModuleHook("b", &pkg.OrderedConfig{RunAfter: []pkg.HookRef{pkg.HookName("a")}}),
+				newModuleHook("c", &pkg.OrderedConfig{RunAfter: []pkg.HookRef{pkg.HookName("b")}}),
+				newModuleHook("d", &pkg.OrderedConfig{RunAfter: []pkg.HookRef{pkg.HookName("c")}}),
+			},
+		}
+
+		err := r.ResolveOrder()
+		assert.EqualError(t, err, "OnBeforeHelm: dependency cycle: a -> b -> c -> a")
+	})
+
+	t.Run("invalid references are reported", func(t *testing.T) {
+		r := &HookRegistry{
+			moduleHooks: []pkg.Hook[pkg.HookConfig, *pkg.HookInput]{
+				newModuleHook("a", &pkg.OrderedConfig{RunAfter: []pkg.HookRef{pkg.HookName("a")}}),
+				newModuleHook("b", &pkg.OrderedConfig{RunBefore: []pkg.HookRef{pkg.HookName("missing")}}),
+				newModuleHook("c", &pkg.OrderedConfig{RunAfter: []pkg.HookRef{(*Handle)(nil)}}),
+				{Config: pkg.HookConfig{Metadata: pkg.HookMetadata{Name: "startup"}, OnStartup: &pkg.OrderedConfig{}}},
+				newModuleHook("d", &pkg.OrderedConfig{RunAfter: []pkg.HookRef{pkg.HookName("startup")}}),
+			},
+		}
+
+		err := r.ResolveOrder()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `OnBeforeHelm of hook "a": runAfter references the hook itself`)
+		assert.Contains(t, err.Error(), `OnBeforeHelm of hook "b": runBefore "missing": hook is not registered or has no OnBeforeHelm binding`)
+		assert.Contains(t, err.Error(), `OnBeforeHelm of hook "c": runAfter reference is empty or the hook is not registered yet`)
+		assert.Contains(t, err.Error(), `OnBeforeHelm of hook "d": runAfter "startup": hook is not registered or has no OnBeforeHelm binding`)
+	})
+}
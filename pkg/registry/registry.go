@@ -1,6 +1,7 @@
 package registry
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"runtime"
@@ -53,16 +54,25 @@ func (h *HookRegistry) ApplicationHooks() []pkg.Hook[pkg.ApplicationHookConfig,
 
 // RegisterFunc registers a hook with the global registry.
 // It accepts both module and application hook configurations.
+// Middlewares wrap only this hook, they run inside the global ones set with app.WithMiddlewares.
 // Returns true to allow usage in var declarations: var _ = registry.RegisterFunc(...)
-func RegisterFunc[C pkg.Config, T pkg.Input](config C, f pkg.HookFunc[T]) bool {
-	registerHook(Registry(), config, f)
+func RegisterFunc[C pkg.Config, T pkg.Input](config C, f pkg.HookFunc[T], middlewares ...pkg.Middleware) bool {
+	registerHook(Registry(), config, f, middlewares...)
 	return true
 }
 
-// registerHook validates and registers a hook with the given registry.
+// Register registers a hook with the global registry like RegisterFunc.
+// It returns a handle which other hooks can use in RunAfter and RunBefore,
+// so packages with reusable hooks can export it.
+func Register[C pkg.Config, T pkg.Input](config C, f pkg.HookFunc[T], middlewares ...pkg.Middleware) *Handle {
+	return &Handle{name: registerHook(Registry(), config, f, middlewares...)}
+}
+
+// registerHook validates and registers a hook with the given registry and returns the hook name.
 // It handles both pointer and value config types through type switching.
-// Panics if validation fails or if OnStartup and Kubernetes bindings are mixed.
-func registerHook[C pkg.Config, T pkg.Input](r *HookRegistry, cfg C, f pkg.HookFunc[T]) {
+// Panics if validation fails, if the hook name is already registered
+// or if OnStartup and Kubernetes bindings are mixed.
+func registerHook[C pkg.Config, T pkg.Input](r *HookRegistry, cfg C, f pkg.HookFunc[T], middlewares ...pkg.Middleware) string {
 	// Phase 1: Validate OnStartup + Kubernetes conflict before extracting metadata.
 	// This check must happen first to ensure proper panic ordering.
 	switch c := any(cfg).(type) {
@@ -96,51 +106,89 @@ func registerHook[C pkg.Config, T pkg.Input](r *HookRegistry, cfg C, f pkg.HookF
 	switch c := any(cfg).(type) {
 	case *pkg.HookConfig:
 		c.Metadata = meta
-		if err := c.Validate(); err != nil {
-			panic(fmt.Sprintf("hook validation failed for %q: %v", c.Metadata.Name, err))
+		if err := errors.Join(r.validateHookName(meta.Name), c.Validate()); err != nil {
+			panic(validationPanicMsg(meta.Name, err))
 		}
 		hook := pkg.Hook[pkg.HookConfig, *pkg.HookInput]{
-			Config:   *c,
-			HookFunc: any(f).(pkg.HookFunc[*pkg.HookInput]),
+			Config:      *c,
+			HookFunc:    any(f).(pkg.HookFunc[*pkg.HookInput]),
+			Middlewares: middlewares,
 		}
 		r.moduleHooks = append(r.moduleHooks, hook)
 
 	case pkg.HookConfig:
 		c.Metadata = meta
-		if err := c.Validate(); err != nil {
-			panic(fmt.Sprintf("hook validation failed for %q: %v", c.Metadata.Name, err))
+		if err := errors.Join(r.validateHookName(meta.Name), c.Validate()); err != nil {
+			panic(validationPanicMsg(meta.Name, err))
 		}
 		hook := pkg.Hook[pkg.HookConfig, *pkg.HookInput]{
-			Config:   c,
-			HookFunc: any(f).(pkg.HookFunc[*pkg.HookInput]),
+			Config:      c,
+			HookFunc:    any(f).(pkg.HookFunc[*pkg.HookInput]),
+			Middlewares: middlewares,
 		}
 		r.moduleHooks = append(r.moduleHooks, hook)
 
 	case *pkg.ApplicationHookConfig:
 		c.Metadata = meta
-		if err := c.Validate(); err != nil {
-			panic(fmt.Sprintf("hook validation failed for %q: %v", c.Metadata.Name, err))
+		if err := errors.Join(r.validateHookName(meta.Name), c.Validate()); err != nil {
+			panic(validationPanicMsg(meta.Name, err))
 		}
 		hook := pkg.Hook[pkg.ApplicationHookConfig, *pkg.ApplicationHookInput]{
-			Config:   *c,
-			HookFunc: any(f).(pkg.HookFunc[*pkg.ApplicationHookInput]),
+			Config:      *c,
+			HookFunc:    any(f).(pkg.HookFunc[*pkg.ApplicationHookInput]),
+			Middlewares: middlewares,
 		}
 		r.appHooks = append(r.appHooks, hook)
 
 	case pkg.ApplicationHookConfig:
 		c.Metadata = meta
-		if err := c.Validate(); err != nil {
-			panic(fmt.Sprintf("hook validation failed for %q: %v", c.Metadata.Name, err))
+		if err := errors.Join(r.validateHookName(meta.Name), c.Validate()); err != nil {
+			panic(validationPanicMsg(meta.Name, err))
 		}
 		hook := pkg.Hook[pkg.ApplicationHookConfig, *pkg.ApplicationHookInput]{
-			Config:   c,
-			HookFunc: any(f).(pkg.HookFunc[*pkg.ApplicationHookInput]),
+			Config:      c,
+			HookFunc:    any(f).(pkg.HookFunc[*pkg.ApplicationHookInput]),
+			Middlewares: middlewares,
 		}
 		r.appHooks = append(r.appHooks, hook)
 
 	default:
 		panic("unknown hook config type")
 	}
+
+	return meta.Name
+}
+
+// validateHookName checks that the hook name is not used by already registered hooks.
+// Hook names are used to address hooks in the deckhouse, so they must be unique.
+// The caller must hold r.mtx.
+func (r *HookRegistry) validateHookName(name string) error {
+	for _, h := range r.moduleHooks {
+		if h.Config.Metadata.Name == name {
+			return fmt.Errorf("hook name %q is already registered by module hook at %q", name, h.Config.Metadata.Path)
+		}
+	}
+
+	for _, h := range r.appHooks {
+		if h.Config.Metadata.Name == name {
+			return fmt.Errorf("hook name %q is already registered by application hook at %q", name, h.Config.Metadata.Path)
+		}
+	}
+
+	return nil
+}
+
+// validationPanicMsg formats the aggregated validation error with one problem per line.
+func validationPanicMsg(hookName string, err error) string {
+	sb := new(strings.Builder)
+	fmt.Fprintf(sb, "hook validation failed for %q:", hookName)
+
+	for _, line := range strings.Split(err.Error(), "\n") {
+		sb.WriteString("\n  - ")
+		sb.WriteString(line)
+	}
+
+	return sb.String()
 }
 
 // extractHookMetadata walks the call stack to extract hook name and path.
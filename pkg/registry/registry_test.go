@@ -2,6 +2,7 @@ package registry
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -71,3 +72,18 @@ func TestRegister(t *testing.T) {
 		})
 	})
 }
+
+func TestValidationPanicMsg(t *testing.T) {
+	err := errors.Join(
+		errors.New(`hook name "002-hook/main" is already registered by module hook at "hooks/002-hook/"`),
+		errors.Join(
+			errors.New("schedule with name 'cron': crontab is not valid"),
+			errors.New("kubernetes config with name 'pods': jqFilter '.{' is not valid"),
+		),
+	)
+
+	assert.Equal(t, `hook validation failed for "002-hook/main":
+  - hook name "002-hook/main" is already registered by module hook at "hooks/002-hook/"
+  - schedule with name 'cron': crontab is not valid
+  - kubernetes config with name 'pods': jqFilter '.{' is not valid`, validationPanicMsg("002-hook/main", err))
+}
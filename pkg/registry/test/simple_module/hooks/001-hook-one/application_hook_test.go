@@ -36,6 +36,15 @@ func TestApplicationHookRegistration(t *testing.T) {
 	})
 
 	t.Run("Application hook metadata is extracted from call stack", func(t *testing.T) {
+		// the hook is registered by the previous subtest
+		appHooks := registry.Registry().ApplicationHooks()
+		require.GreaterOrEqual(t, len(appHooks), 1, "at least one application hook should be registered")
+		registered := appHooks[len(appHooks)-1]
+		assert.NotEmpty(t, registered.Config.Metadata.Name, "Metadata.Name should be set by extractHookMetadata from call stack")
+		assert.NotEmpty(t, registered.Config.Metadata.Path, "Metadata.Path should be set by extractHookMetadata from call stack")
+	})
+
+	t.Run("Hook with already registered name should panic", func(t *testing.T) {
 		hook := &pkg.ApplicationHookConfig{
 			Kubernetes: []pkg.ApplicationKubernetesConfig{
 				{
@@ -46,14 +55,15 @@ func TestApplicationHookRegistration(t *testing.T) {
 			},
 		}
 
+		defer func() {
+			r := recover()
+			require.NotEmpty(t, r)
+			assert.Contains(t, r, `hook name "001-hook-one/application_hook_test" is already registered by application hook`)
+		}()
+
+		// hooks registered from the same file get the same name
 		registry.RegisterFunc(hook, func(_ context.Context, _ *pkg.ApplicationHookInput) error {
 			return nil
 		})
-
-		appHooks := registry.Registry().ApplicationHooks()
-		require.GreaterOrEqual(t, len(appHooks), 1, "at least one application hook should be registered")
-		registered := appHooks[len(appHooks)-1]
-		assert.NotEmpty(t, registered.Config.Metadata.Name, "Metadata.Name should be set by extractHookMetadata from call stack")
-		assert.NotEmpty(t, registered.Config.Metadata.Path, "Metadata.Path should be set by extractHookMetadata from call stack")
 	})
 }
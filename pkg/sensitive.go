@@ -0,0 +1,71 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// RedactedValue replaces sensitive values in logs and debug output.
+const RedactedValue = "[REDACTED]"
+
+var (
+	_ fmt.Stringer   = Sensitive("")
+	_ fmt.GoStringer = Sensitive("")
+	_ slog.LogValuer = Sensitive("")
+	_ json.Marshaler = Sensitive("")
+)
+
+// Sensitive is a string which must not be logged, e.g. a TLS key, a password or registry auth.
+// It is marshaled to JSON as is, so it can be set into values, and redacted when formatted
+// or logged. Values paths set to Sensitive are redacted in debug output of values patches.
+type Sensitive string
+
+func (s Sensitive) String() string {
+	return RedactedValue
+}
+
+func (s Sensitive) GoString() string {
+	return RedactedValue
+}
+
+func (s Sensitive) LogValue() slog.Value {
+	return slog.StringValue(RedactedValue)
+}
+
+func (s Sensitive) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(s))
+}
+
+// SensitiveConfig marks values and snapshot fields which are redacted in debug output
+// of the hook run and in the testing framework dumps.
+type SensitiveConfig struct {
+	// Values are paths of values and config values in the gjson-style dotted syntax,
+	// "*" matches any key or array element, e.g. "myModule.internal.certificates.*.key".
+	Values []string
+	// Snapshots are paths of fields of snapshot objects or filter results by binding name,
+	// e.g. {"secrets": {"data"}}.
+	Snapshots map[string][]string
+}
+
+// Validate checks the SensitiveConfig for errors.
+func (cfg *SensitiveConfig) Validate() error {
+	var errs error
+
+	for _, path := range cfg.Values {
+		if path == "" {
+			errs = errors.Join(errs, errors.New("sensitive values path must not be empty"))
+		}
+	}
+
+	for binding, paths := range cfg.Snapshots {
+		for _, path := range paths {
+			if path == "" {
+				errs = errors.Join(errs, fmt.Errorf("sensitive snapshot path of binding '%s' must not be empty", binding))
+			}
+		}
+	}
+
+	return errs
+}
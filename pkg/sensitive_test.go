@@ -0,0 +1,45 @@
+package pkg_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/deckhouse/module-sdk/pkg"
+)
+
+func TestSensitive(t *testing.T) {
+	password := pkg.Sensitive("s3cr3t")
+
+	assert.Equal(t, "[REDACTED]", fmt.Sprint(password))
+	assert.Equal(t, "[REDACTED] [REDACTED] [REDACTED]", fmt.Sprintf("%v %#v %s", password, password, password))
+
+	buf := bytes.NewBuffer(nil)
+	slog.New(slog.NewJSONHandler(buf, nil)).Info("generated", slog.Any("password", password))
+	assert.Contains(t, buf.String(), `"password":"[REDACTED]"`)
+	assert.NotContains(t, buf.String(), "s3cr3t")
+
+	data, err := json.Marshal(map[string]any{"password": password})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"password":"s3cr3t"}`, string(data))
+}
+
+func TestSensitiveConfigValidate(t *testing.T) {
+	cfg := &pkg.SensitiveConfig{
+		Values:    []string{"myModule.internal.password"},
+		Snapshots: map[string][]string{"secrets": {"data"}},
+	}
+	assert.NoError(t, cfg.Validate())
+
+	cfg = &pkg.SensitiveConfig{
+		Values:    []string{""},
+		Snapshots: map[string][]string{"secrets": {""}},
+	}
+	assert.EqualError(t, cfg.Validate(), "sensitive values path must not be empty\n"+
+		"sensitive snapshot path of binding 'secrets' must not be empty")
+}
@@ -0,0 +1,28 @@
+package patch
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gomodules.xyz/jsonpatch/v2"
+)
+
+// Diff creates a JSON patch which transforms the original document into the modified one.
+func Diff(original, modified []byte) (Patch, error) {
+	ops, err := jsonpatch.CreatePatch(original, modified)
+	if err != nil {
+		return nil, fmt.Errorf("create patch: %w", err)
+	}
+
+	raw, err := json.Marshal(ops)
+	if err != nil {
+		return nil, fmt.Errorf("marshal patch: %w", err)
+	}
+
+	p := make(Patch, 0, len(ops))
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return nil, fmt.Errorf("unmarshal patch: %w", err)
+	}
+
+	return p, nil
+}
@@ -0,0 +1,320 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/uuid"
+
+	"github.com/deckhouse/module-sdk/internal/admission"
+	"github.com/deckhouse/module-sdk/pkg"
+	"github.com/deckhouse/module-sdk/pkg/utils/patch"
+)
+
+// admissionBinding is a common view of KubernetesValidating and KubernetesMutating bindings.
+type admissionBinding struct {
+	name              string
+	group             string
+	rules             []admissionregv1.RuleWithOperations
+	namespaceSelector *pkg.NamespaceSelector
+	labelSelector     *metav1.LabelSelector
+}
+
+// RunHookOnValidating simulates an admission request for the object defined
+// in the provided YAML document and runs the hook with the first
+// KubernetesValidating binding whose rules and selectors match the object.
+//
+// For UPDATE and DELETE requests the current object from the fake cluster is
+// passed as OldObject; the fake cluster itself is not modified. Use
+// ValidatingResponse to assert the hook decision.
+//
+// It returns false if no binding matches the request; the hook is not
+// executed in this case.
+func (h *HookExecutionConfig) RunHookOnValidating(operation admissionv1.Operation, yamlObject string) bool {
+	h.t.Helper()
+	return h.RunHookOnValidatingCtx(context.Background(), operation, yamlObject)
+}
+
+// RunHookOnValidatingCtx is like RunHookOnValidating but accepts an explicit context.
+func (h *HookExecutionConfig) RunHookOnValidatingCtx(ctx context.Context, operation admissionv1.Operation, yamlObject string) bool {
+	h.t.Helper()
+
+	var bindings []admissionBinding
+	if h.hookConfig != nil {
+		for _, b := range h.hookConfig.KubernetesValidating {
+			bindings = append(bindings, admissionBinding{
+				name:              b.Name,
+				group:             b.Group,
+				rules:             b.Rules,
+				namespaceSelector: b.NamespaceSelector,
+				labelSelector:     b.LabelSelector,
+			})
+		}
+	}
+
+	return h.runHookOnAdmission(ctx, pkg.BindingTypeKubernetesValidating, pkg.KubeEventTypeValidating, bindings, operation, yamlObject)
+}
+
+// RunHookOnMutating simulates an admission request for the object defined
+// in the provided YAML document and runs the hook with the first
+// KubernetesMutating binding whose rules and selectors match the object.
+//
+// For UPDATE and DELETE requests the current object from the fake cluster is
+// passed as OldObject; the fake cluster itself is not modified. Use
+// MutatingResponse, MutatingPatch and MutatedObject to assert the result.
+//
+// It returns false if no binding matches the request; the hook is not
+// executed in this case.
+func (h *HookExecutionConfig) RunHookOnMutating(operation admissionv1.Operation, yamlObject string) bool {
+	h.t.Helper()
+	return h.RunHookOnMutatingCtx(context.Background(), operation, yamlObject)
+}
+
+// RunHookOnMutatingCtx is like RunHookOnMutating but accepts an explicit context.
+func (h *HookExecutionConfig) RunHookOnMutatingCtx(ctx context.Context, operation admissionv1.Operation, yamlObject string) bool {
+	h.t.Helper()
+
+	var bindings []admissionBinding
+	if h.hookConfig != nil {
+		for _, b := range h.hookConfig.KubernetesMutating {
+			bindings = append(bindings, admissionBinding{
+				name:              b.Name,
+				group:             b.Group,
+				rules:             b.Rules,
+				namespaceSelector: b.NamespaceSelector,
+				labelSelector:     b.LabelSelector,
+			})
+		}
+	}
+
+	return h.runHookOnAdmission(ctx, pkg.BindingTypeKubernetesMutating, pkg.KubeEventTypeMutating, bindings, operation, yamlObject)
+}
+
+// ValidatingResponse returns the response of the most recent
+// RunHookOnValidating call, or nil if the hook was not run on a validating request.
+func (h *HookExecutionConfig) ValidatingResponse() *pkg.ValidatingResponse {
+	if h.validatingReview == nil {
+		return nil
+	}
+
+	res := h.validatingReview.Response()
+
+	return &res
+}
+
+// MutatingResponse returns the response of the most recent
+// RunHookOnMutating call, or nil if the hook was not run on a mutating request.
+func (h *HookExecutionConfig) MutatingResponse() *pkg.MutatingResponse {
+	if h.mutatingReview == nil {
+		return nil
+	}
+
+	res := h.mutatingReview.Response()
+
+	return &res
+}
+
+// MutatingPatch returns the JSON patch produced by the hook on the most recent
+// RunHookOnMutating call, or nil if the hook did not patch the object.
+func (h *HookExecutionConfig) MutatingPatch() patch.Patch {
+	h.t.Helper()
+
+	res := h.MutatingResponse()
+	if res == nil || len(res.Patch) == 0 {
+		return nil
+	}
+
+	var p patch.Patch
+	if err := json.Unmarshal(res.Patch, &p); err != nil {
+		h.t.Fatalf("framework: decode mutating patch: %v", err)
+	}
+
+	return p
+}
+
+// MutatedObject returns the requested object with the JSON patch produced by
+// the hook applied, or nil if the hook was not run on a mutating request.
+func (h *HookExecutionConfig) MutatedObject() *unstructured.Unstructured {
+	h.t.Helper()
+
+	if h.mutatingReview == nil {
+		return nil
+	}
+
+	raw := h.mutatingReview.Request().Object.Raw
+
+	if p := h.MutatingPatch(); len(p) > 0 {
+		var err error
+		raw, err = p.Apply(raw)
+		if err != nil {
+			h.t.Fatalf("framework: apply mutating patch: %v", err)
+		}
+	}
+
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(raw); err != nil {
+		h.t.Fatalf("framework: decode mutated object: %v", err)
+	}
+
+	return obj
+}
+
+func (h *HookExecutionConfig) runHookOnAdmission(
+	ctx context.Context,
+	bindingType pkg.BindingType,
+	contextType pkg.KubeEventType,
+	bindings []admissionBinding,
+	operation admissionv1.Operation,
+	yamlObject string,
+) bool {
+	h.t.Helper()
+
+	objs, err := parseYAMLDocuments(yamlObject)
+	if err != nil {
+		h.t.Fatalf("framework: parse admission object: %v", err)
+	}
+	if len(objs) != 1 {
+		h.t.Fatalf("framework: admission request expects exactly one object, got %d", len(objs))
+	}
+	obj := &objs[0]
+
+	request, err := h.buildAdmissionRequest(ctx, operation, obj)
+	if err != nil {
+		h.t.Fatalf("framework: build admission request: %v", err)
+	}
+
+	var matched *admissionBinding
+	for i := range bindings {
+		ok, err := h.admissionBindingMatches(ctx, &bindings[i], request, obj)
+		if err != nil {
+			h.t.Fatalf("framework: binding %q: %v", bindings[i].name, err)
+		}
+		if ok {
+			matched = &bindings[i]
+			break
+		}
+	}
+
+	h.validatingReview = nil
+	h.mutatingReview = nil
+
+	if matched == nil {
+		return false
+	}
+
+	snaps, _, err := h.generateSnapshots(ctx)
+	if err != nil {
+		h.t.Fatalf("framework: generate snapshots: %v", err)
+	}
+
+	switch contextType {
+	case pkg.KubeEventTypeValidating:
+		h.validatingReview = admission.NewValidatingReview(request)
+	case pkg.KubeEventTypeMutating:
+		h.mutatingReview = admission.NewMutatingReview(request)
+	}
+
+	h.runHandler(ctx, snaps, []pkg.BindingContext{{
+		Binding:     matched.name,
+		BindingType: bindingType,
+		Type:        contextType,
+		Group:       matched.group,
+	}})
+
+	return true
+}
+
+// buildAdmissionRequest fills the admission request the same way the API server does.
+func (h *HookExecutionConfig) buildAdmissionRequest(ctx context.Context, operation admissionv1.Operation, obj *unstructured.Unstructured) (*admissionv1.AdmissionRequest, error) {
+	gvr, err := h.gvrFor(obj.GetAPIVersion(), obj.GetKind())
+	if err != nil {
+		return nil, err
+	}
+	gvk := obj.GroupVersionKind()
+
+	request := &admissionv1.AdmissionRequest{
+		UID:       uuid.NewUUID(),
+		Kind:      metav1.GroupVersionKind{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind},
+		Resource:  metav1.GroupVersionResource{Group: gvr.Group, Version: gvr.Version, Resource: gvr.Resource},
+		Name:      obj.GetName(),
+		Namespace: obj.GetNamespace(),
+		Operation: operation,
+	}
+
+	if operation != admissionv1.Delete {
+		raw, err := json.Marshal(obj.UnstructuredContent())
+		if err != nil {
+			return nil, fmt.Errorf("marshal object: %w", err)
+		}
+		request.Object = runtime.RawExtension{Raw: raw}
+	}
+
+	if operation == admissionv1.Update || operation == admissionv1.Delete {
+		if existing := h.existingObject(ctx, obj); existing != nil {
+			raw, err := json.Marshal(existing.UnstructuredContent())
+			if err != nil {
+				return nil, fmt.Errorf("marshal old object: %w", err)
+			}
+			request.OldObject = runtime.RawExtension{Raw: raw}
+		}
+	}
+
+	return request, nil
+}
+
+// admissionBindingMatches reports whether the API server would send the request to the binding webhook.
+func (h *HookExecutionConfig) admissionBindingMatches(ctx context.Context, b *admissionBinding, request *admissionv1.AdmissionRequest, obj *unstructured.Unstructured) (bool, error) {
+	if !slices.ContainsFunc(b.rules, func(rule admissionregv1.RuleWithOperations) bool {
+		return admissionRuleMatches(rule, request)
+	}) {
+		return false, nil
+	}
+
+	if b.labelSelector != nil {
+		sel, err := metav1.LabelSelectorAsSelector(b.labelSelector)
+		if err != nil {
+			return false, fmt.Errorf("parse label selector: %w", err)
+		}
+		if !sel.Matches(labels.Set(obj.GetLabels())) {
+			return false, nil
+		}
+	}
+
+	if b.namespaceSelector != nil && b.namespaceSelector.LabelSelector != nil && obj.GetNamespace() != "" {
+		namespaces, err := h.namespacesForBinding(ctx, &pkg.KubernetesConfig{
+			NamespaceSelector: &pkg.NamespaceSelector{LabelSelector: b.namespaceSelector.LabelSelector},
+		})
+		if err != nil {
+			return false, err
+		}
+		if !slices.Contains(namespaces, obj.GetNamespace()) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func admissionRuleMatches(rule admissionregv1.RuleWithOperations, request *admissionv1.AdmissionRequest) bool {
+	operations := make([]string, 0, len(rule.Operations))
+	for _, op := range rule.Operations {
+		operations = append(operations, string(op))
+	}
+
+	return matchesWildcard(operations, string(request.Operation)) &&
+		matchesWildcard(rule.APIGroups, request.Resource.Group) &&
+		matchesWildcard(rule.APIVersions, request.Resource.Version) &&
+		matchesWildcard(rule.Resources, request.Resource.Resource)
+}
+
+func matchesWildcard(values []string, value string) bool {
+	return slices.Contains(values, "*") || slices.Contains(values, value)
+}
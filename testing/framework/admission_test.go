@@ -0,0 +1,174 @@
+package framework_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/deckhouse/module-sdk/pkg"
+	"github.com/deckhouse/module-sdk/pkg/utils/patch"
+	"github.com/deckhouse/module-sdk/testing/framework"
+)
+
+const labeledPod = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: app
+  namespace: default
+  labels:
+    app: web
+`
+
+var podRules = []admissionregv1.RuleWithOperations{
+	{
+		Operations: []admissionregv1.OperationType{admissionregv1.Create, admissionregv1.Update},
+		Rule: admissionregv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"pods"},
+		},
+	},
+}
+
+func mustPatch(t *testing.T, raw string) patch.Patch {
+	t.Helper()
+
+	var p patch.Patch
+	require.NoError(t, json.Unmarshal([]byte(raw), &p))
+
+	return p
+}
+
+func TestRunHookOnValidating(t *testing.T) {
+	config := &pkg.HookConfig{
+		KubernetesValidating: []pkg.ValidatingConfig{
+			{Name: "pods.example.deckhouse.io", Rules: podRules},
+		},
+	}
+
+	var got []pkg.BindingContext
+	handler := func(_ context.Context, input *pkg.HookInput) error {
+		got = input.BindingContexts
+		assert.Nil(t, input.Mutation)
+
+		if input.Review.Request().Operation == admissionv1.Update {
+			input.Review.Deny("pods are immutable")
+		}
+
+		return nil
+	}
+
+	hec := framework.HookExecutionConfigInit(t, config, handler, `{}`, `{}`)
+
+	require.True(t, hec.RunHookOnValidating(admissionv1.Create, labeledPod))
+	require.NoError(t, hec.HookError())
+	require.Len(t, got, 1)
+	assert.True(t, got[0].IsValidating())
+	assert.Equal(t, "pods.example.deckhouse.io", got[0].Binding)
+	assert.Equal(t, &pkg.ValidatingResponse{Allowed: true}, hec.ValidatingResponse())
+
+	require.True(t, hec.RunHookOnValidating(admissionv1.Update, labeledPod))
+	assert.Equal(t, &pkg.ValidatingResponse{Message: "pods are immutable"}, hec.ValidatingResponse())
+
+	// DELETE is not listed in the rules
+	assert.False(t, hec.RunHookOnValidating(admissionv1.Delete, labeledPod))
+	assert.Nil(t, hec.ValidatingResponse())
+}
+
+func TestRunHookOnMutating(t *testing.T) {
+	config := &pkg.HookConfig{
+		KubernetesMutating: []pkg.MutatingConfig{
+			{
+				Name:          "pods.example.deckhouse.io",
+				Rules:         podRules,
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			},
+		},
+	}
+
+	t.Run("patch", func(t *testing.T) {
+		handler := func(_ context.Context, input *pkg.HookInput) error {
+			assert.True(t, input.BindingContexts[0].IsMutating())
+			assert.Nil(t, input.Review)
+
+			return input.Mutation.Patch(mustPatch(t, `[
+				{"op": "add", "path": "/metadata/annotations", "value": {"example.io/mutated": "true"}}
+			]`), "annotation added")
+		}
+
+		hec := framework.HookExecutionConfigInit(t, config, handler, `{}`, `{}`)
+
+		require.True(t, hec.RunHookOnMutating(admissionv1.Create, labeledPod))
+		require.NoError(t, hec.HookError())
+
+		res := hec.MutatingResponse()
+		require.NotNil(t, res)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, []string{"annotation added"}, res.Warnings)
+		require.Len(t, hec.MutatingPatch(), 1)
+		assert.Equal(t, map[string]string{"example.io/mutated": "true"}, hec.MutatedObject().GetAnnotations())
+	})
+
+	t.Run("mutated object", func(t *testing.T) {
+		handler := func(_ context.Context, input *pkg.HookInput) error {
+			obj := &unstructured.Unstructured{}
+			if err := obj.UnmarshalJSON(input.Mutation.Request().Object.Raw); err != nil {
+				return err
+			}
+
+			obj.SetLabels(map[string]string{"app": "web", "tier": "frontend"})
+
+			return input.Mutation.Mutate(obj)
+		}
+
+		hec := framework.HookExecutionConfigInit(t, config, handler, `{}`, `{}`)
+
+		require.True(t, hec.RunHookOnMutating(admissionv1.Create, labeledPod))
+		require.NoError(t, hec.HookError())
+		raw, err := json.Marshal(hec.MutatingPatch())
+		require.NoError(t, err)
+		assert.JSONEq(t, `[{"op":"add","path":"/metadata/labels/tier","value":"frontend"}]`, string(raw))
+		assert.Equal(t, "frontend", hec.MutatedObject().GetLabels()["tier"])
+	})
+
+	t.Run("label selector does not match", func(t *testing.T) {
+		runs := 0
+		handler := func(_ context.Context, _ *pkg.HookInput) error {
+			runs++
+			return nil
+		}
+
+		hec := framework.HookExecutionConfigInit(t, config, handler, `{}`, `{}`)
+
+		assert.False(t, hec.RunHookOnMutating(admissionv1.Create, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: app
+  namespace: default
+`))
+		assert.Equal(t, 0, runs)
+		assert.Nil(t, hec.MutatingResponse())
+		assert.Nil(t, hec.MutatedObject())
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		handler := func(_ context.Context, _ *pkg.HookInput) error {
+			return nil
+		}
+
+		hec := framework.HookExecutionConfigInit(t, config, handler, `{}`, `{}`)
+
+		require.True(t, hec.RunHookOnMutating(admissionv1.Create, labeledPod))
+		assert.Nil(t, hec.MutatingPatch())
+		assert.Equal(t, "app", hec.MutatedObject().GetName())
+	})
+}
@@ -37,6 +37,7 @@ import (
 
 	"github.com/deckhouse/deckhouse/pkg/log"
 
+	"github.com/deckhouse/module-sdk/internal/admission"
 	"github.com/deckhouse/module-sdk/internal/metric"
 	"github.com/deckhouse/module-sdk/pkg"
 )
@@ -64,11 +65,18 @@ type HookExecutionConfig struct {
 
 	values       *valuesStore
 	configValues *valuesStore
+	// paths set to pkg.Sensitive values by the hook, they are redacted by Dump
+	sensitiveValuesPaths       [][]string
+	sensitiveConfigValuesPaths [][]string
 
 	patchCollector   *recordingPatchCollector
 	metricsCollector *metric.Collector
 	snapshots        snapshotsMap
+	bindingContexts  []pkg.BindingContext
+	validatingReview *admission.ValidatingReview
+	mutatingReview   *admission.MutatingReview
 	hookError        error
+	middlewares      []pkg.Middleware
 	loggerOutput     *bytes.Buffer
 	dc               *frameworkDC
 
@@ -118,6 +126,7 @@ func NewHookExecutionConfig(t testing.TB, config *pkg.HookConfig, handler HookFu
 		t:                  t,
 		hookConfig:         config,
 		hookHandler:        handler,
+		middlewares:        cfg.middlewares,
 		scheme:             scheme,
 		unstructuredScheme: unstructuredScheme,
 		gvrToListKind:      defaultGVRToListKind(scheme),
@@ -277,6 +286,10 @@ func (h *HookExecutionConfig) HookError() error { return h.hookError }
 // recent RunHook call.
 func (h *HookExecutionConfig) Snapshots() pkg.Snapshots { return h.snapshots }
 
+// BindingContexts returns the binding contexts that were passed to the hook
+// on the most recent RunHook call.
+func (h *HookExecutionConfig) BindingContexts() []pkg.BindingContext { return h.bindingContexts }
+
 // PatchOperations returns the patch operations recorded by the hook during
 // the most recent RunHook call.
 func (h *HookExecutionConfig) PatchOperations() []pkg.PatchCollectorOperation {
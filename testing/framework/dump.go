@@ -0,0 +1,45 @@
+package framework
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/deckhouse/module-sdk/internal/redact"
+	"github.com/deckhouse/module-sdk/pkg"
+)
+
+// Dump returns the current values, config values and the snapshots of the most
+// recent RunHook call as indented JSON. Sensitive values are redacted: paths listed
+// in the hook's pkg.SensitiveConfig and paths the hook set to pkg.Sensitive values.
+// Use it in failure messages instead of ValuesJSON, e.g.:
+//
+//	assert.True(t, hec.ValuesGet("myModule.internal.ready").Bool(), hec.Dump())
+func (h *HookExecutionConfig) Dump() string {
+	sensitive := &pkg.SensitiveConfig{}
+	if h.hookConfig != nil && h.hookConfig.Sensitive != nil {
+		sensitive = h.hookConfig.Sensitive
+	}
+
+	valuesPaths := redact.ParsePaths(sensitive.Values)
+
+	snapshots := make(map[string][]json.RawMessage, len(h.snapshots))
+	for binding, snaps := range h.snapshots {
+		redactor := redact.New(redact.ParsePaths(sensitive.Snapshots[binding])...)
+
+		for _, snap := range snaps {
+			snapshots[binding] = append(snapshots[binding], redactor.Raw([]byte(snap.String())))
+		}
+	}
+
+	data, err := json.MarshalIndent(map[string]any{
+		"values":       redact.New(slices.Concat(valuesPaths, h.sensitiveValuesPaths)...).Value(h.values.Map()),
+		"configValues": redact.New(slices.Concat(valuesPaths, h.sensitiveConfigValuesPaths)...).Value(h.configValues.Map()),
+		"snapshots":    snapshots,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("framework: dump: %v", err)
+	}
+
+	return string(data)
+}
@@ -0,0 +1,52 @@
+package framework_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/deckhouse/module-sdk/pkg"
+	"github.com/deckhouse/module-sdk/testing/framework"
+)
+
+func TestDumpRedaction(t *testing.T) {
+	config := &pkg.HookConfig{
+		Kubernetes: []pkg.KubernetesConfig{
+			{Name: "secrets", APIVersion: "v1", Kind: "Secret", JqFilter: `{"name": .metadata.name, "token": .data.token}`},
+		},
+		Sensitive: &pkg.SensitiveConfig{
+			Values:    []string{"myModule.internal.registry.auth"},
+			Snapshots: map[string][]string{"secrets": {"token"}},
+		},
+	}
+
+	handler := func(_ context.Context, input *pkg.HookInput) error {
+		input.Values.Set("myModule.internal.registry", map[string]string{"address": "registry.example.com", "auth": "registry-auth"})
+		input.Values.Set("myModule.internal.password", pkg.Sensitive("s3cr3t"))
+		return nil
+	}
+
+	hec := framework.HookExecutionConfigInit(t, config, handler, `{"myModule": {}}`, `{}`)
+	hec.KubeStateSet(`
+apiVersion: v1
+kind: Secret
+metadata:
+  name: token
+  namespace: default
+data:
+  token: c2VjcmV0LXRva2Vu
+`)
+
+	hec.RunHook()
+	require.NoError(t, hec.HookError())
+	assert.Equal(t, "s3cr3t", hec.ValuesGet("myModule.internal.password").String())
+
+	dump := hec.Dump()
+	assert.Contains(t, dump, "registry.example.com")
+	assert.Contains(t, dump, `"name": "token"`)
+	assert.NotContains(t, dump, "registry-auth")
+	assert.NotContains(t, dump, "s3cr3t")
+	assert.NotContains(t, dump, "c2VjcmV0LXRva2Vu")
+}
@@ -0,0 +1,235 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/deckhouse/module-sdk/pkg"
+	sdkjq "github.com/deckhouse/module-sdk/pkg/jq"
+)
+
+// RunHookOnEvent simulates a single Kubernetes watch event for the object
+// defined in the provided YAML document.
+//
+// The framework:
+//  1. Finds the KubernetesConfig bindings matching the object.
+//  2. Applies the event to the fake cluster (Added creates, Modified updates,
+//     Deleted removes the object).
+//  3. Runs the hook with one "Event" binding context per matching binding
+//     subscribed to the event type (see ExecuteHookOnEvents and
+//     ExecuteHookOnEventTypes). Grouped bindings produce a single "Group"
+//     binding context instead. Snapshots reflect the state after the event.
+//
+// It returns false if no binding is subscribed to the event; the hook is not
+// executed in this case, but snapshots are still refreshed.
+func (h *HookExecutionConfig) RunHookOnEvent(event pkg.WatchEventType, yamlObject string) bool {
+	h.t.Helper()
+	return h.RunHookOnEventCtx(context.Background(), event, yamlObject)
+}
+
+// RunHookOnEventCtx is like RunHookOnEvent but accepts an explicit context.
+func (h *HookExecutionConfig) RunHookOnEventCtx(ctx context.Context, event pkg.WatchEventType, yamlObject string) bool {
+	h.t.Helper()
+
+	objs, err := parseYAMLDocuments(yamlObject)
+	if err != nil {
+		h.t.Fatalf("framework: parse event object: %v", err)
+	}
+	if len(objs) != 1 {
+		h.t.Fatalf("framework: event expects exactly one object, got %d", len(objs))
+	}
+	obj := &objs[0]
+
+	// Deleted event carries the last known state of the object.
+	if event == pkg.WatchEventDeleted {
+		if existing := h.existingObject(ctx, obj); existing != nil {
+			obj = existing
+		}
+	}
+
+	bindings, err := h.bindingsForObject(ctx, obj)
+	if err != nil {
+		h.t.Fatalf("framework: match bindings: %v", err)
+	}
+
+	if err := h.applyWatchEvent(ctx, event, obj); err != nil {
+		h.t.Fatalf("framework: apply %s event: %v", event, err)
+	}
+
+	snaps, _, err := h.generateSnapshots(ctx)
+	if err != nil {
+		h.t.Fatalf("framework: generate snapshots: %v", err)
+	}
+
+	bindingContexts := make([]pkg.BindingContext, 0, len(bindings))
+	for _, b := range bindings {
+		if !executeHookOnEvent(b, event) {
+			continue
+		}
+
+		bc, err := buildEventBindingContext(ctx, b, event, obj)
+		if err != nil {
+			h.t.Fatalf("framework: binding %q: %v", b.Name, err)
+		}
+		bindingContexts = append(bindingContexts, bc)
+	}
+
+	bindingContexts = groupBindingContexts(bindingContexts)
+
+	h.validatingReview = nil
+	h.mutatingReview = nil
+
+	if len(bindingContexts) == 0 {
+		h.snapshots = snaps
+		h.bindingContexts = nil
+		return false
+	}
+
+	h.runHandler(ctx, snaps, bindingContexts)
+
+	return true
+}
+
+// executeHookOnEvent reports whether the binding triggers the hook on the event type.
+func executeHookOnEvent(b *pkg.KubernetesConfig, event pkg.WatchEventType) bool {
+	if b.ExecuteHookOnEvents != nil && !*b.ExecuteHookOnEvents {
+		return false
+	}
+
+	if len(b.ExecuteHookOnEventTypes) == 0 {
+		return true
+	}
+
+	return slices.Contains(b.ExecuteHookOnEventTypes, event)
+}
+
+// bindingsForObject returns KubernetesConfig bindings whose selectors match the object.
+func (h *HookExecutionConfig) bindingsForObject(ctx context.Context, obj *unstructured.Unstructured) ([]*pkg.KubernetesConfig, error) {
+	if h.hookConfig == nil {
+		return nil, nil
+	}
+
+	var out []*pkg.KubernetesConfig
+	for i := range h.hookConfig.Kubernetes {
+		b := &h.hookConfig.Kubernetes[i]
+
+		apiVersion := b.APIVersion
+		if apiVersion == "" {
+			apiVersion = "v1"
+		}
+		if obj.GetAPIVersion() != apiVersion || obj.GetKind() != b.Kind {
+			continue
+		}
+
+		if !matchesNameSelector(obj.GetName(), b.NameSelector) {
+			continue
+		}
+		if !matchesFieldSelector(obj, b.FieldSelector) {
+			continue
+		}
+
+		if b.LabelSelector != nil {
+			sel, err := metav1.LabelSelectorAsSelector(b.LabelSelector)
+			if err != nil {
+				return nil, fmt.Errorf("binding %q: parse label selector: %w", b.Name, err)
+			}
+			if !sel.Matches(labels.Set(obj.GetLabels())) {
+				continue
+			}
+		}
+
+		namespaces, err := h.namespacesForBinding(ctx, b)
+		if err != nil {
+			return nil, fmt.Errorf("binding %q: %w", b.Name, err)
+		}
+		if !slices.Contains(namespaces, "") && !slices.Contains(namespaces, obj.GetNamespace()) {
+			continue
+		}
+
+		out = append(out, b)
+	}
+
+	return out, nil
+}
+
+// existingObject returns the object stored in the fake cluster or nil.
+func (h *HookExecutionConfig) existingObject(ctx context.Context, obj *unstructured.Unstructured) *unstructured.Unstructured {
+	gvr, err := h.gvrFor(obj.GetAPIVersion(), obj.GetKind())
+	if err != nil {
+		return nil
+	}
+
+	existing, err := h.resourceInterface(gvr, obj.GetNamespace()).Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return nil
+	}
+
+	return existing
+}
+
+// applyWatchEvent mirrors the watch event in the fake cluster.
+func (h *HookExecutionConfig) applyWatchEvent(ctx context.Context, event pkg.WatchEventType, obj *unstructured.Unstructured) error {
+	gvr, err := h.gvrFor(obj.GetAPIVersion(), obj.GetKind())
+	if err != nil {
+		return err
+	}
+
+	ri := h.resourceInterface(gvr, obj.GetNamespace())
+
+	switch event {
+	case pkg.WatchEventAdded, pkg.WatchEventModified:
+		_, err = ri.Update(ctx, obj, metav1.UpdateOptions{})
+		if apierrors.IsNotFound(err) {
+			_, err = ri.Create(ctx, obj, metav1.CreateOptions{})
+		}
+		return err
+	case pkg.WatchEventDeleted:
+		err = ri.Delete(ctx, obj.GetName(), metav1.DeleteOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	default:
+		return fmt.Errorf("unknown watch event type %q", event)
+	}
+}
+
+// buildEventBindingContext builds an "Event" binding context for the object,
+// applying the binding's JqFilter to produce the filter result.
+func buildEventBindingContext(ctx context.Context, b *pkg.KubernetesConfig, event pkg.WatchEventType, obj *unstructured.Unstructured) (pkg.BindingContext, error) {
+	rawJSON, err := json.Marshal(obj.UnstructuredContent())
+	if err != nil {
+		return pkg.BindingContext{}, fmt.Errorf("marshal object: %w", err)
+	}
+
+	bc := pkg.BindingContext{
+		Binding:     b.Name,
+		BindingType: pkg.BindingTypeKubernetes,
+		Type:        pkg.KubeEventTypeEvent,
+		Group:       b.Group,
+		WatchEvent:  event,
+		Object:      rawSnapshot(rawJSON),
+	}
+
+	if jqExpr := strings.TrimSpace(b.JqFilter); jqExpr != "" {
+		q, err := sdkjq.NewQuery(jqExpr)
+		if err != nil {
+			return pkg.BindingContext{}, fmt.Errorf("compile jq filter %q: %w", b.JqFilter, err)
+		}
+
+		bc.FilterResult, err = buildSnapshot(ctx, obj, q)
+		if err != nil {
+			return pkg.BindingContext{}, err
+		}
+	}
+
+	return bc, nil
+}
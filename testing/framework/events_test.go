@@ -0,0 +1,153 @@
+package framework_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/deckhouse/module-sdk/pkg"
+	"github.com/deckhouse/module-sdk/testing/framework"
+)
+
+const workerNode = `
+apiVersion: v1
+kind: Node
+metadata:
+  name: kube-worker-3
+  labels:
+    node-role: worker
+`
+
+func TestRunHookOnEvent(t *testing.T) {
+	var got []pkg.BindingContext
+	handler := func(_ context.Context, input *pkg.HookInput) error {
+		got = input.BindingContexts
+		input.Values.Set("count", len(input.Snapshots.Get("nodes")))
+		return nil
+	}
+
+	hec := framework.HookExecutionConfigInit(t, nodeBindingConfig, handler, `{}`, `{}`)
+	hec.KubeStateSet(initialNodes)
+
+	executed := hec.RunHookOnEvent(pkg.WatchEventAdded, workerNode)
+	require.True(t, executed)
+	require.NoError(t, hec.HookError())
+	assert.Equal(t, int64(4), hec.ValuesGet("count").Int())
+
+	require.Len(t, got, 1)
+	assert.True(t, got[0].IsEvent())
+	assert.Equal(t, "nodes", got[0].Binding)
+	assert.Equal(t, pkg.WatchEventAdded, got[0].WatchEvent)
+	assert.Contains(t, got[0].Object.String(), `"name":"kube-worker-3"`)
+	assert.JSONEq(t, `{"name":"kube-worker-3","role":"worker"}`, got[0].FilterResult.String())
+
+	executed = hec.RunHookOnEvent(pkg.WatchEventDeleted, `
+apiVersion: v1
+kind: Node
+metadata:
+  name: kube-worker-3
+`)
+	require.True(t, executed)
+	assert.Equal(t, int64(3), hec.ValuesGet("count").Int())
+	require.Len(t, got, 1)
+	assert.Equal(t, pkg.WatchEventDeleted, got[0].WatchEvent)
+	// the last known state of the object is sent on deletion
+	assert.JSONEq(t, `{"name":"kube-worker-3","role":"worker"}`, got[0].FilterResult.String())
+	assert.Nil(t, hec.KubernetesGlobalResource("Node", "kube-worker-3"))
+}
+
+func TestRunHookOnEventFiltersEventTypes(t *testing.T) {
+	config := &pkg.HookConfig{
+		Kubernetes: []pkg.KubernetesConfig{
+			{
+				Name:                    "nodes",
+				APIVersion:              "v1",
+				Kind:                    "Node",
+				ExecuteHookOnEventTypes: []pkg.WatchEventType{pkg.WatchEventDeleted},
+			},
+		},
+	}
+
+	runs := 0
+	handler := func(_ context.Context, _ *pkg.HookInput) error {
+		runs++
+		return nil
+	}
+
+	hec := framework.HookExecutionConfigInit(t, config, handler, `{}`, `{}`)
+
+	assert.False(t, hec.RunHookOnEvent(pkg.WatchEventAdded, workerNode))
+	assert.False(t, hec.RunHookOnEvent(pkg.WatchEventModified, workerNode))
+	assert.Equal(t, 0, runs)
+	// snapshots are refreshed even if the hook is not executed
+	assert.Len(t, hec.Snapshots().Get("nodes"), 1)
+
+	assert.True(t, hec.RunHookOnEvent(pkg.WatchEventDeleted, workerNode))
+	assert.Equal(t, 1, runs)
+}
+
+func TestRunHookOnEventSkipsNotMatchedBindings(t *testing.T) {
+	config := &pkg.HookConfig{
+		Kubernetes: []pkg.KubernetesConfig{
+			{
+				Name:         "masters",
+				APIVersion:   "v1",
+				Kind:         "Node",
+				NameSelector: &pkg.NameSelector{MatchNames: []string{"kube-master-1"}},
+			},
+		},
+	}
+
+	runs := 0
+	handler := func(_ context.Context, _ *pkg.HookInput) error {
+		runs++
+		return nil
+	}
+
+	hec := framework.HookExecutionConfigInit(t, config, handler, `{}`, `{}`)
+
+	assert.False(t, hec.RunHookOnEvent(pkg.WatchEventAdded, workerNode))
+	assert.Equal(t, 0, runs)
+}
+
+func TestGroupedBindingsProduceGroupContext(t *testing.T) {
+	config := &pkg.HookConfig{
+		Kubernetes: []pkg.KubernetesConfig{
+			{Name: "workers", APIVersion: "v1", Kind: "Node", Group: "nodes", JqFilter: ".metadata.name"},
+			{Name: "all", APIVersion: "v1", Kind: "Node", Group: "nodes", JqFilter: ".metadata.name"},
+			{Name: "pods", APIVersion: "v1", Kind: "Pod"},
+		},
+	}
+
+	var (
+		got   []pkg.BindingContext
+		group string
+	)
+	handler := func(_ context.Context, input *pkg.HookInput) error {
+		got = input.BindingContexts
+		group = input.TriggeredGroup()
+		return nil
+	}
+
+	hec := framework.HookExecutionConfigInit(t, config, handler, `{}`, `{}`)
+	hec.KubeStateSet(initialNodes)
+
+	hec.RunHook()
+	require.NoError(t, hec.HookError())
+	require.Len(t, got, 2)
+	assert.True(t, got[0].IsGroup())
+	assert.Equal(t, "nodes", got[0].Binding)
+	assert.Empty(t, got[0].Objects)
+	assert.True(t, got[1].IsSynchronization())
+	assert.Equal(t, "pods", got[1].Binding)
+	assert.Equal(t, "nodes", group)
+
+	require.True(t, hec.RunHookOnEvent(pkg.WatchEventAdded, workerNode))
+	require.Len(t, got, 1)
+	assert.True(t, got[0].IsGroup())
+	assert.Equal(t, "nodes", group)
+	assert.Len(t, hec.Snapshots().Get("workers"), 4)
+	assert.Len(t, hec.Snapshots().Get("all"), 4)
+}
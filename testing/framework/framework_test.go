@@ -11,6 +11,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/deckhouse/module-sdk/pkg"
+	"github.com/deckhouse/module-sdk/pkg/middleware"
 	objectpatch "github.com/deckhouse/module-sdk/pkg/object-patch"
 	"github.com/deckhouse/module-sdk/testing/framework"
 )
@@ -117,6 +118,38 @@ metadata:
 	assert.Equal(t, int64(1), hec.ValuesGet("count").Int())
 }
 
+// TestSynchronizationBindingContexts verifies that RunHook passes one
+// "Synchronization" binding context per kubernetes binding.
+func TestSynchronizationBindingContexts(t *testing.T) {
+	var got []pkg.BindingContext
+	handler := func(_ context.Context, input *pkg.HookInput) error {
+		got = input.BindingContexts
+		return nil
+	}
+
+	hec := framework.HookExecutionConfigInit(t, nodeBindingConfig, handler, `{}`, `{}`)
+
+	hec.KubeStateSet(initialNodes)
+	hec.RunHook()
+	require.NoError(t, hec.HookError())
+
+	require.Len(t, got, 1)
+	assert.Equal(t, got, hec.BindingContexts())
+
+	bc := got[0]
+	assert.True(t, bc.IsSynchronization())
+	assert.Equal(t, "nodes", bc.Binding)
+	assert.Equal(t, pkg.BindingTypeKubernetes, bc.BindingType)
+	require.Len(t, bc.Objects, 3)
+
+	for _, obj := range bc.Objects {
+		require.NotNil(t, obj.Object)
+		require.NotNil(t, obj.FilterResult)
+		assert.Contains(t, obj.Object.String(), `"kind":"Node"`)
+		assert.Contains(t, obj.FilterResult.String(), `"name":"kube-`)
+	}
+}
+
 // TestPatchCollectorAppliesToFakeCluster verifies that Create/Delete/Patch
 // operations performed by the hook are replayed against the fake cluster.
 func TestPatchCollectorAppliesToFakeCluster(t *testing.T) {
@@ -228,6 +261,23 @@ module:
 	assert.Equal(t, "prod", hec.ConfigValuesGet("module.profile").String())
 }
 
+func TestValuesKeysWithDots(t *testing.T) {
+	cfg := &pkg.HookConfig{Metadata: pkg.HookMetadata{Name: "values-hook"}}
+
+	handler := func(_ context.Context, input *pkg.HookInput) error {
+		input.Values.SetPath([]string{"module", "annotations", "example.com/owner"}, "platform")
+		return nil
+	}
+
+	hec := framework.HookExecutionConfigInit(t, cfg, handler, `{}`, `{}`)
+	hec.ValuesSet(`module.labels.app\.kubernetes\.io/name`, "web")
+	hec.RunHook()
+	require.NoError(t, hec.HookError())
+
+	assert.Equal(t, "platform", hec.ValuesGet(`module.annotations.example\.com/owner`).String())
+	assert.Equal(t, "web", hec.ValuesGet(`module.labels.app\.kubernetes\.io/name`).String())
+}
+
 // TestNamespaceSelectorBindings verifies snapshot generation for a binding
 // scoped to specific namespaces.
 func TestNamespaceSelectorBindings(t *testing.T) {
@@ -312,6 +362,27 @@ func TestHookErrorIsCaptured(t *testing.T) {
 	assert.Contains(t, hec.HookError().Error(), "boom")
 }
 
+// TestMiddlewaresWrapHandler verifies middlewares passed with WithMiddlewares run around the handler.
+func TestMiddlewaresWrapHandler(t *testing.T) {
+	cfg := &pkg.HookConfig{Metadata: pkg.HookMetadata{Name: "panic-hook"}}
+	handler := func(_ context.Context, _ *pkg.HookInput) error {
+		panic("boom")
+	}
+
+	hec := framework.NewHookExecutionConfig(t, cfg, handler,
+		framework.WithMiddlewares(middleware.Recovery(), middleware.SkipUnlessModuleEnabled("user-authn")),
+		framework.WithInitialValues(`{"global":{"enabledModules":[]}}`),
+	)
+
+	hec.RunHook()
+	require.NoError(t, hec.HookError())
+
+	hec.ValuesSet("global.enabledModules", []string{"user-authn"})
+	hec.RunHook()
+	require.Error(t, hec.HookError())
+	assert.Equal(t, `hook "panic-hook" panicked: boom`, hec.HookError().Error())
+}
+
 // TestRegisterCRD allows resources of an unknown kind to be used in state YAML.
 func TestRegisterCRD(t *testing.T) {
 	cfg := &pkg.HookConfig{
@@ -2,12 +2,10 @@ package framework
 
 import (
 	"errors"
-	"fmt"
 	"io/fs"
 	"os"
-	"path/filepath"
 
-	k8syaml "sigs.k8s.io/yaml"
+	"github.com/deckhouse/module-sdk/internal/openapi"
 )
 
 // OpenAPI helpers for the testing framework.
@@ -34,9 +32,9 @@ import (
 //     test's values override the schema-provided ones.
 //
 // The functions are intentionally lightweight: they manipulate the schema
-// as a `map[string]any` and do not validate values. For full validation
-// use the validators in `pkg/values/validation` (or the upstream
-// addon-operator implementation).
+// as a `map[string]any` and do not validate values. Values produced by
+// hooks are validated at runtime when the module is started with
+// app.WithOpenAPIValidation.
 
 // LoadOpenAPISchema reads an OpenAPI v3 schema from a YAML or JSON file
 // and returns the parsed document.
@@ -56,182 +54,7 @@ import (
 //
 // LoadOpenAPISchema does not resolve `$ref`s.
 func LoadOpenAPISchema(path string) (map[string]any, error) {
-	return loadOpenAPISchemaWithStack(path, nil)
-}
-
-// loadOpenAPISchemaWithStack tracks already-visited paths to break
-// pathological cycles in x-extend chains.
-func loadOpenAPISchemaWithStack(path string, stack []string) (map[string]any, error) {
-	abs, err := filepath.Abs(path)
-	if err != nil {
-		return nil, fmt.Errorf("openapi: resolve %q: %w", path, err)
-	}
-	for _, prev := range stack {
-		if prev == abs {
-			return nil, fmt.Errorf("openapi: x-extend cycle detected at %q", abs)
-		}
-	}
-
-	data, err := os.ReadFile(abs)
-	if err != nil {
-		return nil, fmt.Errorf("openapi: read %q: %w", abs, err)
-	}
-
-	var doc map[string]any
-	if err := k8syaml.Unmarshal(data, &doc); err != nil {
-		return nil, fmt.Errorf("openapi: parse %q: %w", abs, err)
-	}
-	if doc == nil {
-		doc = map[string]any{}
-	}
-
-	parentPath, ok := extractExtendSchemaPath(doc)
-	if !ok {
-		return doc, nil
-	}
-
-	parentResolved := parentPath
-	if !filepath.IsAbs(parentResolved) {
-		parentResolved = filepath.Join(filepath.Dir(abs), parentResolved)
-	}
-
-	parent, err := loadOpenAPISchemaWithStack(parentResolved, append(stack, abs))
-	if err != nil {
-		return nil, fmt.Errorf("openapi: load x-extend parent %q: %w", parentPath, err)
-	}
-
-	mergeSchemaWithParent(doc, parent)
-	return doc, nil
-}
-
-// extractExtendSchemaPath reads the optional `x-extend.schema` value
-// from a schema document and returns it.
-func extractExtendSchemaPath(doc map[string]any) (string, bool) {
-	raw, ok := doc["x-extend"]
-	if !ok {
-		return "", false
-	}
-	settings, ok := raw.(map[string]any)
-	if !ok {
-		return "", false
-	}
-	schemaPath, ok := settings["schema"].(string)
-	if !ok || schemaPath == "" {
-		return "", false
-	}
-	return schemaPath, true
-}
-
-// mergeSchemaWithParent folds the parent schema's properties/required/etc.
-// into the current schema, mirroring addon-operator's ExtendTransformer.
-// The current schema wins on conflicts.
-func mergeSchemaWithParent(current, parent map[string]any) {
-	current["properties"] = mergeSchemaMap(current["properties"], parent["properties"])
-	current["patternProperties"] = mergeSchemaMap(current["patternProperties"], parent["patternProperties"])
-	current["definitions"] = mergeSchemaMap(current["definitions"], parent["definitions"])
-	current["required"] = mergeRequired(current["required"], parent["required"])
-
-	if _, has := current["title"]; !has {
-		if title, ok := parent["title"].(string); ok && title != "" {
-			current["title"] = title
-		}
-	}
-	if _, has := current["description"]; !has {
-		if desc, ok := parent["description"].(string); ok && desc != "" {
-			current["description"] = desc
-		}
-	}
-
-	for k, v := range parent {
-		if k == "properties" || k == "patternProperties" || k == "definitions" ||
-			k == "required" || k == "title" || k == "description" || k == "x-extend" {
-			continue
-		}
-		if _, has := current[k]; has {
-			continue
-		}
-		// Only carry over OpenAPI extensions and a small set of known
-		// schema-level keys. We deliberately don't override `type`,
-		// `properties`, etc. that the current schema already declared.
-		if isExtension(k) {
-			current[k] = v
-		}
-	}
-}
-
-// mergeSchemaMap merges two map-shaped schema fields (e.g. `properties`).
-// Keys present in `current` win.
-func mergeSchemaMap(current, parent any) any {
-	out := map[string]any{}
-	if pm, ok := parent.(map[string]any); ok {
-		for k, v := range pm {
-			out[k] = v
-		}
-	}
-	if cm, ok := current.(map[string]any); ok {
-		for k, v := range cm {
-			out[k] = v
-		}
-	}
-	if len(out) == 0 {
-		// Preserve "field absent" instead of writing back an empty map.
-		if current == nil && parent == nil {
-			return nil
-		}
-	}
-	return out
-}
-
-// mergeRequired deduplicates two `required:` lists (parent first).
-func mergeRequired(current, parent any) any {
-	pSlice := toStringSlice(parent)
-	cSlice := toStringSlice(current)
-
-	if len(pSlice) == 0 && len(cSlice) == 0 {
-		if current == nil && parent == nil {
-			return nil
-		}
-		return []any{}
-	}
-
-	seen := make(map[string]struct{}, len(pSlice)+len(cSlice))
-	out := make([]any, 0, len(pSlice)+len(cSlice))
-	for _, name := range pSlice {
-		if _, ok := seen[name]; ok {
-			continue
-		}
-		seen[name] = struct{}{}
-		out = append(out, name)
-	}
-	for _, name := range cSlice {
-		if _, ok := seen[name]; ok {
-			continue
-		}
-		seen[name] = struct{}{}
-		out = append(out, name)
-	}
-	return out
-}
-
-func toStringSlice(v any) []string {
-	switch s := v.(type) {
-	case []string:
-		return s
-	case []any:
-		out := make([]string, 0, len(s))
-		for _, item := range s {
-			if str, ok := item.(string); ok {
-				out = append(out, str)
-			}
-		}
-		return out
-	default:
-		return nil
-	}
-}
-
-func isExtension(key string) bool {
-	return len(key) > 2 && key[0] == 'x' && key[1] == '-'
+	return openapi.LoadSchema(path)
 }
 
 // SchemaDefaults walks an OpenAPI schema (as returned by LoadOpenAPISchema)
@@ -4,6 +4,8 @@ import (
 	"path/filepath"
 
 	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/deckhouse/module-sdk/pkg"
 )
 
 // Option configures a HookExecutionConfig at construction time.
@@ -23,6 +25,7 @@ type execOptions struct {
 	configValuesSchemaPath string
 	extraSchemeBuilders    []runtime.SchemeBuilder
 	crds                   []customCRD
+	middlewares            []pkg.Middleware
 }
 
 type customCRD struct {
@@ -46,6 +49,14 @@ func WithInitialConfigValues(v string) Option {
 	})
 }
 
+// WithMiddlewares wraps the hook handler with middlewares the same way
+// app.WithMiddlewares and registry.RegisterFunc do, the first one is the outermost.
+func WithMiddlewares(middlewares ...pkg.Middleware) Option {
+	return optionFunc(func(o *execOptions) {
+		o.middlewares = append(o.middlewares, middlewares...)
+	})
+}
+
 // WithSchemeBuilder registers an additional runtime.SchemeBuilder so that
 // typed CRDs from your module can be used in YAML state and assertions.
 func WithSchemeBuilder(builder runtime.SchemeBuilder) Option {
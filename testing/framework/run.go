@@ -28,13 +28,26 @@ func (h *HookExecutionConfig) RunHook() {
 // RunHookCtx is like RunHook but accepts an explicit context.
 func (h *HookExecutionConfig) RunHookCtx(ctx context.Context) {
 	h.t.Helper()
-	h.hookError = nil
 
-	snaps, err := h.generateSnapshots(ctx)
+	snaps, bindingContexts, err := h.generateSnapshots(ctx)
 	if err != nil {
 		h.t.Fatalf("framework: generate snapshots: %v", err)
 	}
+
+	h.validatingReview = nil
+	h.mutatingReview = nil
+
+	h.runHandler(ctx, snaps, bindingContexts)
+}
+
+// runHandler invokes the hook handler with the given snapshots and binding
+// contexts, then merges values patches and replays cluster patches.
+// Admission reviews prepared by the caller are passed to the hook as well.
+func (h *HookExecutionConfig) runHandler(ctx context.Context, snaps snapshotsMap, bindingContexts []pkg.BindingContext) {
+	h.t.Helper()
+	h.hookError = nil
 	h.snapshots = snaps
+	h.bindingContexts = bindingContexts
 
 	patchableValues, err := patchableValuesFor(h.values)
 	if err != nil {
@@ -54,6 +67,7 @@ func (h *HookExecutionConfig) RunHookCtx(ctx context.Context) {
 
 	input := &pkg.HookInput{
 		Snapshots:        h.snapshots,
+		BindingContexts:  h.bindingContexts,
 		Values:           patchableValues,
 		ConfigValues:     patchableConfigValues,
 		PatchCollector:   h.patchCollector,
@@ -62,7 +76,29 @@ func (h *HookExecutionConfig) RunHookCtx(ctx context.Context) {
 		Logger:           h.logger,
 	}
 
-	h.hookError = h.hookHandler(ctx, input)
+	// keep nil interfaces for runs without admission requests
+	if h.validatingReview != nil {
+		input.Review = h.validatingReview
+	}
+	if h.mutatingReview != nil {
+		input.Mutation = h.mutatingReview
+	}
+
+	run := &pkg.HookRun{
+		Metadata:         h.hookMetadata(),
+		BindingContexts:  input.BindingContexts,
+		Values:           input.Values,
+		MetricsCollector: input.MetricsCollector,
+		Logger:           input.Logger,
+		Input:            input,
+	}
+
+	h.hookError = pkg.RunMiddlewares(ctx, run, h.middlewares, func(ctx context.Context) error {
+		return h.hookHandler(ctx, input)
+	})
+
+	h.sensitiveValuesPaths = append(h.sensitiveValuesPaths, patchableValues.SensitivePaths()...)
+	h.sensitiveConfigValuesPaths = append(h.sensitiveConfigValuesPaths, patchableConfigValues.SensitivePaths()...)
 
 	// Always merge values patches so callers can assert both happy and error
 	// paths.
@@ -79,3 +115,11 @@ func (h *HookExecutionConfig) RunHookCtx(ctx context.Context) {
 		}
 	}
 }
+
+func (h *HookExecutionConfig) hookMetadata() pkg.HookMetadata {
+	if h.hookConfig == nil {
+		return pkg.HookMetadata{}
+	}
+
+	return h.hookConfig.Metadata
+}
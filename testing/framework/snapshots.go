@@ -22,13 +22,18 @@ import (
 //   - lists matching objects (filtered by NameSelector / NamespaceSelector /
 //     LabelSelector),
 //   - applies the JqFilter (if any) to each matched object,
-//   - stores the JSON result as a snapshot under the binding's Name.
-func (h *HookExecutionConfig) generateSnapshots(ctx context.Context) (snapshotsMap, error) {
+//   - stores the JSON result as a snapshot under the binding's Name,
+//   - emits a "Synchronization" binding context with the matched objects,
+//     like shell-operator does on the first run after start. Bindings of the
+//     same group share a single "Group" binding context.
+func (h *HookExecutionConfig) generateSnapshots(ctx context.Context) (snapshotsMap, []pkg.BindingContext, error) {
 	out := snapshotsMap{}
 	if h.hookConfig == nil {
-		return out, nil
+		return out, nil, nil
 	}
 
+	bindingContexts := make([]pkg.BindingContext, 0, len(h.hookConfig.Kubernetes))
+
 	for _, b := range h.hookConfig.Kubernetes {
 		// Allow empty APIVersion (defaults to "v1").
 		apiVersion := b.APIVersion
@@ -38,14 +43,14 @@ func (h *HookExecutionConfig) generateSnapshots(ctx context.Context) (snapshotsM
 
 		gvr, err := h.gvrFor(apiVersion, b.Kind)
 		if err != nil {
-			return nil, fmt.Errorf("binding %q: %w", b.Name, err)
+			return nil, nil, fmt.Errorf("binding %q: %w", b.Name, err)
 		}
 
 		listOpts := metav1.ListOptions{}
 		if b.LabelSelector != nil {
 			sel, err := metav1.LabelSelectorAsSelector(b.LabelSelector)
 			if err != nil {
-				return nil, fmt.Errorf("binding %q: parse label selector: %w", b.Name, err)
+				return nil, nil, fmt.Errorf("binding %q: parse label selector: %w", b.Name, err)
 			}
 			listOpts.LabelSelector = sel.String()
 		}
@@ -53,14 +58,14 @@ func (h *HookExecutionConfig) generateSnapshots(ctx context.Context) (snapshotsM
 		// Determine which namespaces to inspect.
 		namespaces, err := h.namespacesForBinding(ctx, &b)
 		if err != nil {
-			return nil, fmt.Errorf("binding %q: %w", b.Name, err)
+			return nil, nil, fmt.Errorf("binding %q: %w", b.Name, err)
 		}
 
 		var matched []unstructured.Unstructured
 		for _, ns := range namespaces {
 			list, err := h.resourceInterface(gvr, ns).List(ctx, listOpts)
 			if err != nil {
-				return nil, fmt.Errorf("binding %q: list %s in %q: %w", b.Name, gvr.Resource, ns, err)
+				return nil, nil, fmt.Errorf("binding %q: list %s in %q: %w", b.Name, gvr.Resource, ns, err)
 			}
 			for _, item := range list.Items {
 				if !matchesNameSelector(item.GetName(), b.NameSelector) {
@@ -77,21 +82,41 @@ func (h *HookExecutionConfig) generateSnapshots(ctx context.Context) (snapshotsM
 		if jqExpr := strings.TrimSpace(b.JqFilter); jqExpr != "" {
 			compiledJQ, err = sdkjq.NewQuery(jqExpr)
 			if err != nil {
-				return nil, fmt.Errorf("binding %q: compile jq filter %q: %w", b.Name, b.JqFilter, err)
+				return nil, nil, fmt.Errorf("binding %q: compile jq filter %q: %w", b.Name, b.JqFilter, err)
 			}
 		}
 
 		snaps := make([]pkg.Snapshot, 0, len(matched))
+		objects := make([]pkg.ObjectAndFilterResult, 0, len(matched))
 		for _, obj := range matched {
 			snap, err := buildSnapshot(ctx, &obj, compiledJQ)
 			if err != nil {
-				return nil, fmt.Errorf("binding %q: build snapshot for %s/%s: %w", b.Name, obj.GetNamespace(), obj.GetName(), err)
+				return nil, nil, fmt.Errorf("binding %q: build snapshot for %s/%s: %w", b.Name, obj.GetNamespace(), obj.GetName(), err)
 			}
 			snaps = append(snaps, snap)
+
+			// Without a jq filter shell-operator sends the object only.
+			item := pkg.ObjectAndFilterResult{Object: snap}
+			if compiledJQ != nil {
+				objSnap, err := buildSnapshot(ctx, &obj, nil)
+				if err != nil {
+					return nil, nil, fmt.Errorf("binding %q: build object for %s/%s: %w", b.Name, obj.GetNamespace(), obj.GetName(), err)
+				}
+				item = pkg.ObjectAndFilterResult{Object: objSnap, FilterResult: snap}
+			}
+			objects = append(objects, item)
 		}
 		out[b.Name] = snaps
+
+		bindingContexts = append(bindingContexts, pkg.BindingContext{
+			Binding:     b.Name,
+			BindingType: pkg.BindingTypeKubernetes,
+			Type:        pkg.KubeEventTypeSynchronization,
+			Group:       b.Group,
+			Objects:     objects,
+		})
 	}
-	return out, nil
+	return out, groupBindingContexts(bindingContexts), nil
 }
 
 // namespacesForBinding returns the list of namespaces to scan for a given
@@ -188,6 +213,35 @@ func buildSnapshot(ctx context.Context, obj *unstructured.Unstructured, q *sdkjq
 	return rawSnapshot([]byte(res.String())), nil
 }
 
+// groupBindingContexts replaces binding contexts of grouped bindings with a
+// single "Group" binding context per group, keeping the order of the first
+// occurrence. shell-operator sends no objects for groups: hooks are expected
+// to read the snapshots instead.
+func groupBindingContexts(bcs []pkg.BindingContext) []pkg.BindingContext {
+	out := make([]pkg.BindingContext, 0, len(bcs))
+	seen := make(map[string]struct{})
+	for _, bc := range bcs {
+		if bc.Group == "" {
+			out = append(out, bc)
+			continue
+		}
+
+		if _, ok := seen[bc.Group]; ok {
+			continue
+		}
+		seen[bc.Group] = struct{}{}
+
+		out = append(out, pkg.BindingContext{
+			Binding:     bc.Group,
+			BindingType: bc.BindingType,
+			Type:        pkg.KubeEventTypeGroup,
+			Group:       bc.Group,
+		})
+	}
+
+	return out
+}
+
 // silenceUnusedLabels keeps the labels import alive (used by some helpers
 // during future expansion).
 var _ = labels.Everything
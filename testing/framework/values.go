@@ -158,12 +158,13 @@ func splitJSONPath(p string) ([]string, error) {
 	return parts, nil
 }
 
-// splitPath splits a dotted path into segments. Empty input returns nil.
+// splitPath splits a dotted path into segments, see patchablevalues.SplitPath.
+// Empty input returns nil.
 func splitPath(p string) []string {
 	if p == "" {
 		return nil
 	}
-	return strings.Split(p, ".")
+	return patchablevalues.SplitPath(p)
 }
 
 func setNested(m map[string]any, parts []string, value any) {
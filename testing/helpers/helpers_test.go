@@ -7,6 +7,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/deckhouse/module-sdk/pkg"
 	objectpatch "github.com/deckhouse/module-sdk/pkg/object-patch"
@@ -165,6 +168,84 @@ func TestInputBuilder_FluentChain(t *testing.T) {
 	require.Len(t, b.RecordingPatchCollector().Recorded(), 1)
 }
 
+func TestInputBuilder_WithBindingContext(t *testing.T) {
+	in := helpers.NewInputBuilder(t).
+		WithBindingContext(pkg.BindingContext{
+			Binding:     "pods",
+			BindingType: pkg.BindingTypeKubernetes,
+			Type:        pkg.KubeEventTypeEvent,
+			WatchEvent:  pkg.WatchEventDeleted,
+			Object:      helpers.SnapshotJSON(`{"name":"pod-1"}`),
+		}).
+		Build()
+
+	require.Len(t, in.BindingContexts, 1)
+	assert.True(t, in.BindingContexts[0].IsEvent())
+	assert.Equal(t, pkg.WatchEventDeleted, in.BindingContexts[0].WatchEvent)
+	assert.JSONEq(t, `{"name":"pod-1"}`, in.BindingContexts[0].Snapshot().String())
+}
+
+func TestInputBuilder_WithValidatingRequest(t *testing.T) {
+	in := helpers.NewInputBuilder(t).
+		WithValidatingRequest(&admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: []byte(`{"kind":"Pod"}`)},
+		}).
+		Build()
+
+	require.NotNil(t, in.Review)
+	assert.Equal(t, admissionv1.Create, in.Review.Request().Operation)
+	assert.True(t, in.Review.Response().Allowed)
+
+	in.Review.Deny("pods are not allowed", "check the policy")
+	assert.Equal(t, pkg.ValidatingResponse{
+		Allowed:  false,
+		Message:  "pods are not allowed",
+		Warnings: []string{"check the policy"},
+	}, in.Review.Response())
+}
+
+func TestInputBuilder_WithMutatingRequest(t *testing.T) {
+	in := helpers.NewInputBuilder(t).
+		WithMutatingRequest(&admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: []byte(`{"kind":"Pod","metadata":{"name":"app"}}`)},
+		}).
+		Build()
+
+	require.NotNil(t, in.Mutation)
+	assert.Nil(t, in.Review)
+	assert.True(t, in.Mutation.Response().Allowed)
+	assert.Empty(t, in.Mutation.Response().Patch)
+
+	err := in.Mutation.Mutate(map[string]any{
+		"kind":     "Pod",
+		"metadata": map[string]any{"name": "app", "labels": map[string]any{"tier": "frontend"}},
+	})
+	require.NoError(t, err)
+	assert.True(t, in.Mutation.Response().Allowed)
+	assert.JSONEq(t, `[{"op":"add","path":"/metadata/labels","value":{"tier":"frontend"}}]`, string(in.Mutation.Response().Patch))
+}
+
+func TestInputBuilder_WithConversionRequest(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("example.io/v1alpha1")
+	obj.SetName("w-1")
+
+	in := helpers.NewInputBuilder(t).
+		WithConversionRequest("example.io/v1alpha1", "example.io/v1", obj).
+		Build()
+
+	require.NotNil(t, in.Conversion)
+	assert.Equal(t, "example.io/v1", in.Conversion.ToVersion())
+	require.Len(t, in.Conversion.Objects(), 1)
+	assert.NotEmpty(t, in.Conversion.Response().FailedMessage)
+
+	in.Conversion.Respond(obj)
+	assert.Empty(t, in.Conversion.Response().FailedMessage)
+	assert.Len(t, in.Conversion.Response().ConvertedObjects, 1)
+}
+
 func TestJQRunOnString_AndObject(t *testing.T) {
 	const filter = `{name: .metadata.name, count: (.spec.replicas // 0)}`
 	const input = `{"metadata":{"name":"deploy"},"spec":{"replicas":3}}`
@@ -5,7 +5,11 @@ import (
 	"testing"
 
 	"github.com/deckhouse/deckhouse/pkg/log"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
+	"github.com/deckhouse/module-sdk/internal/admission"
+	"github.com/deckhouse/module-sdk/internal/conversion"
 	"github.com/deckhouse/module-sdk/internal/metric"
 	"github.com/deckhouse/module-sdk/pkg"
 )
@@ -24,12 +28,16 @@ import (
 type InputBuilder struct {
 	tb testing.TB
 
-	snapshots StaticSnapshots
-	values    pkg.PatchableValuesCollector
-	config    pkg.PatchableValuesCollector
-	patch     pkg.PatchCollector
-	metrics   pkg.MetricsCollector
-	dc        pkg.DependencyContainer
+	snapshots       StaticSnapshots
+	bindingContexts []pkg.BindingContext
+	values          pkg.PatchableValuesCollector
+	config          pkg.PatchableValuesCollector
+	patch           pkg.PatchCollector
+	metrics         pkg.MetricsCollector
+	dc              pkg.DependencyContainer
+	review          pkg.ValidatingReview
+	mutation        pkg.MutatingReview
+	conversion      pkg.ConversionReview
 
 	logger    pkg.Logger
 	logBuffer *bytes.Buffer
@@ -63,6 +71,37 @@ func (b *InputBuilder) WithSnapshots(s StaticSnapshots) *InputBuilder {
 	return b
 }
 
+// WithBindingContext appends one or more binding contexts to the input.
+// May be called multiple times; contexts keep the order of the calls.
+func (b *InputBuilder) WithBindingContext(bcs ...pkg.BindingContext) *InputBuilder {
+	b.bindingContexts = append(b.bindingContexts, bcs...)
+	return b
+}
+
+// WithValidatingRequest makes the input look like a run triggered by a
+// KubernetesValidating binding. The hook response is available via
+// input.Review.Response() after the hook returns.
+func (b *InputBuilder) WithValidatingRequest(req *admissionv1.AdmissionRequest) *InputBuilder {
+	b.review = admission.NewValidatingReview(req)
+	return b
+}
+
+// WithMutatingRequest makes the input look like a run triggered by a
+// KubernetesMutating binding. The hook response, including the JSON patch,
+// is available via input.Mutation.Response() after the hook returns.
+func (b *InputBuilder) WithMutatingRequest(req *admissionv1.AdmissionRequest) *InputBuilder {
+	b.mutation = admission.NewMutatingReview(req)
+	return b
+}
+
+// WithConversionRequest makes the input look like a run triggered by a
+// KubernetesConversion binding. Converted objects are available via
+// input.Conversion.Response() after the hook returns.
+func (b *InputBuilder) WithConversionRequest(fromVersion, toVersion string, objects ...*unstructured.Unstructured) *InputBuilder {
+	b.conversion = conversion.NewReview(fromVersion, toVersion, objects)
+	return b
+}
+
 // WithValues replaces the values collector with the given one. By default,
 // the builder constructs an empty PatchableValuesCollector lazily on Build.
 func (b *InputBuilder) WithValues(v pkg.PatchableValuesCollector) *InputBuilder {
@@ -176,10 +215,14 @@ func (b *InputBuilder) Build() *pkg.HookInput {
 
 	return &pkg.HookInput{
 		Snapshots:        b.snapshots,
+		BindingContexts:  b.bindingContexts,
 		Values:           b.values,
 		ConfigValues:     b.config,
 		PatchCollector:   b.patch,
 		MetricsCollector: b.metrics,
+		Review:           b.review,
+		Mutation:         b.mutation,
+		Conversion:       b.conversion,
 		DC:               b.dc,
 		Logger:           b.logger,
 	}